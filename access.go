@@ -0,0 +1,52 @@
+package figgy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+)
+
+// AccessDeniedError is returned when IAM denies a request for parameters, naming the
+// parameters and the actions the role needs to be allowed to load them
+type AccessDeniedError struct {
+	// Keys of the parameters requested
+	Keys []string
+	// Actions the role needs, such as ssm:GetParameters and kms:Decrypt
+	Actions []string
+	// Err is the error of the request
+	Err error
+}
+
+func (e *AccessDeniedError) Error() string {
+	return fmt.Sprintf("access denied to parameters %s, allow %s: %v", strings.Join(e.Keys, ", "), strings.Join(e.Actions, ", "), e.Err)
+}
+
+// Unwrap returns the error of the request
+func (e *AccessDeniedError) Unwrap() error {
+	return e.Err
+}
+
+// isAccessDenied reports whether err is an AccessDeniedException
+func isAccessDenied(err error) bool {
+	var aerr awserr.Error
+	return errors.As(err, &aerr) && aerr.Code() == "AccessDeniedException"
+}
+
+// requestError returns the error of a failed request for the parameters named by keys
+// made with action, as an AccessDeniedError when IAM denied it and a BatchError when
+// it failed otherwise
+func requestError(err error, keys []string, action string, decrypt bool) error {
+	if !isAccessDenied(err) {
+		return &BatchError{Keys: keys, Err: err}
+	}
+	actions := []string{action}
+	if isDecryptDenied(err) {
+		actions = nil
+	}
+	if decrypt {
+		actions = append(actions, "kms:Decrypt")
+	}
+	return &AccessDeniedError{Keys: keys, Actions: actions, Err: err}
+}
@@ -0,0 +1,37 @@
+package figgy
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessDeniedError(t *testing.T) {
+	denied := awserr.New("AccessDeniedException", "User: arn:aws:iam::123456789012:user/app is not authorized to perform: ssm:GetParameters", nil)
+	c := &failingSSMClient{MockSSMClient: NewMockSSMClient(), err: denied}
+	s := struct {
+		S string `ssm:"string"`
+		I int    `ssm:"int"`
+	}{}
+	err := Load(c, &s)
+	assert.Equal(t, &AccessDeniedError{Keys: []string{"string", "int"}, Actions: []string{"ssm:GetParameters"}, Err: denied}, err)
+
+	d := struct {
+		B bool `ssm:"bool,decrypt"`
+	}{}
+	err = Load(c, &d)
+	if e, ok := err.(*AccessDeniedError); assert.True(t, ok, "%v", err) {
+		assert.Equal(t, []string{"ssm:GetParameters", "kms:Decrypt"}, e.Actions)
+	}
+}
+
+func TestAccessDeniedDecrypt(t *testing.T) {
+	var cfg decryptConfig
+	err := Load(newDeniedClient(), &cfg)
+	if e, ok := err.(*AccessDeniedError); assert.True(t, ok, "%v", err) {
+		assert.Equal(t, []string{"/app/password", "/app/token"}, e.Keys)
+		assert.Equal(t, []string{"kms:Decrypt"}, e.Actions)
+		assert.Contains(t, e.Error(), "allow kms:Decrypt")
+	}
+}
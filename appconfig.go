@@ -0,0 +1,247 @@
+package figgy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// AppConfigAPI is the part of the AWS AppConfig Data API used by AppConfig.  The
+// appconfigdata package is newer than the AWS SDK figgy is built with, so its client
+// is adapted to this interface.
+type AppConfigAPI interface {
+	// StartConfigurationSession starts a session for the configuration profile of an
+	// application and environment, returning the initial configuration token
+	StartConfigurationSession(ctx context.Context, application, environment, profile string) (token string, err error)
+	// GetLatestConfiguration returns the configuration if it changed since the token
+	// was issued, and the token for the next call
+	GetLatestConfiguration(ctx context.Context, token string) (*AppConfigResult, error)
+}
+
+// AppConfigResult is the result of GetLatestConfiguration
+type AppConfigResult struct {
+	// Configuration is empty when the configuration hasn't changed
+	Configuration []byte
+	ContentType   string
+	NextToken     string
+	// PollInterval is the least time to wait before the next call
+	PollInterval time.Duration
+}
+
+// AppConfig is a KV of a JSON or YAML configuration hosted by AWS AppConfig.  Keys are
+// paths into the document, so the tag `ssm:"/database/host"` loads the host member of
+// the database object.  Arrays of scalars are comma separated lists and objects are
+// JSON, for fields with the json option.  Watch follows AppConfig's polling protocol,
+// waiting the poll interval between requests for the latest configuration.
+//
+//	kv := &figgy.AppConfig{API: client, Application: "myapp", Environment: "prod", Profile: "main"}
+//	err := figgy.Load(figgy.NewKVClient(kv), &cfg)
+type AppConfig struct {
+	API         AppConfigAPI
+	Application string
+	Environment string
+	Profile     string
+
+	mu      sync.Mutex
+	token   string
+	poll    time.Duration
+	polled  time.Time
+	values  map[string]string
+	changed map[string]int64
+	version int64
+}
+
+// Get returns the value at the path key of the configuration, fetching it on first use
+func (a *AppConfig) Get(ctx context.Context, key string) (string, int64, bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.token == "" {
+		if _, err := a.fetch(ctx); err != nil {
+			return "", 0, false, err
+		}
+	}
+	v, ok := a.values[key]
+	return v, a.changed[key], ok, nil
+}
+
+// Watch polls for the latest configuration until a key beginning with prefix changes
+func (a *AppConfig) Watch(ctx context.Context, prefix string, version int64) ([]string, int64, error) {
+	for {
+		a.mu.Lock()
+		if a.token != "" && version == 0 {
+			a.mu.Unlock()
+			return nil, a.version, nil
+		}
+		wait := time.Until(a.polled.Add(a.poll))
+		a.mu.Unlock()
+		if wait > 0 {
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return nil, 0, ctx.Err()
+			}
+		}
+		a.mu.Lock()
+		changed, err := a.fetch(ctx)
+		current := a.version
+		a.mu.Unlock()
+		if err != nil {
+			return nil, 0, err
+		}
+		var keys []string
+		for _, k := range changed {
+			if strings.HasPrefix(k, prefix) {
+				keys = append(keys, k)
+			}
+		}
+		if version == 0 || len(keys) > 0 {
+			return keys, current, nil
+		}
+	}
+}
+
+// fetch gets the latest configuration, starting a session if needed, and returns the
+// keys that changed
+func (a *AppConfig) fetch(ctx context.Context) ([]string, error) {
+	if a.token == "" {
+		token, err := a.API.StartConfigurationSession(ctx, a.Application, a.Environment, a.Profile)
+		if err != nil {
+			return nil, err
+		}
+		a.token = token
+	}
+	res, err := a.API.GetLatestConfiguration(ctx, a.token)
+	if err != nil {
+		return nil, err
+	}
+	a.token = res.NextToken
+	a.poll = res.PollInterval
+	a.polled = time.Now()
+	if len(res.Configuration) == 0 {
+		return nil, nil
+	}
+	values, err := flattenConfiguration(res.Configuration, res.ContentType)
+	if err != nil {
+		return nil, err
+	}
+	var changed []string
+	for k, v := range values {
+		if old, ok := a.values[k]; !ok || old != v {
+			changed = append(changed, k)
+		}
+	}
+	for k := range a.values {
+		if _, ok := values[k]; !ok {
+			changed = append(changed, k)
+		}
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+	sort.Strings(changed)
+	a.version++
+	if a.changed == nil {
+		a.changed = make(map[string]int64)
+	}
+	for _, k := range changed {
+		a.changed[k] = a.version
+	}
+	a.values = values
+	return changed, nil
+}
+
+// flattenConfiguration decodes a JSON or YAML document into the values at each path
+func flattenConfiguration(b []byte, contentType string) (map[string]string, error) {
+	var doc interface{}
+	var err error
+	if strings.Contains(contentType, "yaml") {
+		err = yaml.Unmarshal(b, &doc)
+	} else {
+		// numbers are kept as written rather than formatted from a float64
+		d := json.NewDecoder(bytes.NewReader(b))
+		d.UseNumber()
+		err = d.Decode(&doc)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode AppConfig configuration of type %s: %v", contentType, err)
+	}
+	values := make(map[string]string)
+	if err := flatten(values, "", jsonCompatible(doc)); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// flatten adds the value v at path, and the values it contains, to values
+func flatten(values map[string]string, path string, v interface{}) error {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, x := range v {
+			if err := flatten(values, path+"/"+k, x); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		if l, ok := joinScalars(v); ok {
+			values[path] = l
+			return nil
+		}
+	case nil:
+		values[path] = ""
+		return nil
+	default:
+		values[path] = fmt.Sprint(v)
+		return nil
+	}
+	if path == "" {
+		return nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	values[path] = string(b)
+	return nil
+}
+
+// joinScalars returns a comma separated list of the elements of l, unless one of them
+// is an object or array
+func joinScalars(l []interface{}) (string, bool) {
+	s := make([]string, len(l))
+	for i, x := range l {
+		switch x.(type) {
+		case map[string]interface{}, []interface{}:
+			return "", false
+		}
+		s[i] = fmt.Sprint(x)
+	}
+	return strings.Join(s, ","), true
+}
+
+// jsonCompatible converts the maps decoded from YAML to have string keys
+func jsonCompatible(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, x := range v {
+			m[fmt.Sprint(k)] = jsonCompatible(x)
+		}
+		return m
+	case map[string]interface{}:
+		for k, x := range v {
+			v[k] = jsonCompatible(x)
+		}
+	case []interface{}:
+		for i, x := range v {
+			v[i] = jsonCompatible(x)
+		}
+	}
+	return v
+}
@@ -0,0 +1,106 @@
+package figgy
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockAppConfig returns each configuration set on it once, like AppConfig does
+type mockAppConfig struct {
+	mu       sync.Mutex
+	config   string
+	sessions int
+	tokens   []string
+}
+
+func (m *mockAppConfig) StartConfigurationSession(ctx context.Context, application, environment, profile string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions++
+	return application + "/" + environment + "/" + profile, nil
+}
+
+func (m *mockAppConfig) GetLatestConfiguration(ctx context.Context, token string) (*AppConfigResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tokens = append(m.tokens, token)
+	res := &AppConfigResult{
+		Configuration: []byte(m.config),
+		ContentType:   "application/json",
+		NextToken:     "next",
+		PollInterval:  time.Millisecond,
+	}
+	m.config = ""
+	return res, nil
+}
+
+func (m *mockAppConfig) set(config string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.config = config
+}
+
+type AppConfigConfig struct {
+	Host     string        `ssm:"/database/host"`
+	Port     int           `ssm:"/database/port"`
+	Replicas []string      `ssm:"/database/replicas"`
+	Timeout  time.Duration `ssm:"/timeout"`
+	Database struct {
+		Host string `json:"host"`
+	} `ssm:"/database,json"`
+}
+
+const appConfigDocument = `{
+	"database": {"host": "db.local", "port": 5432, "replicas": ["r1", "r2"]},
+	"timeout": "5s"
+}`
+
+func TestAppConfig(t *testing.T) {
+	m := &mockAppConfig{config: appConfigDocument}
+	kv := &AppConfig{API: m, Application: "myapp", Environment: "prod", Profile: "main"}
+	var cfg AppConfigConfig
+	assert.NoError(t, Load(NewKVClient(kv), &cfg))
+	assert.Equal(t, "db.local", cfg.Host)
+	assert.Equal(t, 5432, cfg.Port)
+	assert.Equal(t, []string{"r1", "r2"}, cfg.Replicas)
+	assert.Equal(t, 5*time.Second, cfg.Timeout)
+	assert.Equal(t, "db.local", cfg.Database.Host)
+	assert.Equal(t, []string{"myapp/prod/main"}, m.tokens)
+
+	var missing struct {
+		Name string `ssm:"/name"`
+	}
+	assert.EqualError(t, Load(NewKVClient(kv), &missing), "invalid parameters: /name")
+	assert.Equal(t, 1, m.sessions)
+}
+
+func TestAppConfigYAML(t *testing.T) {
+	values, err := flattenConfiguration([]byte(`{"a": {"b": 1, "c": [{"d": true}]}}`), "application/x-yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", values["/a/b"])
+	assert.Equal(t, `[{"d":true}]`, values["/a/c"])
+	assert.Equal(t, `{"b":1,"c":[{"d":true}]}`, values["/a"])
+
+	_, err = flattenConfiguration([]byte(`{`), "application/json")
+	assert.Error(t, err)
+}
+
+func TestWatchAppConfig(t *testing.T) {
+	m := &mockAppConfig{config: appConfigDocument}
+	kv := &AppConfig{API: m, Application: "myapp", Environment: "prod", Profile: "main"}
+	var cfg AppConfigConfig
+	w, err := Watch(NewKVClient(kv), &cfg, nil, &KVNotifier{KV: kv, Prefix: "/database/"})
+	assert.NoError(t, err)
+	defer w.Stop()
+
+	m.set(`{"database": {"host": "db.local", "port": 5433, "replicas": ["r1", "r2"]}, "timeout": "5s"}`)
+	assert.True(t, waitChange(t, w))
+	w.RLock()
+	assert.Equal(t, 5433, cfg.Port)
+	assert.Equal(t, "db.local", cfg.Host)
+	w.RUnlock()
+}
@@ -0,0 +1,55 @@
+package figgy
+
+import (
+	"context"
+	"time"
+)
+
+// AzureKeyVaultAPI is the part of the Azure Key Vault secrets client used by
+// AzureKeyVault.  The Azure SDK isn't a dependency of figgy, so its client is adapted
+// to this interface.
+type AzureKeyVaultAPI interface {
+	// GetSecret returns the latest version of the secret called name, or nil when
+	// there's no such secret
+	GetSecret(ctx context.Context, name string) (*AzureSecret, error)
+}
+
+// AzureSecret is a version of a Key Vault secret
+type AzureSecret struct {
+	Value string
+	// Version is the identifier of the version, which changes with the value
+	Version string
+}
+
+// AzureKeyVault is a KV of the secrets in an Azure Key Vault.  Secret names can't
+// contain slashes, so a key's slashes are replaced by dashes and the tag
+// `ssm:"/myapp/db/password"` loads the secret myapp-db-password.  Key Vault has no
+// watch, so Watch checks the secrets that have been loaded every Interval, a minute
+// by default.
+//
+//	kv := &figgy.AzureKeyVault{API: client}
+//	err := figgy.Load(figgy.NewKVClient(kv), &cfg)
+type AzureKeyVault struct {
+	API      AzureKeyVaultAPI
+	Interval time.Duration
+
+	poll pollingKV
+}
+
+// Get returns the latest version of the secret for key
+func (a *AzureKeyVault) Get(ctx context.Context, key string) (string, int64, bool, error) {
+	return a.poll.get(ctx, key, a.fetch)
+}
+
+// Watch polls the secrets for keys beginning with prefix until one changes
+func (a *AzureKeyVault) Watch(ctx context.Context, prefix string, version int64) ([]string, int64, error) {
+	return a.poll.watch(ctx, prefix, version, a.Interval, a.fetch)
+}
+
+func (a *AzureKeyVault) fetch(ctx context.Context, key string) (string, string, bool, error) {
+	s, err := a.API.GetSecret(ctx, secretName(key))
+	if err != nil || s == nil {
+		return "", "", false, err
+	}
+	return s.Value, s.Version, true, nil
+}
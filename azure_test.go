@@ -0,0 +1,65 @@
+package figgy
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockKeyVault struct {
+	mu      sync.Mutex
+	secrets map[string]*AzureSecret
+}
+
+func (m *mockKeyVault) GetSecret(ctx context.Context, name string) (*AzureSecret, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.secrets[name], nil
+}
+
+// set stores a new version of the secret name, or deletes it if value is empty
+func (m *mockKeyVault) set(name, value string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if value == "" {
+		delete(m.secrets, name)
+		return
+	}
+	version := 1
+	if s, ok := m.secrets[name]; ok {
+		version, _ = strconv.Atoi(s.Version)
+		version++
+	}
+	m.secrets[name] = &AzureSecret{Value: value, Version: strconv.Itoa(version)}
+}
+
+func TestAzureKeyVault(t *testing.T) {
+	m := &mockKeyVault{secrets: map[string]*AzureSecret{
+		"app-host":     {Value: "a", Version: "1"},
+		"app-password": {Value: "p", Version: "1"},
+		"app-port":     {Value: "1", Version: "1"},
+	}}
+	kv := &AzureKeyVault{API: m, Interval: 10 * time.Millisecond}
+	var cfg WatchConfig
+	n := &KVNotifier{KV: kv, Prefix: "/app/"}
+	w, err := Watch(NewKVClient(kv), &cfg, nil, n)
+	assert.NoError(t, err)
+	defer w.Stop()
+	w.RLock()
+	assert.Equal(t, "a", cfg.Host)
+	assert.Equal(t, 1, cfg.Nested.Port)
+	w.RUnlock()
+
+	m.set("app-port", "2")
+	assert.True(t, waitChange(t, w))
+	w.RLock()
+	assert.Equal(t, 2, cfg.Nested.Port)
+	w.RUnlock()
+
+	m.set("app-port", "")
+	assert.EqualError(t, Load(NewKVClient(&AzureKeyVault{API: m}), &cfg), "invalid parameters: /app/port")
+}
@@ -0,0 +1,214 @@
+package figgy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// WithBatchSize sets how many parameters are requested by each call to GetParameters.
+// Sizes outside of 1 to 10, the most Parameter Store allows, request 10.
+func WithBatchSize(n int) Option {
+	return func(o *options) {
+		if n < 1 || n > maxParameters {
+			n = maxParameters
+		}
+		o.batchSize = n
+	}
+}
+
+// WithGetParameter requests parameters one at a time with GetParameter, rather than
+// together with GetParameters, when at most n would be requested together, such as
+// for a struct with one or two fields.  GetParameter has its own throughput limit,
+// which is higher than that of GetParameters in some accounts.  The API used for
+// each request is given to WithMetrics in BatchTiming.
+func WithGetParameter(n int) Option {
+	return func(o *options) {
+		o.singleLimit = n
+	}
+}
+
+// WithPathThreshold loads parameters with GetParametersByPath, rather than in batches
+// of GetParameters, when more than n of them and most of those being loaded share a
+// path.  Every parameter under the path is fetched, so this suits structs loading most
+// of a hierarchy, and requires the ssm:GetParametersByPath permission.
+func WithPathThreshold(n int) Option {
+	return func(o *options) {
+		o.pathLimit = n
+	}
+}
+
+// BatchError is returned when a request for a batch of parameters fails, naming the
+// parameters of the batch so throttling or an outage can be traced to the parameters
+// that caused it.  Requests IAM denies return an AccessDeniedError instead.
+type BatchError struct {
+	// Keys of the parameters requested
+	Keys []string
+	// Err is the error of the request
+	Err error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("failed to request parameters %s: %v", strings.Join(e.Keys, ", "), e.Err)
+}
+
+// Unwrap returns the error of the request
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// loadGroup loads fields with the same decryption, those under a shared path with
+// GetParametersByPath when the path threshold is exceeded and the rest in batches.
+// Missing parameters don't stop the remaining batches from being loaded.
+func loadGroup(c ssmiface.SSMAPI, f []*field, decrypt bool, o *options) error {
+	var missing *InvalidParametersError
+	if path := o.sharedPath(f); path != "" {
+		under, rest := partitionFields(f, func(x *field) bool {
+			return !strings.HasPrefix(x.key, path+"/")
+		})
+		var err error
+		if missing, err = collectMissing(missing, loadPath(c, path, under, decrypt, o)); err != nil {
+			return err
+		}
+		f = rest
+	}
+	err := batchIterateFields(groupFields(f), o.batchSize, func(f []*field) error {
+		var err error
+		missing, err = collectMissing(missing, loadParameters(c, f, decrypt, o))
+		return err
+	})
+	if err == nil && missing != nil {
+		return missing
+	}
+	return err
+}
+
+// collectMissing adds the missing parameters of err to missing, or returns err when
+// it's another error, so loading can continue past missing parameters and report
+// all of them at once
+func collectMissing(missing *InvalidParametersError, err error) (*InvalidParametersError, error) {
+	e, ok := err.(*InvalidParametersError)
+	if !ok {
+		return missing, err
+	}
+	if missing == nil {
+		return e, nil
+	}
+	missing.Names = append(missing.Names, e.Names...)
+	for name, s := range e.Suggestions {
+		if missing.Suggestions == nil {
+			missing.Suggestions = make(map[string]string)
+		}
+		missing.Suggestions[name] = s
+	}
+	return missing, nil
+}
+
+// sharedPath returns the deepest path shared by more than the path threshold and
+// more than half of the fields' keys, or "" if there's none
+func (o *options) sharedPath(f []*field) string {
+	if o.pathLimit <= 0 {
+		return ""
+	}
+	keys := parameterNames(f)
+	counts := make(map[string]int)
+	for _, k := range aws.StringValueSlice(keys) {
+		if !strings.HasPrefix(k, "/") {
+			continue
+		}
+		for i := strings.LastIndex(k, "/"); i > 0; i = strings.LastIndex(k[:i], "/") {
+			counts[k[:i]]++
+		}
+	}
+	var path string
+	for p, n := range counts {
+		if n > o.pathLimit && 2*n > len(keys) && strings.Count(p, "/") > strings.Count(path, "/") {
+			path = p
+		}
+	}
+	return path
+}
+
+// getParametersByPath returns all of the parameters under path
+func getParametersByPath(c ssmiface.SSMAPI, path string, recursive, decrypt bool) ([]*ssm.Parameter, error) {
+	it := &ParamIterator{c: c, in: &ssm.GetParametersByPathInput{
+		Path:           aws.String(path),
+		Recursive:      aws.Bool(recursive),
+		WithDecryption: aws.Bool(decrypt),
+	}}
+	var params []*ssm.Parameter
+	for it.Next() {
+		params = append(params, it.Param())
+	}
+	return params, it.Err()
+}
+
+// loadPath loads fields with keys under path using GetParametersByPath
+func loadPath(c ssmiface.SSMAPI, path string, f []*field, decrypt bool, o *options) error {
+	o.logger.Debug("figgy: requesting parameters by path", "path", path, "keys", aws.StringValueSlice(parameterNames(f)), "decrypt", decrypt)
+	params, err := getParametersByPath(c, path, true, decrypt)
+	if isAccessDenied(err) {
+		return requestError(err, []string{path}, "ssm:GetParametersByPath", decrypt)
+	}
+	if err != nil {
+		// name the path and the keys under it, so errors such as throttling say which
+		// parameters weren't loaded
+		return &BatchError{Keys: append([]string{path}, aws.StringValueSlice(parameterNames(f))...), Err: err}
+	}
+	idx := indexParameters(params)
+	var missing []string
+	for _, k := range aws.StringValueSlice(parameterNames(f)) {
+		if _, ok := idx[k]; !ok {
+			missing = append(missing, k)
+		}
+	}
+	missing, err = o.resolveCase(c, idx, missing, decrypt)
+	if err != nil {
+		return err
+	}
+	if len(missing) != 0 && o.resetMissing {
+		o.logger.Debug("figgy: resetting fields of missing parameters", "keys", missing)
+		f, missing = resetFields(f, missing), nil
+	}
+	if len(missing) != 0 {
+		o.logger.Debug("figgy: invalid parameters", "keys", missing)
+		for _, name := range missing {
+			o.metrics.ParameterFailed(name)
+		}
+		if err := assignParameters(c, foundFields(f, missing), idx, o); err != nil {
+			return err
+		}
+		return &InvalidParametersError{Names: missing, Suggestions: suggestNames(c, missing)}
+	}
+	return assignParameters(c, f, idx, o)
+}
+
+// getEachParameter requests the parameters of the fields one at a time, returning
+// those found and an InvalidParametersError for those that don't exist, as
+// getParameters does
+func getEachParameter(c ssmiface.SSMAPI, f []*field, decrypt bool) ([]*ssm.Parameter, error) {
+	var params []*ssm.Parameter
+	var missing []string
+	for _, name := range parameterNames(f) {
+		res, err := c.GetParameter(&ssm.GetParameterInput{
+			Name:           name,
+			WithDecryption: aws.Bool(decrypt),
+		})
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == ssm.ErrCodeParameterNotFound {
+			missing = append(missing, aws.StringValue(name))
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, res.Parameter)
+	}
+	if len(missing) != 0 {
+		return params, &InvalidParametersError{Names: missing}
+	}
+	return params, nil
+}
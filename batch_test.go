@@ -0,0 +1,190 @@
+package figgy
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/stretchr/testify/assert"
+)
+
+func newBatchClient() *countingSSMClient {
+	values := map[string]string{"other": "o"}
+	for i := 0; i < 12; i++ {
+		values[fmt.Sprintf("/app/svc/%c", 'a'+i)] = fmt.Sprint(i)
+	}
+	return &countingSSMClient{MockSSMClient: NewMockSSMClientWith(values)}
+}
+
+func TestWithBatchSize(t *testing.T) {
+	type config struct {
+		A     string `ssm:"/app/svc/a"`
+		B     string `ssm:"/app/svc/b"`
+		C     string `ssm:"/app/svc/c"`
+		D     string `ssm:"/app/svc/d"`
+		Other string `ssm:"other"`
+	}
+	c := newBatchClient()
+	var cfg config
+	assert.NoError(t, Load(c, &cfg, WithBatchSize(2)))
+	assert.Equal(t, []int{2, 2, 1}, c.batches)
+	assert.Equal(t, "3", cfg.D)
+
+	c = newBatchClient()
+	assert.NoError(t, Load(c, &cfg, WithBatchSize(20)))
+	assert.Equal(t, []int{5}, c.batches)
+	assert.Empty(t, c.paths)
+}
+
+func TestWithPathThreshold(t *testing.T) {
+	type config struct {
+		A     string `ssm:"/app/svc/a"`
+		B     string `ssm:"/app/svc/b"`
+		C     string `ssm:"/app/svc/c"`
+		L     string `ssm:"/app/svc/l"`
+		Other string `ssm:"other"`
+	}
+	c := newBatchClient()
+	var cfg config
+	assert.NoError(t, Load(c, &cfg, WithPathThreshold(3)))
+	// the path has more parameters than fit in a page
+	assert.Equal(t, []string{"/app/svc", "/app/svc"}, c.paths)
+	assert.Equal(t, []int{1}, c.batches)
+	assert.Equal(t, config{A: "0", B: "1", C: "2", L: "11", Other: "o"}, cfg)
+
+	// not enough of the keys share a path
+	c = newBatchClient()
+	assert.NoError(t, Load(c, &cfg, WithPathThreshold(4)))
+	assert.Empty(t, c.paths)
+	assert.Equal(t, []int{5}, c.batches)
+
+	c = newBatchClient()
+	delete(c.Data, "/app/svc/b")
+	m := &recordingMetrics{}
+	cfg = config{}
+	err := Load(c, &cfg, WithPathThreshold(3), WithMetrics(m))
+	assert.EqualError(t, err, "invalid parameters: /app/svc/b")
+	// the path is listed again for suggestions, and the rest of the keys are still
+	// requested
+	assert.Equal(t, []int{10, 1, 10, 1, 1}, m.batches)
+	assert.Equal(t, "o", cfg.Other)
+	assert.Equal(t, []string{"/app/svc/b"}, m.failed)
+}
+
+func TestWithGetParameter(t *testing.T) {
+	var cfg struct {
+		A string `ssm:"/app/svc/a"`
+		B string `ssm:"/app/svc/b"`
+		C string `ssm:"/app/svc/b"`
+	}
+	c := newBatchClient()
+	m := &timingMetrics{}
+	assert.NoError(t, Load(c, &cfg, WithGetParameter(2), WithMetrics(m)))
+	assert.Empty(t, c.batches)
+	assert.Equal(t, "1", cfg.C)
+	if assert.Len(t, m.timings, 2) {
+		assert.Equal(t, "GetParameter", m.timings[0].API)
+		assert.Equal(t, []string{"/app/svc/a"}, m.timings[0].Keys)
+	}
+
+	// more parameters are requested together
+	c = newBatchClient()
+	assert.NoError(t, Load(c, &cfg, WithGetParameter(1)))
+	assert.Equal(t, []int{2}, c.batches)
+
+	var missing struct {
+		A string `ssm:"/app/svc/a"`
+		Z string `ssm:"/app/svc/z"`
+	}
+	err := Load(newBatchClient(), &missing, WithGetParameter(2))
+	if assert.IsType(t, &InvalidParametersError{}, err) {
+		assert.Equal(t, []string{"/app/svc/z"}, err.(*InvalidParametersError).Names)
+	}
+}
+
+func TestBatchError(t *testing.T) {
+	denied := errors.New("access denied")
+	c := &failingSSMClient{MockSSMClient: NewMockSSMClient(), err: denied}
+	s := struct {
+		S string `ssm:"string"`
+		I int    `ssm:"int"`
+		B bool   `ssm:"bool,decrypt"`
+	}{}
+	err := Load(c, &s)
+	assert.Equal(t, &BatchError{Keys: []string{"string", "int"}, Err: denied}, err)
+	assert.EqualError(t, err, "failed to request parameters string, int: access denied")
+	assert.True(t, errors.Is(err, denied))
+}
+
+// throttledPathClient fails every GetParametersByPath request with err
+type throttledPathClient struct {
+	*MockSSMClient
+	err error
+}
+
+func (c *throttledPathClient) GetParametersByPath(i *ssm.GetParametersByPathInput) (*ssm.GetParametersByPathOutput, error) {
+	return nil, c.err
+}
+
+func TestBatchErrorPath(t *testing.T) {
+	throttled := awserr.New("ThrottlingException", "rate exceeded", nil)
+	c := &throttledPathClient{MockSSMClient: newBatchClient().MockSSMClient, err: throttled}
+	var cfg struct {
+		A string `ssm:"/app/svc/a"`
+		B string `ssm:"/app/svc/b"`
+	}
+	err := Load(c, &cfg, WithPathThreshold(1))
+	assert.Equal(t, &BatchError{Keys: []string{"/app/svc", "/app/svc/a", "/app/svc/b"}, Err: throttled}, err)
+	assert.True(t, errors.Is(err, throttled))
+}
+
+func TestPartialBatch(t *testing.T) {
+	type config struct {
+		A string `ssm:"/app/svc/a"`
+		B string `ssm:"/app/svc/b"`
+		Z string `ssm:"/app/svc/z"`
+	}
+	var cfg config
+	err := Load(newBatchClient(), &cfg)
+	assert.EqualError(t, err, "invalid parameters: /app/svc/z")
+	assert.Equal(t, config{A: "0", B: "1"}, cfg)
+
+	// and under a shared path
+	cfg = config{}
+	err = Load(newBatchClient(), &cfg, WithPathThreshold(2))
+	assert.EqualError(t, err, "invalid parameters: /app/svc/z")
+	assert.Equal(t, config{A: "0", B: "1"}, cfg)
+
+	// batches after the one missing a parameter are still loaded
+	type batched struct {
+		A string `ssm:"/app/svc/a"`
+		Z string `ssm:"/app/svc/z"`
+		C string `ssm:"/app/svc/c"`
+	}
+	var b batched
+	err = Load(newBatchClient(), &b, WithBatchSize(2))
+	assert.EqualError(t, err, "invalid parameters: /app/svc/z")
+	assert.Equal(t, batched{A: "0", C: "2"}, b)
+}
+
+func TestMissingAcrossBatches(t *testing.T) {
+	type config struct {
+		A string `ssm:"/app/svc/a"`
+		Y string `ssm:"/app/svc/y"`
+		C string `ssm:"/app/svc/c"`
+		Z string `ssm:"/app/svc/z"`
+		X string `ssm:"/app/svc/x,decrypt"`
+		V string `ssm:"/app/svc/v,old=/app/svc/u"`
+		W string `ssm:"/app/svc/w,chunks"`
+		B string `ssm:"/app/svc/b,old=/app/svc/t"`
+	}
+	var cfg config
+	err := Load(newBatchClient(), &cfg, WithBatchSize(2))
+	if e, ok := err.(*InvalidParametersError); assert.True(t, ok, "%v", err) {
+		// every missing parameter is named, whichever request it was in
+		assert.Equal(t, []string{"/app/svc/v", "/app/svc/u", "/app/svc/w/part-000", "/app/svc/y", "/app/svc/z", "/app/svc/x"}, e.Names)
+	}
+	assert.Equal(t, config{A: "0", C: "2", B: "1"}, cfg)
+}
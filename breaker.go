@@ -0,0 +1,168 @@
+package figgy
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// ErrBreakerOpen is returned instead of making requests to Parameter Store while a
+// Breaker is open
+var ErrBreakerOpen = errors.New("figgy: circuit breaker open")
+
+// Breaker stops requests to Parameter Store after consecutive failures, so loads fail
+// fast with ErrBreakerOpen and watchers stop retrying while it's erroring.  After a
+// cooldown, a single request is let through: the breaker closes when it succeeds and
+// opens for another cooldown when it fails.  A Breaker can be shared by loads and
+// watchers using the same Parameter Store.
+type Breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	failures int
+	opened   time.Time
+	trying   bool
+}
+
+// NewBreaker returns a Breaker that opens after threshold consecutive failed requests
+// and stays open for cooldown
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &Breaker{threshold: threshold, cooldown: cooldown}
+}
+
+// Open reports whether requests are being stopped
+func (b *Breaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures >= b.threshold && (b.trying || time.Since(b.opened) < b.cooldown)
+}
+
+// allow returns ErrBreakerOpen unless a request can be made
+func (b *Breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.threshold {
+		return nil
+	}
+	if b.trying || time.Since(b.opened) < b.cooldown {
+		return ErrBreakerOpen
+	}
+	b.trying = true
+	return nil
+}
+
+// done records the result of a request that was allowed.  Cancelled requests
+// aren't failures of Parameter Store.
+func (b *Breaker) done(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trying = false
+	switch err {
+	case nil:
+		b.failures = 0
+	case context.Canceled:
+	default:
+		b.failures++
+		if b.failures >= b.threshold {
+			b.opened = time.Now()
+		}
+	}
+}
+
+// WithBreaker stops requests to Parameter Store while b is open
+func WithBreaker(b *Breaker) Option {
+	return func(o *options) {
+		o.breaker = b
+	}
+}
+
+// guardedSSM makes requests only while its breaker allows them
+type guardedSSM struct {
+	ssmiface.SSMAPI
+	breaker *Breaker
+}
+
+// guard wraps c to make requests only while the breaker allows them, unless no
+// breaker is configured
+func (o *options) guard(c ssmiface.SSMAPI) ssmiface.SSMAPI {
+	if o.breaker == nil {
+		return c
+	}
+	return &guardedSSM{SSMAPI: c, breaker: o.breaker}
+}
+
+// do makes a request with f if the breaker allows it
+func (c *guardedSSM) do(f func() error) error {
+	if err := c.breaker.allow(); err != nil {
+		return err
+	}
+	err := f()
+	c.breaker.done(err)
+	return err
+}
+
+func (c *guardedSSM) GetParameter(in *ssm.GetParameterInput) (out *ssm.GetParameterOutput, err error) {
+	err = c.do(func() error {
+		out, err = c.SSMAPI.GetParameter(in)
+		return err
+	})
+	return out, err
+}
+
+func (c *guardedSSM) GetParameterWithContext(ctx aws.Context, in *ssm.GetParameterInput, opts ...request.Option) (out *ssm.GetParameterOutput, err error) {
+	err = c.do(func() error {
+		out, err = c.SSMAPI.GetParameterWithContext(ctx, in, opts...)
+		return err
+	})
+	return out, err
+}
+
+func (c *guardedSSM) GetParameters(in *ssm.GetParametersInput) (out *ssm.GetParametersOutput, err error) {
+	err = c.do(func() error {
+		out, err = c.SSMAPI.GetParameters(in)
+		return err
+	})
+	return out, err
+}
+
+func (c *guardedSSM) GetParametersWithContext(ctx aws.Context, in *ssm.GetParametersInput, opts ...request.Option) (out *ssm.GetParametersOutput, err error) {
+	err = c.do(func() error {
+		out, err = c.SSMAPI.GetParametersWithContext(ctx, in, opts...)
+		return err
+	})
+	return out, err
+}
+
+func (c *guardedSSM) GetParametersByPath(in *ssm.GetParametersByPathInput) (out *ssm.GetParametersByPathOutput, err error) {
+	err = c.do(func() error {
+		out, err = c.SSMAPI.GetParametersByPath(in)
+		return err
+	})
+	return out, err
+}
+
+func (c *guardedSSM) GetParametersByPathWithContext(ctx aws.Context, in *ssm.GetParametersByPathInput, opts ...request.Option) (out *ssm.GetParametersByPathOutput, err error) {
+	err = c.do(func() error {
+		out, err = c.SSMAPI.GetParametersByPathWithContext(ctx, in, opts...)
+		return err
+	})
+	return out, err
+}
+
+func (c *guardedSSM) DescribeParameters(in *ssm.DescribeParametersInput) (out *ssm.DescribeParametersOutput, err error) {
+	err = c.do(func() error {
+		out, err = c.SSMAPI.DescribeParameters(in)
+		return err
+	})
+	return out, err
+}
@@ -0,0 +1,76 @@
+package figgy
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/stretchr/testify/assert"
+)
+
+// failingSSMClient fails requests with err, when set
+type failingSSMClient struct {
+	*MockSSMClient
+	err   error
+	calls int
+}
+
+func (c *failingSSMClient) GetParameters(i *ssm.GetParametersInput) (*ssm.GetParametersOutput, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.MockSSMClient.GetParameters(i)
+}
+
+func TestWithBreaker(t *testing.T) {
+	unavailable := errors.New("service unavailable")
+	c := &failingSSMClient{MockSSMClient: NewMockSSMClient(), err: unavailable}
+	b := NewBreaker(2, 50*time.Millisecond)
+	s := struct {
+		S string `ssm:"string"`
+	}{}
+	assert.True(t, errors.Is(Load(c, &s, WithBreaker(b)), unavailable))
+	assert.False(t, b.Open())
+	assert.True(t, errors.Is(Load(c, &s, WithBreaker(b)), unavailable))
+	assert.True(t, b.Open())
+	assert.True(t, errors.Is(Load(c, &s, WithBreaker(b)), ErrBreakerOpen))
+	assert.Equal(t, 2, c.calls)
+
+	// a failed request after the cooldown opens the breaker again
+	time.Sleep(60 * time.Millisecond)
+	assert.False(t, b.Open())
+	assert.True(t, errors.Is(Load(c, &s, WithBreaker(b)), unavailable))
+	assert.True(t, errors.Is(Load(c, &s, WithBreaker(b)), ErrBreakerOpen))
+	assert.Equal(t, 3, c.calls)
+
+	// and a successful one closes it
+	time.Sleep(60 * time.Millisecond)
+	c.err = nil
+	assert.NoError(t, Load(c, &s, WithBreaker(b)))
+	assert.NoError(t, Load(c, &s, WithBreaker(b)))
+	assert.False(t, b.Open())
+	assert.Equal(t, 5, c.calls)
+	assert.Equal(t, "this is a string", s.S)
+}
+
+func TestWatchBreaker(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/host": "a", "/app/password": "p", "/app/port": "1"})
+	c := &failingSSMClient{MockSSMClient: m}
+	n := make(chanNotifier)
+	b := NewBreaker(1, time.Hour)
+	var cfg WatchConfig
+	w, err := Watch(c, &cfg, nil, n, WithBreaker(b))
+	assert.NoError(t, err)
+	defer w.Stop()
+
+	c.err = errors.New("service unavailable")
+	n <- []string{"/app/host"}
+	n.settle()
+	assert.True(t, errors.Is(w.LastError(), c.err))
+	n <- []string{"/app/host"}
+	n.settle()
+	assert.True(t, errors.Is(w.LastError(), ErrBreakerOpen))
+	assert.Equal(t, 3, c.calls)
+}
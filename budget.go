@@ -0,0 +1,50 @@
+package figgy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WithStartupBudget gives a load at most d for all of its requests to Parameter Store.
+// When the budget runs out, the fields not yet loaded keep the values they had, such
+// as defaults set before loading, and the load returns a *DegradedError naming them
+// rather than its request's error.  A service that can run with those values can
+// treat the error as a warning:
+//
+//	err := figgy.Load(c, &cfg, figgy.WithStartupBudget(500*time.Millisecond))
+//	if e, ok := err.(*figgy.DegradedError); ok {
+//		log.Printf("using defaults for %v", e.Fields)
+//	} else if err != nil {
+//		return err
+//	}
+//
+// The budget only applies to loads, not to the reloads of a watcher.
+func WithStartupBudget(d time.Duration) Option {
+	return func(o *options) {
+		o.budget = d
+	}
+}
+
+// DegradedError is returned by a load that ran out of its startup budget
+type DegradedError struct {
+	// Fields that weren't loaded
+	Fields []string
+	// Err is the error of the request the budget ran out during
+	Err error
+}
+
+func (e *DegradedError) Error() string {
+	return fmt.Sprintf("startup budget exceeded, fields not loaded: %s: %v", strings.Join(e.Fields, ", "), e.Err)
+}
+
+// degraded returns a DegradedError for the fields that weren't loaded before err
+func degraded(f []*field, err error) error {
+	e := &DegradedError{Err: err}
+	for _, x := range f {
+		if !x.loaded {
+			e.Fields = append(e.Fields, x.field.Name)
+		}
+	}
+	return e
+}
@@ -0,0 +1,54 @@
+package figgy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/stretchr/testify/assert"
+)
+
+// hangingSSMClient answers requests without decryption, and waits for the context of
+// those with decryption to be done
+type hangingSSMClient struct {
+	*MockSSMClient
+}
+
+func (c *hangingSSMClient) GetParametersWithContext(ctx aws.Context, in *ssm.GetParametersInput, opts ...request.Option) (*ssm.GetParametersOutput, error) {
+	if !aws.BoolValue(in.WithDecryption) {
+		return c.MockSSMClient.GetParameters(in)
+	}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestWithStartupBudget(t *testing.T) {
+	c := &hangingSSMClient{MockSSMClient: NewMockSSMClient()}
+	s := struct {
+		S string `ssm:"string"`
+		I int    `ssm:"int,decrypt"`
+		B bool   `ssm:"bool,decrypt"`
+	}{I: 42}
+	start := time.Now()
+	err := Load(c, &s, WithStartupBudget(20*time.Millisecond))
+	assert.True(t, time.Since(start) < time.Second)
+	assert.Equal(t, &DegradedError{Fields: []string{"I", "B"}, Err: &BatchError{Keys: []string{"int", "bool"}, Err: context.DeadlineExceeded}}, err)
+	assert.EqualError(t, err, "startup budget exceeded, fields not loaded: I, B: failed to request parameters int, bool: context deadline exceeded")
+	assert.Equal(t, "this is a string", s.S)
+	assert.Equal(t, 42, s.I)
+
+	// the struct is still validated
+	invalid := errors.New("invalid")
+	err = Load(c, &s, WithStartupBudget(20*time.Millisecond), WithValidator(func(interface{}) error {
+		return invalid
+	}))
+	assert.Equal(t, invalid, err)
+
+	// loads within the budget succeed
+	assert.NoError(t, Load(&slowSSMClient{MockSSMClient: NewMockSSMClient()}, &s, WithStartupBudget(time.Second)))
+	assert.Equal(t, 2, s.I)
+}
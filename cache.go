@@ -0,0 +1,170 @@
+package figgy
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// Cache is a client that keeps the parameters it gets in memory and serves them from
+// there, without checking for changes, so that a process such as a Lambda function
+// requests each parameter once rather than on every invocation.  Parameters that
+// don't exist aren't cached.  A Cache is safe for concurrent use.
+//
+//	cache := figgy.NewCache(ssm.New(sess))
+//
+//	func init() {
+//		if err := cache.PrimeCache(context.Background(), &Config{}, nil); err != nil {
+//			panic(err)
+//		}
+//	}
+//
+//	func handler(ctx context.Context) error {
+//		var cfg Config
+//		if err := figgy.Load(cache, &cfg); err != nil { // served from memory
+//			return err
+//		}
+//		...
+//	}
+type Cache struct {
+	ssmiface.SSMAPI
+	mu     sync.RWMutex
+	params map[cacheKey]*ssm.Parameter
+}
+
+// cacheKey identifies a cached parameter, whose value depends on whether it was
+// requested with decryption
+type cacheKey struct {
+	name    string
+	decrypt bool
+}
+
+// NewCache returns a Cache that gets parameters from c
+func NewCache(c ssmiface.SSMAPI) *Cache {
+	return &Cache{SSMAPI: c, params: make(map[cacheKey]*ssm.Parameter)}
+}
+
+// PrimeCache loads v with LoadWithParameters using the cache, with requests made with
+// ctx, so the parameters of v are in memory for later loads.  Call it while the
+// process initializes, such as during a Lambda function's init phase.
+func (c *Cache) PrimeCache(ctx context.Context, v interface{}, data interface{}, opts ...Option) error {
+	return LoadWithParameters(&primingClient{Cache: c, ctx: ctx}, v, data, opts...)
+}
+
+// primingClient makes the requests of a load through a cache with a context
+type primingClient struct {
+	*Cache
+	ctx context.Context
+}
+
+func (c *primingClient) GetParameter(in *ssm.GetParameterInput) (*ssm.GetParameterOutput, error) {
+	return c.Cache.GetParameterWithContext(c.ctx, in)
+}
+
+func (c *primingClient) GetParameters(in *ssm.GetParametersInput) (*ssm.GetParametersOutput, error) {
+	return c.Cache.GetParametersWithContext(c.ctx, in)
+}
+
+func (c *primingClient) GetParametersByPath(in *ssm.GetParametersByPathInput) (*ssm.GetParametersByPathOutput, error) {
+	return c.Cache.GetParametersByPathWithContext(c.ctx, in)
+}
+
+func (c *Cache) GetParameter(in *ssm.GetParameterInput) (*ssm.GetParameterOutput, error) {
+	return c.getParameter(in, c.SSMAPI.GetParameter)
+}
+
+func (c *Cache) GetParameterWithContext(ctx aws.Context, in *ssm.GetParameterInput, opts ...request.Option) (*ssm.GetParameterOutput, error) {
+	return c.getParameter(in, func(in *ssm.GetParameterInput) (*ssm.GetParameterOutput, error) {
+		return c.SSMAPI.GetParameterWithContext(ctx, in, opts...)
+	})
+}
+
+func (c *Cache) GetParameters(in *ssm.GetParametersInput) (*ssm.GetParametersOutput, error) {
+	return c.getParameters(in, c.SSMAPI.GetParameters)
+}
+
+func (c *Cache) GetParametersWithContext(ctx aws.Context, in *ssm.GetParametersInput, opts ...request.Option) (*ssm.GetParametersOutput, error) {
+	return c.getParameters(in, func(in *ssm.GetParametersInput) (*ssm.GetParametersOutput, error) {
+		return c.SSMAPI.GetParametersWithContext(ctx, in, opts...)
+	})
+}
+
+// GetParametersByPath always requests the parameters under the path, since the
+// cache can't tell which of them exist, and caches those returned
+func (c *Cache) GetParametersByPath(in *ssm.GetParametersByPathInput) (*ssm.GetParametersByPathOutput, error) {
+	out, err := c.SSMAPI.GetParametersByPath(in)
+	if err == nil {
+		c.store(out.Parameters, aws.BoolValue(in.WithDecryption))
+	}
+	return out, err
+}
+
+func (c *Cache) GetParametersByPathWithContext(ctx aws.Context, in *ssm.GetParametersByPathInput, opts ...request.Option) (*ssm.GetParametersByPathOutput, error) {
+	out, err := c.SSMAPI.GetParametersByPathWithContext(ctx, in, opts...)
+	if err == nil {
+		c.store(out.Parameters, aws.BoolValue(in.WithDecryption))
+	}
+	return out, err
+}
+
+// getParameter serves a parameter from the cache, or with get when it's not cached
+func (c *Cache) getParameter(in *ssm.GetParameterInput, get func(*ssm.GetParameterInput) (*ssm.GetParameterOutput, error)) (*ssm.GetParameterOutput, error) {
+	decrypt := aws.BoolValue(in.WithDecryption)
+	if p, ok := c.load(aws.StringValue(in.Name), decrypt); ok {
+		return &ssm.GetParameterOutput{Parameter: p}, nil
+	}
+	out, err := get(in)
+	if err == nil && out.Parameter != nil {
+		c.store([]*ssm.Parameter{out.Parameter}, decrypt)
+	}
+	return out, err
+}
+
+// getParameters serves the cached parameters of a request, getting the others with get
+func (c *Cache) getParameters(in *ssm.GetParametersInput, get func(*ssm.GetParametersInput) (*ssm.GetParametersOutput, error)) (*ssm.GetParametersOutput, error) {
+	decrypt := aws.BoolValue(in.WithDecryption)
+	out := &ssm.GetParametersOutput{}
+	var missing []*string
+	for _, name := range in.Names {
+		if p, ok := c.load(aws.StringValue(name), decrypt); ok {
+			out.Parameters = append(out.Parameters, p)
+		} else {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) == 0 {
+		return out, nil
+	}
+	res, err := get(&ssm.GetParametersInput{Names: missing, WithDecryption: in.WithDecryption})
+	if err != nil {
+		return nil, err
+	}
+	c.store(res.Parameters, decrypt)
+	out.Parameters = append(out.Parameters, res.Parameters...)
+	out.InvalidParameters = res.InvalidParameters
+	return out, nil
+}
+
+func (c *Cache) load(name string, decrypt bool) (*ssm.Parameter, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	p, ok := c.params[cacheKey{name, decrypt}]
+	return p, ok
+}
+
+// store caches params under their names, and under the ARNs of parameters requested
+// by ARN
+func (c *Cache) store(params []*ssm.Parameter, decrypt bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, p := range params {
+		c.params[cacheKey{aws.StringValue(p.Name), decrypt}] = p
+		if p.ARN != nil {
+			c.params[cacheKey{aws.StringValue(p.ARN), decrypt}] = p
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package figgy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	c := &slowSSMClient{MockSSMClient: NewMockSSMClient()}
+	cache := NewCache(c)
+	type config struct {
+		S string `ssm:"string"`
+		I int    `ssm:"int,decrypt"`
+	}
+	var primed config
+	assert.NoError(t, cache.PrimeCache(context.Background(), &primed, nil))
+	assert.Equal(t, "this is a string", primed.S)
+	assert.Equal(t, 2, primed.I)
+
+	// later loads are served from memory
+	setParameter(c.MockSSMClient, "string", "changed")
+	var cfg config
+	assert.NoError(t, Load(cache, &cfg))
+	assert.Equal(t, primed, cfg)
+
+	// parameters that weren't primed are requested and cached, except those that
+	// don't exist
+	counting := &countingSSMClient{MockSSMClient: c.MockSSMClient}
+	cache.SSMAPI = counting
+	var more struct {
+		S string `ssm:"string"`
+		B bool   `ssm:"bool"`
+	}
+	assert.NoError(t, Load(cache, &more))
+	assert.Equal(t, "this is a string", more.S)
+	assert.True(t, more.B)
+	assert.Equal(t, []int{1}, counting.batches)
+	assert.NoError(t, Load(cache, &more))
+	assert.Equal(t, []int{1}, counting.batches)
+
+	var missing struct {
+		M string `ssm:"/no/such/param"`
+	}
+	assert.EqualError(t, Load(cache, &missing), "invalid parameters: /no/such/param")
+	assert.Error(t, Load(cache, &missing))
+	assert.Equal(t, []int{1, 1, 1}, counting.batches)
+}
+
+func TestCacheDecryption(t *testing.T) {
+	cache := NewCache(NewMockSSMClient())
+	var plain struct {
+		S string `ssm:"string"`
+	}
+	assert.NoError(t, Load(cache, &plain))
+	assert.Len(t, cache.params, 1)
+	// a value requested with decryption is cached separately
+	var decrypted struct {
+		S string `ssm:"string,decrypt"`
+	}
+	assert.NoError(t, Load(cache, &decrypted))
+	assert.Len(t, cache.params, 2)
+}
@@ -0,0 +1,172 @@
+package figgy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// Layer is a KV in a Chain.  Keys are looked up in the layer with Prefix prepended,
+// so a layer with the prefix "/myapp/prod" loads the key "/db/host" from
+// "/myapp/prod/db/host".
+type Layer struct {
+	KV     KV
+	Prefix string
+}
+
+// chainKV is the KV returned by Chain
+type chainKV struct {
+	layers []Layer
+
+	keys    pollingKV
+	mu      sync.Mutex
+	current []int64
+	version int64
+}
+
+// Chain layers several KVs into one, with each layer overriding the layers before it,
+// so a key is loaded from the last layer that has it.  Its watch merges the watches of
+// every layer.
+//
+//	kv := figgy.Chain(
+//		figgy.Layer{KV: &figgy.ParameterStore{API: ssmClient}, Prefix: "/myapp/defaults"},
+//		figgy.Layer{KV: consul, Prefix: "/myapp/prod"},
+//	)
+//	w, err := figgy.Watch(figgy.NewKVClient(kv), &cfg, nil, &figgy.KVNotifier{KV: kv})
+func Chain(layers ...Layer) KV {
+	// versions start at 1, as 0 asks Watch for the current version
+	return &chainKV{layers: layers, current: make([]int64, len(layers)), version: 1}
+}
+
+// Get returns the value of key from the last layer that has it
+func (c *chainKV) Get(ctx context.Context, key string) (string, int64, bool, error) {
+	return c.keys.get(ctx, key, c.fetch)
+}
+
+func (c *chainKV) fetch(ctx context.Context, key string) (string, string, bool, error) {
+	for i := len(c.layers) - 1; i >= 0; i-- {
+		value, version, ok, err := c.layers[i].KV.Get(ctx, c.layers[i].Prefix+key)
+		if err != nil {
+			return "", "", false, err
+		}
+		if ok {
+			return value, fmt.Sprintf("%d:%d", i, version), true, nil
+		}
+	}
+	return "", "", false, nil
+}
+
+// watched is the result of watching a layer
+type watched struct {
+	layer   int
+	keys    []string
+	current int64
+	err     error
+}
+
+// Watch watches every layer for changes to keys beginning with prefix, returning when
+// the first of them changes
+func (c *chainKV) Watch(ctx context.Context, prefix string, version int64) ([]string, int64, error) {
+	c.mu.Lock()
+	current := append([]int64(nil), c.current...)
+	chainVersion := c.version
+	c.mu.Unlock()
+	if version == 0 {
+		for i, l := range c.layers {
+			if current[i] != 0 {
+				continue
+			}
+			_, v, err := l.KV.Watch(ctx, l.Prefix+prefix, 0)
+			if err != nil {
+				return nil, 0, err
+			}
+			c.setCurrent(i, v, false)
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return nil, c.version, nil
+	}
+	if chainVersion != version {
+		// a layer changed since the version
+		return nil, chainVersion, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan watched, len(c.layers))
+	for i, l := range c.layers {
+		go func(i int, l Layer) {
+			keys, v, err := l.KV.Watch(ctx, l.Prefix+prefix, current[i])
+			results <- watched{layer: i, keys: keys, current: v, err: err}
+		}(i, l)
+	}
+	r := <-results
+	if r.err != nil {
+		return nil, 0, r.err
+	}
+	if r.current == current[r.layer] {
+		// a blocking query timed out without a change
+		return nil, chainVersion, nil
+	}
+	var keys []string
+	if r.keys != nil {
+		keys = make([]string, len(r.keys))
+		for i, k := range r.keys {
+			keys[i] = strings.TrimPrefix(k, c.layers[r.layer].Prefix)
+		}
+	}
+	return keys, c.setCurrent(r.layer, r.current, true), nil
+}
+
+// setCurrent records the version of a layer, counting a change of the chain if it
+// changed
+func (c *chainKV) setCurrent(layer int, v int64, changed bool) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.current[layer] = v
+	if changed {
+		c.version++
+	}
+	return c.version
+}
+
+// ParameterStore is a KV of Parameter Store, for layering it with other KVs in a
+// Chain.  Parameters are loaded with decryption.  Watch checks the parameters that
+// have been loaded every Interval, a minute by default.
+type ParameterStore struct {
+	API      ssmiface.SSMAPI
+	Interval time.Duration
+
+	poll pollingKV
+}
+
+// Get returns the value of the parameter called key
+func (p *ParameterStore) Get(ctx context.Context, key string) (string, int64, bool, error) {
+	return p.poll.get(ctx, key, p.fetch)
+}
+
+// Watch polls the parameters beginning with prefix until one changes
+func (p *ParameterStore) Watch(ctx context.Context, prefix string, version int64) ([]string, int64, error) {
+	return p.poll.watch(ctx, prefix, version, p.Interval, p.fetch)
+}
+
+func (p *ParameterStore) fetch(ctx context.Context, key string) (string, string, bool, error) {
+	out, err := p.API.GetParameter(&ssm.GetParameterInput{
+		Name:           aws.String(key),
+		WithDecryption: aws.Bool(true),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == ssm.ErrCodeParameterNotFound {
+		return "", "", false, nil
+	}
+	if err != nil {
+		return "", "", false, err
+	}
+	return aws.StringValue(out.Parameter.Value), fmt.Sprint(aws.Int64Value(out.Parameter.Version)), true, nil
+}
@@ -0,0 +1,66 @@
+package figgy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChain(t *testing.T) {
+	defaults := &ParameterStore{API: NewMockSSMClientWith(map[string]string{
+		"/defaults/app/host":     "a",
+		"/defaults/app/password": "p",
+		"/defaults/app/port":     "1",
+	})}
+	prod := newMemoryKV(map[string]string{"/prod/app/port": "2"})
+	kv := Chain(Layer{KV: defaults, Prefix: "/defaults"}, Layer{KV: prod, Prefix: "/prod"})
+
+	var cfg WatchConfig
+	n := &KVNotifier{KV: kv, Prefix: "/app/"}
+	w, err := Watch(NewKVClient(kv), &cfg, nil, n)
+	assert.NoError(t, err)
+	defer w.Stop()
+	w.RLock()
+	assert.Equal(t, "a", cfg.Host)
+	assert.Equal(t, "p", cfg.Password)
+	assert.Equal(t, 2, cfg.Nested.Port)
+	w.RUnlock()
+
+	// wait for the notifier to start watching
+	for n.current() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	prod.set("/prod/app/host", "b")
+	assert.True(t, waitChange(t, w))
+	w.RLock()
+	assert.Equal(t, "b", cfg.Host)
+	w.RUnlock()
+}
+
+func TestChainWatchKeys(t *testing.T) {
+	first := newMemoryKV(map[string]string{"/a/x": "1"})
+	second := newMemoryKV(map[string]string{"/b/x": "2"})
+	kv := Chain(Layer{KV: first, Prefix: "/a"}, Layer{KV: second, Prefix: "/b"})
+	ctx := context.Background()
+
+	v, _, ok, err := kv.Get(ctx, "/x")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "2", v)
+
+	_, version, err := kv.Watch(ctx, "/", 0)
+	assert.NoError(t, err)
+	go first.set("/a/y", "3")
+	keys, current, err := kv.Watch(ctx, "/", version)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/y"}, keys)
+	assert.True(t, current > version)
+
+	// the parameter store layer is polled
+	p := &ParameterStore{API: NewMockSSMClientWith(map[string]string{"/x": "1"}), Interval: time.Millisecond}
+	_, _, ok, err = p.Get(ctx, "/missing")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
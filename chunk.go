@@ -0,0 +1,68 @@
+package figgy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// chunkKey is the name of a part of a chunked parameter
+func chunkKey(key string, i int) string {
+	return fmt.Sprintf("%s/part-%03d", strings.TrimSuffix(key, "/"), i)
+}
+
+// loadChunks loads a parameter that has been split into parts and assigns it to the field
+func loadChunks(c ssmiface.SSMAPI, x *field, o *options) error {
+	o.logger.Debug("figgy: requesting chunked parameter", "key", x.key, "decrypt", x.decrypt)
+	p, err := fetchChunks(c, x.key, x.decrypt)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		o.logger.Debug("figgy: invalid parameters", "keys", []string{chunkKey(x.key, 0)})
+		o.metrics.ParameterFailed(chunkKey(x.key, 0))
+		return &InvalidParametersError{Names: []string{chunkKey(x.key, 0)}}
+	}
+	return assign(c, x, aws.StringValue(p.Value), o)
+}
+
+// fetchChunks fetches a parameter that has been split into parts named /key/part-000,
+// /key/part-001, and so on.  Parts are requested in batches until the first missing
+// part and are concatenated in order into a single parameter with the metadata of the
+// first part.  A nil parameter is returned when the first part doesn't exist.
+func fetchChunks(c ssmiface.SSMAPI, key string, decrypt bool) (*ssm.Parameter, error) {
+	var first *ssm.Parameter
+	var b strings.Builder
+	for n := 0; ; n += maxParameters {
+		names := make([]*string, maxParameters)
+		for i := range names {
+			names[i] = aws.String(chunkKey(key, n+i))
+		}
+		res, err := c.GetParameters(&ssm.GetParametersInput{
+			Names:          names,
+			WithDecryption: aws.Bool(decrypt),
+		})
+		if err != nil {
+			return nil, err
+		}
+		idx := indexParameters(res.Parameters)
+		for i, name := range names {
+			p, ok := idx[aws.StringValue(name)]
+			if !ok {
+				if n+i == 0 {
+					return nil, nil
+				}
+				joined := *first
+				joined.Value = aws.String(b.String())
+				return &joined, nil
+			}
+			if first == nil {
+				first = p
+			}
+			b.WriteString(aws.StringValue(p.Value))
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package figgy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChunks(t *testing.T) {
+	parts := map[string]string{
+		"/chunked/json/part-000": `{"F1": `,
+		"/chunked/json/part-001": `1, "F2"`,
+		"/chunked/json/part-002": `: "2"}`,
+	}
+	// enough parts to span more than one batch
+	for i := 0; i < maxParameters+2; i++ {
+		parts[chunkKey("/chunked/string", i)] = "x"
+	}
+	m := NewMockSSMClientWith(parts)
+	var c struct {
+		JSON   SimpleJSON `ssm:"/chunked/json,json,chunks"`
+		String string     `ssm:"/chunked/string/,chunks,decrypt"`
+		Plain  string     `ssm:"string"`
+	}
+	err := Load(m, &c)
+	assert.NoError(t, err)
+	assert.Equal(t, SimpleJSON{F1: 1, F2: "2"}, c.JSON)
+	assert.Len(t, c.String, maxParameters+2)
+	assert.Equal(t, "this is a string", c.Plain)
+}
+
+func TestChunksMissing(t *testing.T) {
+	var c struct {
+		String string `ssm:"/no/such/param,chunks"`
+	}
+	err := Load(NewMockSSMClient(), &c)
+	assert.Error(t, err)
+}
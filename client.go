@@ -0,0 +1,139 @@
+package figgy
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/ec2metadata"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// ClientOption configures a client from NewClient
+type ClientOption func(*clientOptions)
+
+type clientOptions struct {
+	region     string
+	profile    string
+	endpoint   string
+	fips       bool
+	maxRetries int
+}
+
+// ClientRegion sets the region of the client, rather than finding it from the
+// environment, the shared config or the instance metadata
+func ClientRegion(region string) ClientOption {
+	return func(o *clientOptions) {
+		o.region = region
+	}
+}
+
+// ClientProfile loads the credentials and region of a profile from the shared config
+func ClientProfile(profile string) ClientOption {
+	return func(o *clientOptions) {
+		o.profile = profile
+	}
+}
+
+// ClientEndpoint sends the client's requests to endpoint, such as a VPC endpoint
+func ClientEndpoint(endpoint string) ClientOption {
+	return func(o *clientOptions) {
+		o.endpoint = endpoint
+	}
+}
+
+// ClientFIPS sends the client's requests to the FIPS endpoint of its region
+func ClientFIPS() ClientOption {
+	return func(o *clientOptions) {
+		o.fips = true
+	}
+}
+
+// ClientMaxRetries sets how many times the client retries a throttled or failed
+// request, rather than the SDK's default of 3
+func ClientMaxRetries(n int) ClientOption {
+	return func(o *clientOptions) {
+		o.maxRetries = n
+	}
+}
+
+// errNoRegion is returned by NewClient when no region is configured and the instance
+// metadata is unavailable
+var errNoRegion = errors.New("figgy: no region for the client, set AWS_REGION or use ClientRegion")
+
+// NewClient returns a Parameter Store client configured from the environment and the
+// shared config, as the AWS CLI is.  When neither sets a region, the region the
+// instance runs in is read from the instance metadata until ctx is done.
+//
+//	c, err := figgy.NewClient(ctx, figgy.ClientFIPS())
+//	err = figgy.Load(c, &cfg)
+func NewClient(ctx context.Context, opts ...ClientOption) (ssmiface.SSMAPI, error) {
+	o := clientOptions{maxRetries: -1}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            *o.sessionConfig(),
+		Profile:           o.profile,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, err
+	}
+	region := aws.StringValue(sess.Config.Region)
+	if region == "" {
+		region, err = instanceRegion(ctx, ec2metadata.New(sess))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ssm.New(sess, o.clientConfig(region)), nil
+}
+
+// sessionConfig returns the config of the session clients are created from
+func (o *clientOptions) sessionConfig() *aws.Config {
+	cfg := aws.NewConfig()
+	if o.region != "" {
+		cfg.WithRegion(o.region)
+	}
+	if o.maxRetries >= 0 {
+		cfg.WithMaxRetries(o.maxRetries)
+	}
+	return cfg
+}
+
+// clientConfig returns the config of the client for region
+func (o *clientOptions) clientConfig(region string) *aws.Config {
+	cfg := aws.NewConfig().WithRegion(region)
+	switch {
+	case o.endpoint != "":
+		cfg.WithEndpoint(o.endpoint)
+	case o.fips:
+		cfg.WithEndpoint("https://ssm-fips." + region + ".amazonaws.com")
+	}
+	return cfg
+}
+
+// instanceRegion returns the region of the instance from its metadata
+func instanceRegion(ctx context.Context, m *ec2metadata.EC2Metadata) (string, error) {
+	type result struct {
+		region string
+		err    error
+	}
+	found := make(chan result, 1)
+	go func() {
+		region, err := m.Region()
+		found <- result{region, err}
+	}()
+	select {
+	case r := <-found:
+		if r.err != nil {
+			return "", errNoRegion
+		}
+		return r.region, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
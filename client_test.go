@@ -0,0 +1,38 @@
+package figgy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientConfig(t *testing.T) {
+	o := clientOptions{maxRetries: -1}
+	cfg := o.sessionConfig()
+	assert.Nil(t, cfg.Region)
+	assert.Nil(t, cfg.MaxRetries)
+	assert.Nil(t, o.clientConfig("us-east-1").Endpoint)
+
+	for _, opt := range []ClientOption{ClientRegion("us-west-2"), ClientMaxRetries(8), ClientFIPS()} {
+		opt(&o)
+	}
+	cfg = o.sessionConfig()
+	assert.Equal(t, "us-west-2", aws.StringValue(cfg.Region))
+	assert.Equal(t, 8, aws.IntValue(cfg.MaxRetries))
+	assert.Equal(t, "https://ssm-fips.us-west-2.amazonaws.com", aws.StringValue(o.clientConfig("us-west-2").Endpoint))
+
+	// an endpoint given takes precedence
+	ClientEndpoint("https://vpce-0123.ssm.us-west-2.vpce.amazonaws.com")(&o)
+	assert.Equal(t, "https://vpce-0123.ssm.us-west-2.vpce.amazonaws.com", aws.StringValue(o.clientConfig("us-west-2").Endpoint))
+}
+
+func TestNewClient(t *testing.T) {
+	c, err := NewClient(context.Background(), ClientRegion("eu-west-1"), ClientFIPS())
+	if assert.NoError(t, err) {
+		assert.Equal(t, "https://ssm-fips.eu-west-1.amazonaws.com", c.(*ssm.SSM).Endpoint)
+		assert.Equal(t, "eu-west-1", aws.StringValue(c.(*ssm.SSM).Config.Region))
+	}
+}
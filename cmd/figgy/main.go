@@ -0,0 +1,289 @@
+// Command figgy inspects and manages the AWS Parameter Store parameters referenced by
+// figgy tags, without writing a Go program.
+//
+// Parameters are read from the ssm tags of a struct in a Go package directory, or from
+// a JSON manifest:
+//
+//	{"parameters": [{"key": "/myapp/{{.env}}/port", "value": "8080"}, {"key": "/myapp/{{.env}}/password", "options": ["decrypt"]}]}
+//
+// Usage:
+//
+//	figgy plan -dir ./config -type Config -data env=prod
+//	figgy get  -dir ./config -type Config -data env=prod [-show-secrets]
+//	figgy diff -manifest params.json -data env=dev
+//	figgy seed -manifest params.json -data env=dev [-overwrite] [-kms-key alias/app] [-dry-run]
+//	figgy seed -dir ./config -type Config -data env=dev -values values.json
+//
+// The diff and seed commands only consider parameters with a value, given in the manifest
+// or in a -values file of values keyed by parameter key, which is required with -type:
+//
+//	{"/myapp/{{.env}}/port": "8080", "/myapp/{{.env}}/password": "hunter2"}
+//
+// Diff exits with status 1 when differences are found.  Parameters are read and written
+// by their key as is, so the parts of a parameter using the chunks option aren't
+// reassembled.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// maxParameters is the maximum number of parameters that can be requested in a single call to GetParameters
+const maxParameters = 10
+
+const redacted = "[REDACTED]"
+
+// errDifferences is returned by diff when parameters differ
+var errDifferences = errors.New("differences found")
+
+type config struct {
+	dir         string
+	typeName    string
+	manifest    string
+	values      string
+	data        dataFlag
+	region      string
+	profile     string
+	showSecrets bool
+	overwrite   bool
+	kmsKey      string
+	dryRun      bool
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: figgy <plan|get|diff|seed> [flags]")
+	fmt.Fprintln(os.Stderr, "run 'figgy <command> -h' for the command's flags")
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	cmd := os.Args[1]
+	cfg := config{data: dataFlag{}}
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	fs.StringVar(&cfg.dir, "dir", ".", "directory of the Go package declaring the struct")
+	fs.StringVar(&cfg.typeName, "type", "", "name of the struct type")
+	fs.StringVar(&cfg.manifest, "manifest", "", "JSON manifest of parameters, used instead of -type")
+	fs.Var(cfg.data, "data", "template data for keys as name=value, may be repeated")
+	fs.StringVar(&cfg.region, "region", "", "AWS region, defaults to the shared config or environment")
+	fs.StringVar(&cfg.profile, "profile", "", "AWS shared config profile")
+	switch cmd {
+	case "get":
+		fs.BoolVar(&cfg.showSecrets, "show-secrets", false, "print the values of decrypt parameters")
+	case "diff":
+		fs.StringVar(&cfg.values, "values", "", "JSON file of values keyed by parameter key")
+	case "seed":
+		fs.StringVar(&cfg.values, "values", "", "JSON file of values keyed by parameter key")
+		fs.BoolVar(&cfg.overwrite, "overwrite", false, "replace parameters that already exist")
+		fs.StringVar(&cfg.kmsKey, "kms-key", "", "KMS key used to encrypt SecureString parameters")
+		fs.BoolVar(&cfg.dryRun, "dry-run", false, "print the parameters that would be written")
+	case "plan":
+	default:
+		usage()
+		os.Exit(2)
+	}
+	fs.Parse(os.Args[2:])
+
+	if err := run(cmd, cfg, os.Stdout); err != nil {
+		if err != errDifferences {
+			fmt.Fprintln(os.Stderr, "figgy:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(cmd string, cfg config, w io.Writer) error {
+	params, err := loadParameters(cfg)
+	if err != nil {
+		return err
+	}
+	if cmd == "plan" {
+		return plan(w, params)
+	}
+	if (cmd == "diff" || cmd == "seed") && cfg.manifest == "" && cfg.values == "" {
+		return fmt.Errorf("%s requires -values with -type, a struct has no values", cmd)
+	}
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Profile:           cfg.profile,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return err
+	}
+	c := ssm.New(sess)
+	if cfg.region != "" {
+		c = ssm.New(sess, aws.NewConfig().WithRegion(cfg.region))
+	}
+	switch cmd {
+	case "get":
+		return get(w, c, params, cfg.showSecrets)
+	case "diff":
+		return diff(w, c, params)
+	case "seed":
+		return seed(w, c, params, cfg)
+	}
+	return fmt.Errorf("unknown command %s", cmd)
+}
+
+func loadParameters(cfg config) ([]parameter, error) {
+	var params []parameter
+	var err error
+	switch {
+	case cfg.manifest != "":
+		params, err = readManifest(cfg.manifest)
+	case cfg.typeName != "":
+		params, err = parseStruct(cfg.dir, cfg.typeName)
+	default:
+		return nil, errors.New("either -type or -manifest is required")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if params, err = expand(params, cfg.data); err != nil {
+		return nil, err
+	}
+	if cfg.values == "" {
+		return params, nil
+	}
+	values, err := readValues(cfg.values, cfg.data)
+	if err != nil {
+		return nil, err
+	}
+	return mergeValues(params, values)
+}
+
+func plan(w io.Writer, params []parameter) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tFIELD\tTYPE\tOPTIONS")
+	for _, p := range params {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%v\n", p.Key, p.Field, p.Type, p.Options)
+	}
+	return tw.Flush()
+}
+
+// fetch the current parameters, keyed by name, decrypting the ones with the decrypt option
+func fetch(c ssmiface.SSMAPI, params []parameter) (map[string]*ssm.Parameter, error) {
+	remote := make(map[string]*ssm.Parameter, len(params))
+	for _, decrypt := range []bool{false, true} {
+		var names []string
+		seen := make(map[string]bool)
+		for _, p := range params {
+			if p.decrypt() == decrypt && !seen[p.Key] {
+				seen[p.Key] = true
+				names = append(names, p.Key)
+			}
+		}
+		for i := 0; i < len(names); i += maxParameters {
+			j := i + maxParameters
+			if j > len(names) {
+				j = len(names)
+			}
+			res, err := c.GetParameters(&ssm.GetParametersInput{
+				Names:          aws.StringSlice(names[i:j]),
+				WithDecryption: aws.Bool(decrypt),
+			})
+			if err != nil {
+				return nil, err
+			}
+			for _, p := range res.Parameters {
+				remote[aws.StringValue(p.Name)] = p
+			}
+		}
+	}
+	return remote, nil
+}
+
+func get(w io.Writer, c ssmiface.SSMAPI, params []parameter, showSecrets bool) error {
+	remote, err := fetch(c, params)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tVERSION\tVALUE")
+	for _, p := range params {
+		r, ok := remote[p.Key]
+		if !ok {
+			fmt.Fprintf(tw, "%s\t-\t(missing)\n", p.Key)
+			continue
+		}
+		v := aws.StringValue(r.Value)
+		if p.decrypt() && !showSecrets {
+			v = redacted
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\n", p.Key, aws.Int64Value(r.Version), v)
+	}
+	return tw.Flush()
+}
+
+func diff(w io.Writer, c ssmiface.SSMAPI, params []parameter) error {
+	remote, err := fetch(c, params)
+	if err != nil {
+		return err
+	}
+	var found bool
+	for _, p := range params {
+		if p.Value == nil {
+			continue
+		}
+		r, ok := remote[p.Key]
+		switch {
+		case !ok:
+			found = true
+			fmt.Fprintf(w, "+ %s\n", p.Key)
+		case aws.StringValue(r.Value) != *p.Value:
+			found = true
+			if p.decrypt() {
+				fmt.Fprintf(w, "~ %s (version %d)\n", p.Key, aws.Int64Value(r.Version))
+				continue
+			}
+			fmt.Fprintf(w, "~ %s (version %d): %q -> %q\n", p.Key, aws.Int64Value(r.Version), aws.StringValue(r.Value), *p.Value)
+		}
+	}
+	if found {
+		return errDifferences
+	}
+	return nil
+}
+
+func seed(w io.Writer, c ssmiface.SSMAPI, params []parameter, cfg config) error {
+	seen := make(map[string]bool)
+	for _, p := range params {
+		if p.Value == nil || seen[p.Key] {
+			continue
+		}
+		seen[p.Key] = true
+		in := &ssm.PutParameterInput{
+			Name:      aws.String(p.Key),
+			Value:     p.Value,
+			Type:      aws.String(ssm.ParameterTypeString),
+			Overwrite: aws.Bool(cfg.overwrite),
+		}
+		if p.decrypt() {
+			in.Type = aws.String(ssm.ParameterTypeSecureString)
+			if cfg.kmsKey != "" {
+				in.KeyId = aws.String(cfg.kmsKey)
+			}
+		}
+		if cfg.dryRun {
+			fmt.Fprintf(w, "would put %s (%s)\n", p.Key, aws.StringValue(in.Type))
+			continue
+		}
+		if _, err := c.PutParameter(in); err != nil {
+			return fmt.Errorf("failed to put %s: %v", p.Key, err)
+		}
+		fmt.Fprintf(w, "put %s (%s)\n", p.Key, aws.StringValue(in.Type))
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockSSMClient struct {
+	ssmiface.SSMAPI
+	Data map[string]string
+}
+
+func (c *mockSSMClient) GetParameters(i *ssm.GetParametersInput) (*ssm.GetParametersOutput, error) {
+	out := new(ssm.GetParametersOutput)
+	for _, n := range i.Names {
+		v, ok := c.Data[aws.StringValue(n)]
+		if !ok {
+			out.InvalidParameters = append(out.InvalidParameters, n)
+			continue
+		}
+		out.Parameters = append(out.Parameters, &ssm.Parameter{Name: n, Value: aws.String(v), Version: aws.Int64(1)})
+	}
+	return out, nil
+}
+
+func (c *mockSSMClient) PutParameter(i *ssm.PutParameterInput) (*ssm.PutParameterOutput, error) {
+	c.Data[aws.StringValue(i.Name)] = aws.StringValue(i.Value)
+	return &ssm.PutParameterOutput{Version: aws.Int64(1)}, nil
+}
+
+func TestParseStruct(t *testing.T) {
+	params, err := loadParameters(config{dir: "testdata/config", typeName: "Config", data: dataFlag{"env": "dev"}})
+	assert.NoError(t, err)
+	keys := make([]string, len(params))
+	for i, p := range params {
+		keys[i] = p.Key
+	}
+	assert.Equal(t, []string{
+		"/myapp/dev/server",
+		"/myapp/dev/port",
+		"/myapp/dev/password",
+		"/myapp/dev/timeout",
+		"/myapp/dev/db/host",
+		"/myapp/dev/cache/hosts",
+		"/myapp/dev/region",
+	}, keys)
+	assert.Equal(t, "Password", params[2].Field)
+	assert.True(t, params[2].decrypt())
+	assert.Equal(t, "time.Duration", params[3].Type)
+	assert.Equal(t, "Database.Host", params[4].Field)
+	assert.Equal(t, "Cache.Hosts", params[5].Field)
+	assert.Equal(t, "[]string", params[5].Type)
+	assert.Equal(t, "Base.Region", params[6].Field)
+}
+
+func TestLoadParametersErrors(t *testing.T) {
+	tests := map[string]config{
+		"no source":        {},
+		"missing type":     {dir: "testdata/config", typeName: "Nope"},
+		"missing manifest": {manifest: "testdata/nope.json"},
+		"missing data":     {manifest: "testdata/manifest.json", data: dataFlag{}},
+	}
+	for n, cfg := range tests {
+		_, err := loadParameters(cfg)
+		assert.Error(t, err, "test '%s' failed", n)
+	}
+}
+
+func TestGetDiffSeed(t *testing.T) {
+	params, err := loadParameters(config{manifest: "testdata/manifest.json", data: dataFlag{"env": "dev"}})
+	assert.NoError(t, err)
+	c := &mockSSMClient{Data: map[string]string{
+		"/myapp/dev/server":   "localhost",
+		"/myapp/dev/port":     "80",
+		"/myapp/dev/password": "secret",
+	}}
+
+	b := &bytes.Buffer{}
+	assert.NoError(t, get(b, c, params, false))
+	assert.Contains(t, b.String(), "[REDACTED]")
+	assert.NotContains(t, b.String(), "secret")
+	assert.Contains(t, b.String(), "(missing)")
+
+	b.Reset()
+	assert.Equal(t, errDifferences, diff(b, c, params))
+	assert.Equal(t, "~ /myapp/dev/port (version 1): \"80\" -> \"8080\"\n~ /myapp/dev/password (version 1)\n", b.String())
+
+	b.Reset()
+	assert.NoError(t, seed(b, c, params, config{overwrite: true, dryRun: true}))
+	assert.Equal(t, "80", c.Data["/myapp/dev/port"])
+
+	assert.NoError(t, seed(b, c, params, config{overwrite: true}))
+	assert.Equal(t, "8080", c.Data["/myapp/dev/port"])
+	assert.Equal(t, "hunter2", c.Data["/myapp/dev/password"])
+	_, ok := c.Data["/myapp/dev/timeout"]
+	assert.False(t, ok)
+
+	b.Reset()
+	assert.NoError(t, diff(b, c, params))
+	assert.Empty(t, b.String())
+}
+
+func TestDiffSeedStruct(t *testing.T) {
+	cfg := config{dir: "testdata/config", typeName: "Config", data: dataFlag{"env": "dev"}}
+	for _, cmd := range []string{"diff", "seed"} {
+		assert.EqualError(t, run(cmd, cfg, &bytes.Buffer{}), cmd+" requires -values with -type, a struct has no values")
+	}
+
+	cfg.values = "testdata/values.json"
+	params, err := loadParameters(cfg)
+	assert.NoError(t, err)
+	c := &mockSSMClient{Data: map[string]string{"/myapp/dev/port": "80"}}
+	b := &bytes.Buffer{}
+	assert.Equal(t, errDifferences, diff(b, c, params))
+	assert.Equal(t, "~ /myapp/dev/port (version 1): \"80\" -> \"8080\"\n+ /myapp/dev/password\n+ /myapp/dev/db/host\n", b.String())
+
+	b.Reset()
+	assert.NoError(t, seed(b, c, params, config{overwrite: true}))
+	assert.Equal(t, map[string]string{
+		"/myapp/dev/port":     "8080",
+		"/myapp/dev/password": "hunter2",
+		"/myapp/dev/db/host":  "db.local",
+	}, c.Data)
+
+	cfg.values = "testdata/unknown.json"
+	_, err = loadParameters(cfg)
+	assert.EqualError(t, err, "values for unknown parameters: /myapp/dev/nope")
+}
+
+func TestPlan(t *testing.T) {
+	params, err := loadParameters(config{manifest: "testdata/manifest.json", data: dataFlag{"env": "dev"}})
+	assert.NoError(t, err)
+	b := &bytes.Buffer{}
+	assert.NoError(t, plan(b, params))
+	assert.Contains(t, b.String(), "/myapp/dev/password")
+	assert.Contains(t, b.String(), "[decrypt]")
+}
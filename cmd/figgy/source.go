@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// parameter describes a single parameter referenced by a struct tag or a manifest
+type parameter struct {
+	// Key of the parameter, which may contain template actions such as {{.env}}
+	Key string `json:"key"`
+	// Field is the path of the struct field the parameter is loaded into
+	Field string `json:"field,omitempty"`
+	// Type is the Go type of the field
+	Type string `json:"type,omitempty"`
+	// Options are the tag options following the key
+	Options []string `json:"options,omitempty"`
+	// Value is used by diff and seed, parameters without one are skipped
+	Value *string `json:"value,omitempty"`
+}
+
+// manifest lists parameters without requiring a Go struct
+type manifest struct {
+	Parameters []parameter `json:"parameters"`
+}
+
+func (p parameter) hasOption(name string) bool {
+	for _, o := range p.Options {
+		if o == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (p parameter) decrypt() bool {
+	return p.hasOption("decrypt")
+}
+
+// readManifest reads a JSON manifest of parameters
+func readManifest(path string) ([]parameter, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m manifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %v", path, err)
+	}
+	for i, p := range m.Parameters {
+		if strings.TrimSpace(p.Key) == "" {
+			return nil, fmt.Errorf("invalid manifest %s: parameter %d has no key", path, i)
+		}
+	}
+	return m.Parameters, nil
+}
+
+// parseStruct finds the named struct type in the Go source files of a directory and
+// returns the parameters referenced by its ssm tags, following untagged fields whose
+// type is another struct declared in the same directory.
+func parseStruct(dir, name string) ([]parameter, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	structs := make(map[string]*ast.StructType)
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			if ts, ok := n.(*ast.TypeSpec); ok {
+				if st, ok := ts.Type.(*ast.StructType); ok {
+					structs[ts.Name.Name] = st
+				}
+			}
+			return true
+		})
+	}
+	st, ok := structs[name]
+	if !ok {
+		return nil, fmt.Errorf("struct %s not found in %s", name, dir)
+	}
+	return structParameters(st, structs, "", map[string]bool{name: true})
+}
+
+func structParameters(st *ast.StructType, structs map[string]*ast.StructType, parent string, visiting map[string]bool) ([]parameter, error) {
+	var params []parameter
+	for _, f := range st.Fields.List {
+		names := make([]string, 0, len(f.Names))
+		for _, n := range f.Names {
+			names = append(names, n.Name)
+		}
+		if len(names) == 0 {
+			// embedded fields are named by their type
+			names = append(names, strings.TrimPrefix(types.ExprString(f.Type), "*"))
+		}
+		var tag string
+		if f.Tag != nil {
+			t, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return nil, err
+			}
+			tag = reflect.StructTag(t).Get("ssm")
+		}
+		for _, n := range names {
+			if i := strings.LastIndex(n, "."); i >= 0 {
+				n = n[i+1:]
+			}
+			if !ast.IsExported(n) || tag == "-" {
+				continue
+			}
+			path := n
+			if parent != "" {
+				path = parent + "." + n
+			}
+			if tag != "" {
+				o := strings.Split(tag, ",")
+				p := parameter{
+					Key:   strings.TrimSpace(o[0]),
+					Field: path,
+					Type:  types.ExprString(f.Type),
+				}
+				if p.Key == "" {
+					return nil, fmt.Errorf("failed to parse tag [%s] for field %s", tag, path)
+				}
+				for _, x := range o[1:] {
+					p.Options = append(p.Options, strings.TrimSpace(x))
+				}
+				params = append(params, p)
+				continue
+			}
+			// walk down untagged fields of struct types declared alongside
+			tn := strings.TrimPrefix(types.ExprString(f.Type), "*")
+			nested, ok := structs[tn]
+			if !ok {
+				if s, ok := f.Type.(*ast.StructType); ok {
+					nested = s
+				}
+			}
+			if nested == nil || visiting[tn] {
+				continue
+			}
+			visiting[tn] = true
+			p, err := structParameters(nested, structs, path, visiting)
+			delete(visiting, tn)
+			if err != nil {
+				return nil, err
+			}
+			params = append(params, p...)
+		}
+	}
+	return params, nil
+}
+
+// expand executes the template actions in each key with the data.  Unlike
+// figgy.LoadWithParameters, which leaves a key it can't expand as is, a key referring
+// to data that wasn't given is an error, so a missing -data flag isn't mistaken for
+// a parameter named after the template.
+func expand(params []parameter, data map[string]string) ([]parameter, error) {
+	out := make([]parameter, len(params))
+	for i, p := range params {
+		tpl, err := template.New(p.Key).Option("missingkey=error").Parse(p.Key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %s: %v", p.Key, err)
+		}
+		b := &bytes.Buffer{}
+		if err := tpl.Execute(b, data); err != nil {
+			return nil, fmt.Errorf("failed to expand key %s: %v", p.Key, err)
+		}
+		p.Key = b.String()
+		out[i] = p
+	}
+	return out, nil
+}
+
+// readValues reads a JSON object of parameter values keyed by parameter key, expanding
+// the keys with the data
+func readValues(path string, data map[string]string) (map[string]string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]string
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, fmt.Errorf("invalid values %s: %v", path, err)
+	}
+	keys := make([]parameter, 0, len(raw))
+	for k := range raw {
+		keys = append(keys, parameter{Key: k})
+	}
+	expanded, err := expand(keys, data)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(raw))
+	for i, p := range expanded {
+		values[p.Key] = raw[keys[i].Key]
+	}
+	return values, nil
+}
+
+// mergeValues sets the value of each parameter to its value by key, which replaces a
+// value given in a manifest.  Values for keys without a parameter are an error.
+func mergeValues(params []parameter, values map[string]string) ([]parameter, error) {
+	used := make(map[string]bool, len(values))
+	out := make([]parameter, len(params))
+	for i, p := range params {
+		if v, ok := values[p.Key]; ok {
+			v := v
+			p.Value = &v
+			used[p.Key] = true
+		}
+		out[i] = p
+	}
+	var unknown []string
+	for k := range values {
+		if !used[k] {
+			unknown = append(unknown, k)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("values for unknown parameters: %s", strings.Join(unknown, ", "))
+	}
+	return out, nil
+}
+
+// dataFlag collects repeated -data name=value flags
+type dataFlag map[string]string
+
+func (d dataFlag) String() string {
+	l := make([]string, 0, len(d))
+	for k, v := range d {
+		l = append(l, k+"="+v)
+	}
+	sort.Strings(l)
+	return strings.Join(l, ",")
+}
+
+func (d dataFlag) Set(s string) error {
+	for _, kv := range strings.Split(s, ",") {
+		i := strings.Index(kv, "=")
+		if i <= 0 {
+			return fmt.Errorf("expected name=value, got %q", kv)
+		}
+		d[kv[:i]] = kv[i+1:]
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package config
+
+import "time"
+
+type Config struct {
+	Server   string        `ssm:"/myapp/{{.env}}/server"`
+	Port     int           `ssm:"/myapp/{{.env}}/port"`
+	Password string        `ssm:"/myapp/{{.env}}/password,decrypt"`
+	Timeout  time.Duration `ssm:"/myapp/{{.env}}/timeout"`
+	Ignored  string        `ssm:"-"`
+	Database Database
+	Cache    *Cache
+	Base
+	internal string
+}
+
+type Database struct {
+	Host string `ssm:"/myapp/{{.env}}/db/host"`
+	Name string
+}
+
+type Cache struct {
+	Hosts []string `ssm:"/myapp/{{.env}}/cache/hosts"`
+}
+
+type Base struct {
+	Region string `ssm:"/myapp/{{.env}}/region"`
+}
@@ -0,0 +1,383 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// pkgInfo holds the declarations of a package needed to generate loaders
+type pkgInfo struct {
+	name string
+	// types maps declared type names to their type expression
+	types map[string]ast.Expr
+	// unmarshalers are the declared types with an UnmarshalParameter method
+	unmarshalers map[string]bool
+}
+
+func parsePackage(dir string) (*pkgInfo, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	pkg := &pkgInfo{
+		types:        make(map[string]ast.Expr),
+		unmarshalers: make(map[string]bool),
+	}
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		pkg.name = f.Name.Name
+		for _, d := range f.Decls {
+			switch d := d.(type) {
+			case *ast.GenDecl:
+				for _, s := range d.Specs {
+					if ts, ok := s.(*ast.TypeSpec); ok {
+						pkg.types[ts.Name.Name] = ts.Type
+					}
+				}
+			case *ast.FuncDecl:
+				if d.Recv == nil || d.Name.Name != "UnmarshalParameter" || len(d.Recv.List) != 1 {
+					continue
+				}
+				recv := d.Recv.List[0].Type
+				if s, ok := recv.(*ast.StarExpr); ok {
+					recv = s.X
+				}
+				if id, ok := recv.(*ast.Ident); ok {
+					pkg.unmarshalers[id.Name] = true
+				}
+			}
+		}
+	}
+	if pkg.name == "" {
+		return nil, fmt.Errorf("no Go files in %s", dir)
+	}
+	return pkg, nil
+}
+
+// key is a parameter fetched by the generated code
+type key struct {
+	key     string
+	decrypt bool
+}
+
+type generator struct {
+	pkg     *pkgInfo
+	keys    []key
+	imports map[string]bool
+}
+
+// generate the source of a LoadParameters method for the named struct type
+func generate(pkg *pkgInfo, typeName string) ([]byte, error) {
+	st, ok := pkg.types[typeName].(*ast.StructType)
+	if !ok {
+		return nil, fmt.Errorf("struct %s not found in package %s", typeName, pkg.name)
+	}
+	g := &generator{
+		pkg:     pkg,
+		imports: make(map[string]bool),
+	}
+	body := &bytes.Buffer{}
+	if err := g.structFields(body, st, "v", map[string]bool{typeName: true}); err != nil {
+		return nil, err
+	}
+
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, "// Code generated by figgygen. DO NOT EDIT.\n\npackage %s\n\n", pkg.name)
+	g.imports[`figgy "github.com/Syncbak-Git/go-figgy"`] = true
+	g.imports[`"github.com/aws/aws-sdk-go/service/ssm/ssmiface"`] = true
+	var std, other []string
+	for i := range g.imports {
+		if strings.Contains(i, ".") {
+			other = append(other, i)
+		} else {
+			std = append(std, i)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+	fmt.Fprintln(b, "import (")
+	for _, i := range std {
+		fmt.Fprintln(b, i)
+	}
+	fmt.Fprintln(b)
+	for _, i := range other {
+		fmt.Fprintln(b, i)
+	}
+	fmt.Fprintln(b, ")")
+
+	fmt.Fprintf(b, "\n// LoadParameters loads the ssm tagged fields of %s from AWS Parameter Store without\n", typeName)
+	fmt.Fprintln(b, "// reflection, performing parameter substitution on keys with data.")
+	fmt.Fprintf(b, "func (v *%s) LoadParameters(c ssmiface.SSMAPI, data interface{}) error {\n", typeName)
+	if len(g.keys) == 0 {
+		fmt.Fprintln(b, "return nil\n}")
+		return format.Source(b.Bytes())
+	}
+	fmt.Fprintln(b, "keys := [...]string{")
+	for _, k := range g.keys {
+		fmt.Fprintf(b, "figgy.ExpandKey(%q, data),\n", k.key)
+	}
+	fmt.Fprintln(b, "}")
+	fmt.Fprintln(b, "values, err := figgy.FetchParameters(c, []figgy.ParameterRequest{")
+	for i, k := range g.keys {
+		if k.decrypt {
+			fmt.Fprintf(b, "{Key: keys[%d], Decrypt: true},\n", i)
+			continue
+		}
+		fmt.Fprintf(b, "{Key: keys[%d]},\n", i)
+	}
+	fmt.Fprintln(b, "})")
+	fmt.Fprintln(b, "if err != nil {\nreturn err\n}")
+	b.Write(body.Bytes())
+	fmt.Fprintln(b, "return nil\n}")
+	return format.Source(b.Bytes())
+}
+
+// structFields generates the assignments for the fields of a struct held by target
+func (g *generator) structFields(b *bytes.Buffer, st *ast.StructType, target string, visiting map[string]bool) error {
+	for _, f := range st.Fields.List {
+		names := make([]string, 0, len(f.Names))
+		for _, n := range f.Names {
+			names = append(names, n.Name)
+		}
+		embedded := len(names) == 0
+		if embedded {
+			// embedded fields are named by their type
+			n := strings.TrimPrefix(types.ExprString(f.Type), "*")
+			if i := strings.LastIndex(n, "."); i >= 0 {
+				n = n[i+1:]
+			}
+			names = append(names, n)
+		}
+		var tag string
+		if f.Tag != nil {
+			t, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return err
+			}
+			tag = reflect.StructTag(t).Get("ssm")
+		}
+		_, ptr := f.Type.(*ast.StarExpr)
+		for _, n := range names {
+			// the exported fields of an unexported struct embedded by value are
+			// promoted, as Load does, but a nil pointer to one can't be allocated
+			promoted := embedded && tag == "" && !ptr
+			if !ast.IsExported(n) && !promoted || tag == "-" {
+				continue
+			}
+			t := target + "." + n
+			if tag != "" {
+				if err := g.field(b, n, t, f.Type, tag); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := g.nested(b, t, f.Type, visiting); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// nested generates the assignments for an untagged field holding a struct, allocating
+// a nil pointer only when the struct has fields to load
+func (g *generator) nested(b *bytes.Buffer, target string, typ ast.Expr, visiting map[string]bool) error {
+	ptr := false
+	if s, ok := typ.(*ast.StarExpr); ok {
+		ptr = true
+		typ = s.X
+	}
+	var st *ast.StructType
+	var name string
+	switch t := typ.(type) {
+	case *ast.Ident:
+		name = t.Name
+		st, _ = g.pkg.types[name].(*ast.StructType)
+	case *ast.StructType:
+		if !ptr {
+			st = t
+		}
+	}
+	if st == nil || visiting[name] {
+		return nil
+	}
+	if name != "" {
+		visiting[name] = true
+		defer delete(visiting, name)
+	}
+	nb := &bytes.Buffer{}
+	if err := g.structFields(nb, st, target, visiting); err != nil {
+		return err
+	}
+	if nb.Len() == 0 {
+		return nil
+	}
+	if ptr {
+		fmt.Fprintf(b, "if %s == nil {\n%s = new(%s)\n}\n", target, target, name)
+	}
+	b.Write(nb.Bytes())
+	return nil
+}
+
+// field generates the assignment of a tagged field
+func (g *generator) field(b *bytes.Buffer, name, target string, typ ast.Expr, tag string) error {
+	o := strings.Split(tag, ",")
+	k := key{key: strings.TrimSpace(o[0])}
+	if k.key == "" {
+		return fmt.Errorf("failed to parse tag [%s] for field %s", tag, name)
+	}
+	var json bool
+	for _, option := range o[1:] {
+		switch option = strings.TrimSpace(option); option {
+		case "decrypt":
+			k.decrypt = true
+		case "json":
+			json = true
+		default:
+			if strings.HasPrefix(option, "refresh=") {
+				// only used by watchers
+				continue
+			}
+			return fmt.Errorf("field %s: the '%s' option is not supported by figgygen, use figgy.Load", name, option)
+		}
+	}
+	fmt.Fprintf(b, "// %s\n{\ns := values[keys[%d]]\n", name, len(g.keys))
+	g.keys = append(g.keys, k)
+	if json {
+		g.imports[`"encoding/json"`] = true
+		g.imports[`"fmt"`] = true
+		fmt.Fprintf(b, "if err := json.Unmarshal([]byte(s), &%s); err != nil {\n", target)
+		fmt.Fprintf(b, "return fmt.Errorf(\"json unmarshal error for field '%%s'\", %q)\n}\n", name)
+	} else if err := g.convert(b, name, target, typ, true); err != nil {
+		return err
+	}
+	fmt.Fprintln(b, "}")
+	return nil
+}
+
+// convert generates the conversion of the string s into target.  Pointers and slices
+// are only allowed at the top level, matching what figgy can set.
+func (g *generator) convert(b *bytes.Buffer, name, target string, typ ast.Expr, top bool) error {
+	switch t := typ.(type) {
+	case *ast.StarExpr:
+		if _, ok := t.X.(*ast.StarExpr); ok {
+			return g.unsupported(name, typ)
+		}
+		fmt.Fprintf(b, "var x %s\n", types.ExprString(t.X))
+		if err := g.convert(b, name, "x", t.X, false); err != nil {
+			return err
+		}
+		fmt.Fprintf(b, "%s = &x\n", target)
+		return nil
+	case *ast.ArrayType:
+		if t.Len != nil || !top {
+			return g.unsupported(name, typ)
+		}
+		g.imports[`"strings"`] = true
+		fmt.Fprintf(b, "parts := strings.Split(s, \",\")\n%s = make(%s, len(parts))\n", target, types.ExprString(t))
+		fmt.Fprintln(b, "for i, s := range parts {")
+		if err := g.convert(b, name, target+"[i]", t.Elt, false); err != nil {
+			return err
+		}
+		fmt.Fprintln(b, "}")
+		return nil
+	case *ast.Ident:
+		if g.pkg.unmarshalers[t.Name] {
+			fmt.Fprintf(b, "if err := %s.UnmarshalParameter(s); err != nil {\nreturn err\n}\n", target)
+			return nil
+		}
+		if underlying, ok := g.pkg.types[t.Name]; ok {
+			return g.scalar(b, name, target, t.Name, g.pkg.name+"."+t.Name, underlying)
+		}
+		return g.scalar(b, name, target, t.Name, t.Name, t)
+	case *ast.SelectorExpr:
+		return g.scalar(b, name, target, types.ExprString(t), types.ExprString(t), t)
+	}
+	return g.unsupported(name, typ)
+}
+
+// scalar generates the conversion to a basic type, or a type whose underlying type is
+// basic, assigning the result converted to the type named cast
+func (g *generator) scalar(b *bytes.Buffer, name, target, cast, typeName string, underlying ast.Expr) error {
+	convertErr := fmt.Sprintf("if err != nil {\nreturn &figgy.ConvertTypeError{Field: %q, Type: %q, Value: s}\n}\n", name, typeName)
+	if sel, ok := underlying.(*ast.SelectorExpr); ok {
+		if types.ExprString(sel) != "time.Duration" {
+			return g.unsupported(name, underlying)
+		}
+		if cast != "time.Duration" {
+			// only time.Duration itself accepts duration strings
+			g.imports[`"strconv"`] = true
+			fmt.Fprintf(b, "n, err := strconv.ParseInt(s, 10, 64)\n%s%s = %s(n)\n", convertErr, target, cast)
+			return nil
+		}
+		// bare integers are rejected as figgy.Load rejects them, rather than taken as
+		// nanoseconds
+		g.imports[`"time"`] = true
+		fmt.Fprintf(b, "d, err := time.ParseDuration(s)\n%s%s = d\n", convertErr, target)
+		return nil
+	}
+	id, ok := underlying.(*ast.Ident)
+	if !ok {
+		return g.unsupported(name, underlying)
+	}
+	switch id.Name {
+	case "string":
+		if cast == "string" {
+			fmt.Fprintf(b, "%s = s\n", target)
+			return nil
+		}
+		fmt.Fprintf(b, "%s = %s(s)\n", target, cast)
+		return nil
+	case "bool":
+		g.imports[`"strconv"`] = true
+		fmt.Fprintf(b, "n, err := strconv.ParseBool(s)\n%s%s = %s\n", convertErr, target, conversion(cast, "bool"))
+		return nil
+	}
+	bits := map[string]string{
+		"int": "0", "int8": "8", "int16": "16", "int32": "32", "int64": "64",
+		"uint": "0", "uint8": "8", "uint16": "16", "uint32": "32", "uint64": "64", "uintptr": "64",
+		"float32": "32", "float64": "64",
+	}[id.Name]
+	if bits == "" {
+		return g.unsupported(name, underlying)
+	}
+	g.imports[`"strconv"`] = true
+	parse, parsed := "strconv.ParseInt(s, 10, "+bits+")", "int64"
+	switch {
+	case strings.HasPrefix(id.Name, "uint"):
+		parse, parsed = "strconv.ParseUint(s, 10, "+bits+")", "uint64"
+	case strings.HasPrefix(id.Name, "float"):
+		parse, parsed = "strconv.ParseFloat(s, "+bits+")", "float64"
+	}
+	fmt.Fprintf(b, "n, err := %s\n%s%s = %s\n", parse, convertErr, target, conversion(cast, parsed))
+	return nil
+}
+
+// conversion returns the expression converting n, of type parsed, to the type cast
+func conversion(cast, parsed string) string {
+	if cast == parsed {
+		return "n"
+	}
+	return cast + "(n)"
+}
+
+func (g *generator) unsupported(name string, typ ast.Expr) error {
+	return fmt.Errorf("field %s: type %s is not supported by figgygen, use the json option or figgy.Load", name, types.ExprString(typ))
+}
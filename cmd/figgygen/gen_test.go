@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	pkg, err := parsePackage("testdata/config")
+	assert.NoError(t, err)
+	assert.Equal(t, "config", pkg.name)
+	assert.True(t, pkg.unmarshalers["Level"])
+
+	src, err := generate(pkg, "Config")
+	assert.NoError(t, err)
+	s := string(src)
+	assert.Contains(t, s, "// Code generated by figgygen. DO NOT EDIT.")
+	assert.Contains(t, s, "func (v *Config) LoadParameters(c ssmiface.SSMAPI, data interface{}) error {")
+	assert.Contains(t, s, `{Key: keys[2], Decrypt: true},`)
+	assert.Contains(t, s, `strconv.ParseUint(s, 10, 16)`)
+	assert.Contains(t, s, `v.Port = Port(n)`)
+	assert.Contains(t, s, `Type: "config.Port"`)
+	assert.Contains(t, s, `v.Password = &x`)
+	assert.Contains(t, s, "d, err := time.ParseDuration(s)\n\t\tif err != nil {")
+	assert.NotContains(t, s, "time.Duration(n)")
+	assert.Contains(t, s, `v.Hosts = make([]string, len(parts))`)
+	assert.Contains(t, s, `v.Level.UnmarshalParameter(s)`)
+	assert.Contains(t, s, `json.Unmarshal([]byte(s), &v.Settings)`)
+	assert.Contains(t, s, `v.Database.Host = s`)
+	assert.Contains(t, s, "if v.Cache == nil {\n\t\tv.Cache = new(Cache)\n\t}")
+	assert.Contains(t, s, "if v.Base == nil {\n\t\tv.Base = new(Base)\n\t}")
+	assert.Contains(t, s, `v.Base.Region = s`)
+	assert.Contains(t, s, `v.shared.Zone = s`)
+	assert.NotContains(t, s, "Unused")
+	assert.NotContains(t, s, "Ignored")
+	assert.NotContains(t, s, "internal")
+}
+
+func TestGenerateErrors(t *testing.T) {
+	pkg, err := parsePackage("testdata/config")
+	assert.NoError(t, err)
+	for _, n := range []string{"Nope", "Level", "Unsupported", "UnsupportedOption"} {
+		_, err := generate(pkg, n)
+		assert.Error(t, err, "test '%s' failed", n)
+	}
+	_, err = parsePackage("testdata/nope")
+	assert.Error(t, err)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// listParameters returns every parameter under path, without decryption
+func listParameters(c ssmiface.SSMAPI, path string) ([]*ssm.Parameter, error) {
+	var params []*ssm.Parameter
+	in := &ssm.GetParametersByPathInput{
+		Path:      aws.String(path),
+		Recursive: aws.Bool(true),
+	}
+	for {
+		out, err := c.GetParametersByPath(in)
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, out.Parameters...)
+		if aws.StringValue(out.NextToken) == "" {
+			return params, nil
+		}
+		in.NextToken = out.NextToken
+	}
+}
+
+// generateStruct emits a file declaring a struct with a tagged field for each parameter.
+// Field names are built from the parameter names relative to path, and field types are
+// inferred from the values.  SecureString parameters are strings with the decrypt option.
+func generateStruct(params []*ssm.Parameter, path, pkgName, typeName string) ([]byte, error) {
+	if len(params) == 0 {
+		return nil, fmt.Errorf("no parameters found under %s", path)
+	}
+	b := &bytes.Buffer{}
+	fields := &bytes.Buffer{}
+	names := make(map[string]int)
+	var usesTime, usesFiggy bool
+	for _, p := range params {
+		key := aws.StringValue(p.Name)
+		name := fieldName(strings.TrimPrefix(key, path))
+		if n := names[name]; n > 0 {
+			names[name]++
+			name = fmt.Sprintf("%s%d", name, n+1)
+		} else {
+			names[name] = 1
+		}
+		typ, option := inferType(p)
+		switch typ {
+		case "time.Duration":
+			usesTime = true
+		case "figgy.Raw":
+			usesFiggy = true
+		}
+		fmt.Fprintf(fields, "%s %s `ssm:\"%s%s\"`\n", name, typ, key, option)
+	}
+	fmt.Fprintf(b, "// Code generated by figgygen from the parameters under %s.\n\npackage %s\n\n", path, pkgName)
+	switch {
+	case usesTime && usesFiggy:
+		fmt.Fprintf(b, "import (\n\"time\"\n\nfiggy \"github.com/Syncbak-Git/go-figgy\"\n)\n\n")
+	case usesTime:
+		fmt.Fprintf(b, "import \"time\"\n\n")
+	case usesFiggy:
+		fmt.Fprintf(b, "import figgy \"github.com/Syncbak-Git/go-figgy\"\n\n")
+	}
+	fmt.Fprintf(b, "type %s struct {\n", typeName)
+	b.Write(fields.Bytes())
+	fmt.Fprintln(b, "}")
+	return format.Source(b.Bytes())
+}
+
+// inferType returns the Go type and tag options for a parameter
+func inferType(p *ssm.Parameter) (typ, option string) {
+	s := aws.StringValue(p.Value)
+	switch aws.StringValue(p.Type) {
+	case ssm.ParameterTypeSecureString:
+		return "string", ",decrypt"
+	case ssm.ParameterTypeStringList:
+		return "[]string", ""
+	}
+	if strings.EqualFold(s, "true") || strings.EqualFold(s, "false") {
+		return "bool", ""
+	}
+	if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return "int", ""
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return "float64", ""
+	}
+	if _, err := time.ParseDuration(s); err == nil {
+		return "time.Duration", ""
+	}
+	if t := strings.TrimSpace(s); (strings.HasPrefix(t, "{") || strings.HasPrefix(t, "[")) && json.Valid([]byte(t)) {
+		return "figgy.Raw", ",json"
+	}
+	return "string", ""
+}
+
+// fieldName converts a parameter name to an exported Go identifier, for example
+// /db/read-replica/host to DbReadReplicaHost
+func fieldName(name string) string {
+	b := &strings.Builder{}
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if b.Len() == 0 && unicode.IsDigit(r) {
+			b.WriteRune('P')
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	if b.Len() == 0 {
+		return "Value"
+	}
+	return b.String()
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+	"github.com/stretchr/testify/assert"
+)
+
+// pagingSSMClient returns one parameter per page of GetParametersByPath
+type pagingSSMClient struct {
+	ssmiface.SSMAPI
+	params []*ssm.Parameter
+}
+
+func (c *pagingSSMClient) GetParametersByPath(i *ssm.GetParametersByPathInput) (*ssm.GetParametersByPathOutput, error) {
+	n := 0
+	if i.NextToken != nil {
+		n = int(aws.StringValue(i.NextToken)[0] - '0')
+	}
+	out := &ssm.GetParametersByPathOutput{Parameters: c.params[n : n+1]}
+	if n+1 < len(c.params) {
+		out.NextToken = aws.String(string(rune('0' + n + 1)))
+	}
+	return out, nil
+}
+
+func param(name, typ, value string) *ssm.Parameter {
+	return &ssm.Parameter{Name: aws.String(name), Type: aws.String(typ), Value: aws.String(value)}
+}
+
+func TestGenerateStruct(t *testing.T) {
+	c := &pagingSSMClient{params: []*ssm.Parameter{
+		param("/myapp/prod/server", ssm.ParameterTypeString, "localhost"),
+		param("/myapp/prod/port", ssm.ParameterTypeString, "8080"),
+		param("/myapp/prod/db/password", ssm.ParameterTypeSecureString, "AQICAHh..."),
+		param("/myapp/prod/timeout", ssm.ParameterTypeString, "30s"),
+		param("/myapp/prod/debug", ssm.ParameterTypeString, "false"),
+		param("/myapp/prod/ratio", ssm.ParameterTypeString, "0.5"),
+		param("/myapp/prod/hosts", ssm.ParameterTypeStringList, "a,b"),
+		param("/myapp/prod/read-replica", ssm.ParameterTypeString, `{"host":"db"}`),
+		param("/myapp/prod/read_replica", ssm.ParameterTypeString, "db"),
+	}}
+	params, err := listParameters(c, "/myapp/prod")
+	assert.NoError(t, err)
+	assert.Len(t, params, 9)
+
+	src, err := generateStruct(params, "/myapp/prod", "config", "Config")
+	assert.NoError(t, err)
+	assert.Equal(t, `// Code generated by figgygen from the parameters under /myapp/prod.
+
+package config
+
+import (
+	"time"
+
+	figgy "github.com/Syncbak-Git/go-figgy"
+)
+
+type Config struct {
+	Server       string        `+"`ssm:\"/myapp/prod/server\"`"+`
+	Port         int           `+"`ssm:\"/myapp/prod/port\"`"+`
+	DbPassword   string        `+"`ssm:\"/myapp/prod/db/password,decrypt\"`"+`
+	Timeout      time.Duration `+"`ssm:\"/myapp/prod/timeout\"`"+`
+	Debug        bool          `+"`ssm:\"/myapp/prod/debug\"`"+`
+	Ratio        float64       `+"`ssm:\"/myapp/prod/ratio\"`"+`
+	Hosts        []string      `+"`ssm:\"/myapp/prod/hosts\"`"+`
+	ReadReplica  figgy.Raw     `+"`ssm:\"/myapp/prod/read-replica,json\"`"+`
+	ReadReplica2 string        `+"`ssm:\"/myapp/prod/read_replica\"`"+`
+}
+`, string(src))
+
+	_, err = generateStruct(nil, "/myapp/prod", "config", "Config")
+	assert.Error(t, err)
+}
+
+func TestFieldName(t *testing.T) {
+	tests := map[string]string{
+		"/db/read-replica/host": "DbReadReplicaHost",
+		"/2fa/key":              "P2faKey",
+		"api.url":               "ApiUrl",
+		"/":                     "Value",
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, fieldName(in), "test '%s' failed", in)
+	}
+}
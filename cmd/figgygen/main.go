@@ -0,0 +1,103 @@
+// Command figgygen generates code that loads a struct's ssm tagged fields without
+// reflection.  For each type it writes a LoadParameters method to <type>_figgy.go,
+// for use with go:generate:
+//
+//	//go:generate figgygen -type Config
+//
+//	cfg := Config{}
+//	err := cfg.LoadParameters(ssmClient, figgy.P{"env": "prod"})
+//
+// The generated code fetches parameters with figgy.FetchParameters and converts
+// values the same way as figgy.Load.  Field types and tag options that can't be
+// loaded without reflection are reported when generating, rather than when loading.
+// Supported are strings, bools, integers, floats, time.Duration, types in the same
+// package implementing figgy.Unmarshaler, pointers and slices of those, and any type
+// with the json option.
+//
+// With -path, figgygen instead lists the parameters under a path and writes a struct
+// with a tagged field for each, inferring field types from the values:
+//
+//	figgygen -path /myapp/prod -type Config -package config -out config.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma separated list of struct type names")
+	dir := flag.String("dir", ".", "directory of the Go package declaring the types")
+	path := flag.String("path", "", "generate a struct named by -type from the parameters under this path")
+	pkgName := flag.String("package", "config", "package name of the struct generated with -path")
+	out := flag.String("out", "", "file to write the struct generated with -path, defaults to stdout")
+	region := flag.String("region", "", "AWS region, defaults to the shared config or environment")
+	profile := flag.String("profile", "", "AWS shared config profile")
+	flag.Parse()
+	if *typeNames == "" {
+		fmt.Fprintln(os.Stderr, "usage: figgygen -type T[,T...] [-dir dir]")
+		fmt.Fprintln(os.Stderr, "       figgygen -path path -type T [-package name] [-out file]")
+		os.Exit(2)
+	}
+	if *path != "" {
+		if err := fromPath(*path, *pkgName, *typeNames, *out, *region, *profile); err != nil {
+			fmt.Fprintln(os.Stderr, "figgygen:", err)
+			os.Exit(1)
+		}
+		return
+	}
+	pkg, err := parsePackage(*dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "figgygen:", err)
+		os.Exit(1)
+	}
+	for _, t := range strings.Split(*typeNames, ",") {
+		t = strings.TrimSpace(t)
+		src, err := generate(pkg, t)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "figgygen:", err)
+			os.Exit(1)
+		}
+		out := filepath.Join(*dir, strings.ToLower(t)+"_figgy.go")
+		if err := ioutil.WriteFile(out, src, 0644); err != nil {
+			fmt.Fprintln(os.Stderr, "figgygen:", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// fromPath generates a struct from the parameters under path
+func fromPath(path, pkgName, typeName, out, region, profile string) error {
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Profile:           profile,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return err
+	}
+	c := ssm.New(sess)
+	if region != "" {
+		c = ssm.New(sess, aws.NewConfig().WithRegion(region))
+	}
+	params, err := listParameters(c, path)
+	if err != nil {
+		return err
+	}
+	src, err := generateStruct(params, path, pkgName, typeName)
+	if err != nil {
+		return err
+	}
+	if out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return ioutil.WriteFile(out, src, 0644)
+}
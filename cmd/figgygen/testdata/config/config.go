@@ -0,0 +1,62 @@
+package config
+
+import "time"
+
+type Level string
+
+func (l *Level) UnmarshalParameter(s string) error {
+	*l = Level("level-" + s)
+	return nil
+}
+
+type Port uint16
+
+type Config struct {
+	Server   string        `ssm:"/myapp/{{.env}}/server"`
+	Port     Port          `ssm:"/myapp/{{.env}}/port"`
+	Password *string       `ssm:"/myapp/{{.env}}/password,decrypt"`
+	Timeout  time.Duration `ssm:"/myapp/{{.env}}/timeout"`
+	Debug    bool          `ssm:"/myapp/{{.env}}/debug"`
+	Ratio    float32       `ssm:"/myapp/{{.env}}/ratio"`
+	Hosts    []string      `ssm:"/myapp/{{.env}}/hosts"`
+	Retries  []*int        `ssm:"/myapp/{{.env}}/retries"`
+	Level    Level         `ssm:"/myapp/{{.env}}/level"`
+	Settings struct {
+		A int
+	} `ssm:"/myapp/{{.env}}/settings,json"`
+	Ignored  string `ssm:"-"`
+	Database Database
+	Cache    *Cache
+	Unused   *Unused
+	internal string
+	*Base
+	shared
+}
+
+type Base struct {
+	Region string `ssm:"/myapp/{{.env}}/region"`
+}
+
+type shared struct {
+	Zone string `ssm:"/myapp/{{.env}}/zone"`
+}
+
+type Database struct {
+	Host string `ssm:"/myapp/{{.env}}/db/host"`
+}
+
+type Cache struct {
+	Size int64 `ssm:"/myapp/{{.env}}/cache/size"`
+}
+
+type Unused struct {
+	Name string
+}
+
+type Unsupported struct {
+	Map map[string]string `ssm:"/myapp/map"`
+}
+
+type UnsupportedOption struct {
+	Host string `ssm:"/myapp/config,json,path=$.host"`
+}
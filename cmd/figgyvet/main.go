@@ -0,0 +1,20 @@
+// Command figgyvet checks the ssm tags of the struct types in Go packages with the
+// figgyvet Analyzer, reporting problems that figgy would otherwise only find when
+// loading, or silently ignore.  It runs on its own, exiting with status 1 if any are
+// found:
+//
+//	figgyvet ./config/...
+//
+// or as a vet tool in CI, alongside go vet's own checks:
+//
+//	go vet -vettool=$(which figgyvet) ./...
+package main
+
+import (
+	"github.com/Syncbak-Git/go-figgy/figgyvet"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(figgyvet.Analyzer)
+}
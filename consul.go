@@ -0,0 +1,73 @@
+package figgy
+
+import (
+	"context"
+	"strings"
+)
+
+// ConsulKVAPI is the part of the Consul KV client used by ConsulKV.  The Consul API
+// isn't a dependency of figgy, so its client is adapted to this interface.
+type ConsulKVAPI interface {
+	// Get returns the pair called key, or nil when there's no such key
+	Get(ctx context.Context, key string) (*ConsulKVPair, error)
+	// List returns the pairs whose keys begin with prefix and the X-Consul-Index of the
+	// result.  With a waitIndex it's a blocking query, which waits until the index
+	// exceeds waitIndex or the query's wait time passes.
+	List(ctx context.Context, prefix string, waitIndex uint64) ([]*ConsulKVPair, uint64, error)
+}
+
+// ConsulKVPair is a key of Consul KV
+type ConsulKVPair struct {
+	Key   string
+	Value []byte
+	// ModifyIndex is the index of the last change to the key
+	ModifyIndex uint64
+}
+
+// ConsulKV is a KV of the keys of Consul KV.  Consul keys have no leading slash, so
+// it's trimmed and the tag `ssm:"/myapp/db/host"` loads the key myapp/db/host.
+// Versions are Consul indexes, and Watch is a blocking query on the keys under the
+// prefix.
+//
+//	kv := &figgy.ConsulKV{API: client}
+//	w, err := figgy.Watch(figgy.NewKVClient(kv), &cfg, nil, &figgy.KVNotifier{KV: kv, Prefix: "/myapp/"})
+type ConsulKV struct {
+	API ConsulKVAPI
+}
+
+// Get returns the value of the key and its ModifyIndex
+func (c *ConsulKV) Get(ctx context.Context, key string) (string, int64, bool, error) {
+	p, err := c.API.Get(ctx, strings.TrimPrefix(key, "/"))
+	if err != nil || p == nil {
+		return "", 0, false, err
+	}
+	return string(p.Value), int64(p.ModifyIndex), true, nil
+}
+
+// Watch makes a blocking query for the keys beginning with prefix, returning the keys
+// modified after the index version.  When the query times out without a change,
+// version is returned as the current index.  Deleted keys aren't listed, so a change
+// without a modified key returns a nil slice.
+func (c *ConsulKV) Watch(ctx context.Context, prefix string, version int64) ([]string, int64, error) {
+	pairs, index, err := c.API.List(ctx, strings.TrimPrefix(prefix, "/"), uint64(version))
+	if err != nil {
+		return nil, 0, err
+	}
+	if version == 0 || int64(index) <= version {
+		// the index only goes backwards when Consul's state is reset, in which case
+		// any key may have changed
+		return nil, int64(index), nil
+	}
+	var keys []string
+	for _, p := range pairs {
+		if int64(p.ModifyIndex) <= version {
+			continue
+		}
+		key := p.Key
+		if strings.HasPrefix(prefix, "/") {
+			key = "/" + key
+		}
+		keys = append(keys, key)
+	}
+	return keys, int64(index), nil
+}
@@ -0,0 +1,126 @@
+package figgy
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockConsul is a Consul KV whose blocking queries wait up to wait for a change
+type mockConsul struct {
+	mu      sync.Mutex
+	pairs   map[string]*ConsulKVPair
+	index   uint64
+	changed chan struct{}
+	wait    time.Duration
+}
+
+func newMockConsul(values map[string]string) *mockConsul {
+	m := &mockConsul{pairs: make(map[string]*ConsulKVPair), changed: make(chan struct{}), wait: time.Second}
+	for k, v := range values {
+		m.set(k, v)
+	}
+	return m
+}
+
+func (m *mockConsul) Get(ctx context.Context, key string) (*ConsulKVPair, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.pairs[key], nil
+}
+
+func (m *mockConsul) List(ctx context.Context, prefix string, waitIndex uint64) ([]*ConsulKVPair, uint64, error) {
+	m.mu.Lock()
+	changed := m.changed
+	if waitIndex != 0 && m.index <= waitIndex {
+		m.mu.Unlock()
+		select {
+		case <-changed:
+		case <-time.After(m.wait):
+		case <-ctx.Done():
+			return nil, 0, ctx.Err()
+		}
+		m.mu.Lock()
+	}
+	defer m.mu.Unlock()
+	var pairs []*ConsulKVPair
+	for k, p := range m.pairs {
+		if strings.HasPrefix(k, prefix) {
+			pairs = append(pairs, p)
+		}
+	}
+	return pairs, m.index, nil
+}
+
+// set stores value in key, or deletes it if value is empty
+func (m *mockConsul) set(key, value string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.index++
+	if value == "" {
+		delete(m.pairs, key)
+	} else {
+		m.pairs[key] = &ConsulKVPair{Key: key, Value: []byte(value), ModifyIndex: m.index}
+	}
+	close(m.changed)
+	m.changed = make(chan struct{})
+}
+
+func TestConsulKV(t *testing.T) {
+	m := newMockConsul(map[string]string{"app/host": "a", "app/password": "p", "app/port": "1"})
+	kv := &ConsulKV{API: m}
+	var cfg WatchConfig
+	n := &KVNotifier{KV: kv, Prefix: "/app/"}
+	w, err := Watch(NewKVClient(kv), &cfg, nil, n)
+	assert.NoError(t, err)
+	defer w.Stop()
+	w.RLock()
+	assert.Equal(t, "a", cfg.Host)
+	assert.Equal(t, 1, cfg.Nested.Port)
+	w.RUnlock()
+
+	for n.current() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	m.set("app/port", "2")
+	assert.True(t, waitChange(t, w))
+	w.RLock()
+	assert.Equal(t, 2, cfg.Nested.Port)
+	w.RUnlock()
+
+	m.set("app/port", "")
+	assert.EqualError(t, Load(NewKVClient(kv), &cfg), "invalid parameters: /app/port")
+}
+
+func TestConsulKVWatch(t *testing.T) {
+	m := newMockConsul(map[string]string{"app/host": "a", "other": "x"})
+	m.wait = 10 * time.Millisecond
+	kv := &ConsulKV{API: m}
+	ctx := context.Background()
+	_, index, err := kv.Watch(ctx, "/app/", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), index)
+
+	// a blocking query that times out returns the same index
+	keys, current, err := kv.Watch(ctx, "/app/", index)
+	assert.NoError(t, err)
+	assert.Nil(t, keys)
+	assert.Equal(t, index, current)
+
+	m.set("app/host", "b")
+	keys, current, err = kv.Watch(ctx, "/app/", index)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/app/host"}, keys)
+	assert.Equal(t, int64(3), current)
+
+	// deleted keys aren't listed, so any key may have changed
+	m.set("app/host", "")
+	keys, current, err = kv.Watch(ctx, "/app/", current)
+	assert.NoError(t, err)
+	assert.Nil(t, keys)
+	assert.Equal(t, int64(4), current)
+}
@@ -0,0 +1,24 @@
+package figgy
+
+import (
+	"context"
+)
+
+// DataFunc returns the template data for the tags of a struct.  Template data given to
+// LoadWithParameters or Watch can be a DataFunc, or a func(context.Context) P, to
+// expand keys with values that change over time, such as the active deployment color.
+// Loads call it with a background context, and watchers call it with a context that's
+// done once the watcher is stopped, before each reload.  When the keys it expands to
+// change, the watcher reloads every field from the new keys.
+type DataFunc func(ctx context.Context) P
+
+// resolveData returns the template data given by data, calling it when it's a function
+func resolveData(ctx context.Context, data interface{}) interface{} {
+	switch d := data.(type) {
+	case DataFunc:
+		return d(ctx)
+	case func(context.Context) P:
+		return d(ctx)
+	}
+	return data
+}
@@ -0,0 +1,86 @@
+package figgy
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type colorConfig struct {
+	Host string `ssm:"/app/{{.color}}/host"`
+}
+
+func TestLoadDataFunc(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/blue/host": "b"})
+	var cfg colorConfig
+	data := DataFunc(func(ctx context.Context) P { return P{"color": "blue"} })
+	assert.NoError(t, LoadWithParameters(m, &cfg, data))
+	assert.Equal(t, "b", cfg.Host)
+}
+
+func TestWatchDataFunc(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/blue/host": "b", "/app/green/host": "g"})
+	var color atomic.Value
+	color.Store("blue")
+	data := func(ctx context.Context) P { return P{"color": color.Load().(string)} }
+	n := make(chanNotifier)
+	var cfg colorConfig
+	w, err := Watch(m, &cfg, data, n)
+	assert.NoError(t, err)
+	defer w.Stop()
+	assert.Equal(t, "b", cfg.Host)
+
+	color.Store("green")
+	assert.NoError(t, w.Reload(context.Background()))
+	assert.True(t, waitChange(t, w))
+	w.RLock()
+	assert.Equal(t, "g", cfg.Host)
+	w.RUnlock()
+
+	// a notification for the new key reloads from it
+	setParameter(m, "/app/green/host", "g2")
+	n <- []string{"/app/green/host"}
+	assert.True(t, waitChange(t, w))
+	w.RLock()
+	assert.Equal(t, "g2", cfg.Host)
+	w.RUnlock()
+
+	// a notification for any key reloads from the new keys once they change
+	color.Store("blue")
+	n <- []string{"/app/green/host"}
+	assert.True(t, waitChange(t, w))
+	w.RLock()
+	assert.Equal(t, "b", cfg.Host)
+	w.RUnlock()
+}
+
+func TestWatcherSetData(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/blue/host": "b", "/app/green/host": "g"})
+	n := make(chanNotifier)
+	var cfg colorConfig
+	w, err := Watch(m, &cfg, P{"color": "blue"}, n)
+	assert.NoError(t, err)
+	defer w.Stop()
+
+	w.SetData(P{"color": "green"})
+	n <- []string{"/app/blue/host"}
+	assert.True(t, waitChange(t, w))
+	w.RLock()
+	assert.Equal(t, "g", cfg.Host)
+	w.RUnlock()
+
+	// only the new keys are watched
+	setParameter(m, "/app/blue/host", "b2")
+	n <- []string{"/app/blue/host"}
+	n.settle()
+	assert.False(t, waitChange(t, w))
+
+	w.SetData(P{"color": "blue"})
+	assert.NoError(t, w.Reload(context.Background()))
+	assert.True(t, waitChange(t, w))
+	w.RLock()
+	assert.Equal(t, "b2", cfg.Host)
+	w.RUnlock()
+}
@@ -0,0 +1,102 @@
+package figgy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// decryptDenied is what a load does when decrypting parameters is denied
+type decryptDenied int
+
+const (
+	decryptDeniedFail decryptDenied = iota
+	decryptDeniedRetry
+	decryptDeniedReport
+)
+
+// WithDecryptFallback requests the parameters of fields with the decrypt option again
+// without decryption when decrypting them is denied, such as without kms:Decrypt
+// permission for their key, so parameters that aren't SecureStrings still load.  The
+// parameters are requested one at a time to find those that can't be decrypted.
+func WithDecryptFallback() Option {
+	return func(o *options) {
+		o.decryptDenied = decryptDeniedRetry
+	}
+}
+
+// WithDecryptDeniedError loads the fields whose parameters can be decrypted when
+// decrypting others is denied, returning a DecryptDeniedError naming the fields that
+// weren't loaded rather than failing the load at the first denied request.  The
+// parameters are requested one at a time to find those that can't be decrypted.
+func WithDecryptDeniedError() Option {
+	return func(o *options) {
+		o.decryptDenied = decryptDeniedReport
+	}
+}
+
+// DecryptDeniedError is returned by a load with WithDecryptDeniedError when decrypting
+// the parameters of some fields is denied
+type DecryptDeniedError struct {
+	// Fields that weren't loaded
+	Fields []string
+	// Keys of the parameters that couldn't be decrypted
+	Keys []string
+	// Err is the error of the first denied request
+	Err error
+}
+
+func (e *DecryptDeniedError) Error() string {
+	return fmt.Sprintf("decryption denied, fields not loaded: %s: %v", strings.Join(e.Fields, ", "), e.Err)
+}
+
+// isDecryptDenied reports whether err denies decrypting a parameter with KMS
+func isDecryptDenied(err error) bool {
+	var aerr awserr.Error
+	return errors.As(err, &aerr) && aerr.Code() == "AccessDeniedException" && strings.Contains(aerr.Message(), "kms:Decrypt")
+}
+
+// loadDecrypted loads the fields with the decrypt option, handling denied decryption
+// as the options ask
+func loadDecrypted(c ssmiface.SSMAPI, f []*field, o *options) error {
+	err := loadGroup(c, f, true, o)
+	if o.decryptDenied == decryptDeniedFail || !isDecryptDenied(err) {
+		return err
+	}
+	o.logger.Debug("figgy: decryption denied, requesting parameters one at a time", "error", err)
+	var denied *DecryptDeniedError
+	var missing *InvalidParametersError
+	err = batchIterateFields(groupFields(f), 1, func(g []*field) error {
+		err := loadParameters(c, g, true, o)
+		if !isDecryptDenied(err) {
+			missing, err = collectMissing(missing, err)
+			return err
+		}
+		o.logger.Debug("figgy: decryption denied", "key", g[0].key)
+		if o.decryptDenied == decryptDeniedRetry {
+			missing, err = collectMissing(missing, loadParameters(c, g, false, o))
+			return err
+		}
+		if denied == nil {
+			denied = &DecryptDeniedError{Err: err}
+		}
+		denied.Keys = append(denied.Keys, g[0].key)
+		for _, x := range g {
+			denied.Fields = append(denied.Fields, x.field.Name)
+		}
+		return nil
+	})
+	switch {
+	case err != nil:
+		return err
+	case missing != nil:
+		// missing parameters fail the load, where denied ones only leave fields unloaded
+		return missing
+	case denied != nil:
+		return denied
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package figgy
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/stretchr/testify/assert"
+)
+
+// deniedSSMClient denies decrypting the parameters in its set
+type deniedSSMClient struct {
+	*MockSSMClient
+	denied map[string]bool
+}
+
+func (c *deniedSSMClient) GetParameters(i *ssm.GetParametersInput) (*ssm.GetParametersOutput, error) {
+	if aws.BoolValue(i.WithDecryption) {
+		for _, name := range aws.StringValueSlice(i.Names) {
+			if c.denied[name] {
+				return nil, awserr.New("AccessDeniedException", "User: arn:aws:iam::123456789012:user/app is not authorized to perform: kms:Decrypt", nil)
+			}
+		}
+	}
+	return c.MockSSMClient.GetParameters(i)
+}
+
+type decryptConfig struct {
+	Token    string `ssm:"/app/token,decrypt"`
+	Password string `ssm:"/app/password,decrypt"`
+	Host     string `ssm:"/app/host"`
+}
+
+func newDeniedClient() *deniedSSMClient {
+	return &deniedSSMClient{
+		MockSSMClient: NewMockSSMClientWith(map[string]string{"/app/token": "t", "/app/password": "p", "/app/host": "h"}),
+		denied:        map[string]bool{"/app/password": true},
+	}
+}
+
+func TestDecryptDenied(t *testing.T) {
+	var cfg decryptConfig
+	err := Load(newDeniedClient(), &cfg)
+	assert.True(t, isDecryptDenied(err))
+	assert.Equal(t, "", cfg.Token)
+}
+
+func TestWithDecryptFallback(t *testing.T) {
+	var cfg decryptConfig
+	assert.NoError(t, Load(newDeniedClient(), &cfg, WithDecryptFallback()))
+	assert.Equal(t, decryptConfig{Token: "t", Password: "p", Host: "h"}, cfg)
+}
+
+func TestWithDecryptDeniedError(t *testing.T) {
+	var cfg decryptConfig
+	err := Load(newDeniedClient(), &cfg, WithDecryptDeniedError())
+	e, ok := err.(*DecryptDeniedError)
+	if assert.True(t, ok, "%v", err) {
+		assert.Equal(t, []string{"Password"}, e.Fields)
+		assert.Equal(t, []string{"/app/password"}, e.Keys)
+		assert.True(t, isDecryptDenied(e.Err))
+	}
+	assert.Equal(t, decryptConfig{Token: "t", Host: "h"}, cfg)
+
+	// other errors still fail the load
+	c := newDeniedClient()
+	delete(c.Data, "/app/token")
+	assert.IsType(t, &InvalidParametersError{}, Load(c, &cfg, WithDecryptDeniedError()))
+}
+
+func TestDecryptFallbackMissing(t *testing.T) {
+	var cfg struct {
+		decryptConfig
+		Key  string `ssm:"/app/key,decrypt"`
+		Cert string `ssm:"/app/cert,decrypt"`
+	}
+	for _, opt := range []Option{WithDecryptFallback(), WithDecryptDeniedError()} {
+		err := Load(newDeniedClient(), &cfg, opt)
+		if e, ok := err.(*InvalidParametersError); assert.True(t, ok, "%v", err) {
+			// each parameter is requested on its own, and all of the missing ones named
+			assert.Equal(t, []string{"/app/key", "/app/cert"}, e.Names)
+		}
+		assert.Equal(t, "t", cfg.Token)
+	}
+	assert.Equal(t, "p", cfg.Password)
+}
@@ -0,0 +1,129 @@
+package figgy
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// WithDryRun makes Delete report the parameters it would delete without deleting them
+func WithDryRun() Option {
+	return func(o *options) {
+		o.dryRun = true
+	}
+}
+
+// Delete removes the parameters referenced by a struct's tags from AWS Parameter Store,
+// including every part of fields with the chunks option, and returns the keys of the
+// parameters that were deleted.  Parameters that don't exist are ignored.
+//
+// With the WithDryRun option nothing is deleted and the keys of the existing parameters
+// that would be deleted are returned.
+func Delete(c ssmiface.SSMAPI, v interface{}, opts ...Option) ([]string, error) {
+	return DeleteWithParameters(c, v, nil, opts...)
+}
+
+// DeleteWithParameters removes the parameters referenced by a struct's tags, performing
+// parameter substitution on field tags the same way as LoadWithParameters.
+func DeleteWithParameters(c ssmiface.SSMAPI, v interface{}, data interface{}, opts ...Option) ([]string, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return nil, &InvalidTypeError{Type: reflect.TypeOf(v)}
+	}
+	f, err := inspect(rv.Elem(), rv.Elem().Type(), data, "")
+	f = parameterFields(f)
+	if err != nil {
+		return nil, err
+	}
+	o := newOptions(opts)
+	o.normalizeKeys(f)
+	var keys, parts []string
+	for _, x := range f {
+		if !x.chunks {
+			keys = append(keys, x.key)
+			continue
+		}
+		p, err := chunkNames(c, x.key)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, p...)
+	}
+	names, err := existingNames(c, keys)
+	if err != nil {
+		return nil, err
+	}
+	names = append(names, parts...)
+	if o.dryRun {
+		return names, nil
+	}
+	var deleted []string
+	for i := 0; i < len(names); i += maxParameters {
+		j := i + maxParameters
+		if j > len(names) {
+			j = len(names)
+		}
+		res, err := c.DeleteParameters(&ssm.DeleteParametersInput{
+			Names: aws.StringSlice(names[i:j]),
+		})
+		if err != nil {
+			return deleted, fmt.Errorf("failed to delete parameters: %v", err)
+		}
+		deleted = append(deleted, aws.StringValueSlice(res.DeletedParameters)...)
+	}
+	return deleted, nil
+}
+
+// existingNames returns the distinct names that exist in Parameter Store, in order
+func existingNames(c ssmiface.SSMAPI, names []string) ([]string, error) {
+	var unique []string
+	seen := make(map[string]bool, len(names))
+	for _, n := range names {
+		if !seen[n] {
+			seen[n] = true
+			unique = append(unique, n)
+		}
+	}
+	var existing []string
+	for i := 0; i < len(unique); i += maxParameters {
+		j := i + maxParameters
+		if j > len(unique) {
+			j = len(unique)
+		}
+		res, err := c.GetParameters(&ssm.GetParametersInput{
+			Names: aws.StringSlice(unique[i:j]),
+		})
+		if err != nil {
+			return nil, err
+		}
+		idx := indexParameters(res.Parameters)
+		for _, n := range unique[i:j] {
+			if _, ok := idx[n]; ok {
+				existing = append(existing, n)
+			}
+		}
+	}
+	return existing, nil
+}
+
+// chunkNames returns the names of the existing parts of a chunked parameter
+func chunkNames(c ssmiface.SSMAPI, key string) ([]string, error) {
+	var names []string
+	for n := 0; ; n += maxParameters {
+		batch := make([]string, maxParameters)
+		for i := range batch {
+			batch[i] = chunkKey(key, n+i)
+		}
+		existing, err := existingNames(c, batch)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, existing...)
+		if len(existing) < len(batch) {
+			return names, nil
+		}
+	}
+}
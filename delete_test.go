@@ -0,0 +1,47 @@
+package figgy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDelete(t *testing.T) {
+	parts := make(map[string]string)
+	for i := 0; i < maxParameters+2; i++ {
+		parts[chunkKey("/dev/chunked", i)] = "x"
+	}
+	m := NewMockSSMClientWith(parts)
+	var c struct {
+		String  string `ssm:"string"`
+		Again   string `ssm:"string"`
+		Secret  string `ssm:"pstring,decrypt"`
+		Chunked string `ssm:"/{{.env}}/chunked,chunks"`
+		Missing string `ssm:"/no/such/param"`
+		PNested *Nested
+	}
+
+	names, err := DeleteWithParameters(m, &c, P{"env": "dev"}, WithDryRun())
+	assert.NoError(t, err)
+	assert.Len(t, names, maxParameters+4)
+	assert.Equal(t, []string{"string", "pstring"}, names[:2])
+	assert.Contains(t, m.Data, "string")
+	assert.Nil(t, c.PNested)
+
+	deleted, err := DeleteWithParameters(m, &c, P{"env": "dev"})
+	assert.NoError(t, err)
+	assert.Equal(t, names, deleted)
+	for _, n := range names {
+		_, ok := m.Data[n]
+		assert.False(t, ok, "parameter %s was not deleted", n)
+	}
+
+	deleted, err = DeleteWithParameters(m, &c, P{"env": "dev"})
+	assert.NoError(t, err)
+	assert.Empty(t, deleted)
+}
+
+func TestDeleteErrors(t *testing.T) {
+	_, err := Delete(NewMockSSMClient(), struct{}{})
+	assert.Error(t, err)
+}
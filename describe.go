@@ -0,0 +1,101 @@
+package figgy
+
+import (
+	"reflect"
+	"strings"
+)
+
+// FieldSpec describes a field of a struct loaded by figgy, for tools such as
+// documentation and policy generators
+type FieldSpec struct {
+	// Key of the parameter after template expansion, or bucket/key for an s3 tag
+	Key string
+	// Path of the field within the struct, such as "Database.Host"
+	Path string
+	// Type of the field
+	Type reflect.Type
+	// Source is the tag the field is loaded by, "ssm" or "s3"
+	Source string
+	// Options of the tag by name, with the value of options such as path= and an
+	// empty value for options without one
+	Options map[string]string
+}
+
+// Describe returns the fields of v, a struct or pointer to a struct, that Load would
+// assign, in the order they're declared.  Tag substitution uses data, as with
+// LoadWithParameters.  v may be a nil pointer, as only its type is inspected.
+func Describe(v interface{}, data interface{}) ([]FieldSpec, error) {
+	t := reflect.TypeOf(v)
+	rv := reflect.ValueOf(v)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		rv = rv.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, &InvalidTypeError{Type: reflect.TypeOf(v)}
+	}
+	f, err := inspect(rv, t, data, "")
+	if err != nil {
+		return nil, err
+	}
+	specs := make([]FieldSpec, len(f))
+	for i, x := range f {
+		specs[i] = x.spec()
+	}
+	return specs, nil
+}
+
+// spec describes the field for use outside of the package
+func (f *field) spec() FieldSpec {
+	s := FieldSpec{
+		Key:     f.key,
+		Path:    f.name,
+		Type:    f.field.Type,
+		Source:  "ssm",
+		Options: make(map[string]string),
+	}
+	if f.object {
+		s.Source = "s3"
+	}
+	flags := map[string]bool{
+		"decrypt": f.decrypt,
+		"json":    f.json,
+		"chunks":  f.chunks,
+		"dotenv":  f.dotenv,
+		"setenv":  f.setenv,
+		"static":  f.static,
+		"path":    f.tree,
+		"raw":     f.raw,
+	}
+	for name, set := range flags {
+		if set {
+			s.Options[name] = ""
+		}
+	}
+	if f.format != "" {
+		s.Options[f.format] = ""
+	}
+	values := map[string]string{
+		"path":    f.path,
+		"refresh": f.refresh,
+		"region":  f.region,
+		"old":     f.old,
+		"rename":  f.rename,
+		"group":   f.group,
+		"durfmt":  f.durfmt,
+		"oneof":   strings.Join(f.oneof, "|"),
+		"min":     f.min,
+		"max":     f.max,
+		"emptyas": f.emptyas,
+		"nilas":   f.nilas,
+	}
+	if f.match != nil {
+		values["match"] = f.match.String()
+	}
+	for name, v := range values {
+		if v != "" {
+			s.Options[name] = v
+		}
+	}
+	return s
+}
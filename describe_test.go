@@ -0,0 +1,36 @@
+package figgy
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribe(t *testing.T) {
+	type database struct {
+		Host     string `ssm:"/{{.env}}/db/host,refresh=fast"`
+		Password string `ssm:"/{{.env}}/db/password,decrypt"`
+	}
+	type config struct {
+		Database *database
+		Settings map[string]string `ssm:"/{{.env}}/settings,json,path=$.settings,region=us-west-2"`
+		Routes   []string          `s3:"config/{{.env}}/routes.json,json"`
+		Local    string
+	}
+	specs, err := Describe((*config)(nil), map[string]string{"env": "prod"})
+	assert.NoError(t, err)
+	assert.Equal(t, []FieldSpec{
+		{Key: "/prod/db/host", Path: "Database.Host", Type: reflect.TypeOf(""), Source: "ssm", Options: map[string]string{"refresh": "fast"}},
+		{Key: "/prod/db/password", Path: "Database.Password", Type: reflect.TypeOf(""), Source: "ssm", Options: map[string]string{"decrypt": ""}},
+		{Key: "/prod/settings", Path: "Settings", Type: reflect.TypeOf(map[string]string{}), Source: "ssm", Options: map[string]string{"json": "", "path": "$.settings", "region": "us-west-2"}},
+		{Key: "config/prod/routes.json", Path: "Routes", Type: reflect.TypeOf([]string{}), Source: "s3", Options: map[string]string{"json": ""}},
+	}, specs)
+
+	_, err = Describe(config{}, nil)
+	assert.NoError(t, err)
+	_, err = Describe("config", nil)
+	assert.Error(t, err)
+	_, err = Describe(nil, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,150 @@
+package figgy
+
+import (
+	"reflect"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// Difference describes a field whose value differs from its parameter in Parameter Store.
+// Values of fields with the decrypt option are included, so take care when logging them.
+type Difference struct {
+	// Field is the path of the field within the struct, such as Database.Host
+	Field string
+	// Key of the parameter
+	Key string
+	// Local is the value held by the field, or nil when it is behind a nil pointer
+	Local interface{}
+	// Remote is the parameter value decoded to the field's type, or nil when missing
+	Remote interface{}
+	// Version of the remote parameter
+	Version int64
+	// Missing is true when the parameter doesn't exist
+	Missing bool
+}
+
+// Diff compares the values held by a struct with the parameters its tags refer to and
+// returns the fields that differ.  Remote values are decoded the same way as Load
+// before being compared, and neither the struct nor Parameter Store is modified.
+func Diff(c ssmiface.SSMAPI, v interface{}, opts ...Option) ([]Difference, error) {
+	return DiffWithParameters(c, v, nil, opts...)
+}
+
+// DiffWithParameters compares a struct with Parameter Store, performing parameter
+// substitution on field tags the same way as LoadWithParameters.
+func DiffWithParameters(c ssmiface.SSMAPI, v interface{}, data interface{}, opts ...Option) ([]Difference, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return nil, &InvalidTypeError{Type: reflect.TypeOf(v)}
+	}
+	f, err := inspect(rv.Elem(), rv.Elem().Type(), data, "")
+	f = parameterFields(f)
+	if err != nil {
+		return nil, err
+	}
+	o := newOptions(opts)
+	o.normalizeKeys(f)
+	remote, err := fetchFields(c, f)
+	if err != nil {
+		return nil, err
+	}
+	var diffs []Difference
+	for _, x := range f {
+		d, err := diffField(c, x, remote[x], o)
+		if err != nil {
+			return nil, err
+		}
+		if d != nil {
+			diffs = append(diffs, *d)
+		}
+	}
+	return diffs, nil
+}
+
+// diffField compares a field with its remote parameter, returning nil when they match
+func diffField(c ssmiface.SSMAPI, x *field, p *ssm.Parameter, o *options) (*Difference, error) {
+	d := &Difference{
+		Field: x.name,
+		Key:   x.key,
+	}
+	if x.value.IsValid() {
+		d.Local = x.value.Interface()
+	}
+	if p == nil {
+		d.Missing = true
+		return d, nil
+	}
+	d.Version = aws.Int64Value(p.Version)
+	remote, err := decodeField(c, x, aws.StringValue(p.Value), o)
+	if err != nil {
+		return nil, err
+	}
+	d.Remote = remote
+	if x.value.IsValid() && reflect.DeepEqual(d.Local, d.Remote) {
+		return nil, nil
+	}
+	return d, nil
+}
+
+// decodeField decodes a value the same way Load would for the field, but into a new
+// value so the struct is left untouched
+func decodeField(c ssmiface.SSMAPI, x *field, s string, o *options) (interface{}, error) {
+	t := x.field.Type
+	if t.Kind() == reflect.Ptr && !x.nilable() {
+		t = t.Elem()
+	}
+	y := *x
+	y.value = reflect.New(t).Elem()
+	y.setenv = false
+	if err := assign(c, &y, s, o); err != nil {
+		return nil, err
+	}
+	return y.value.Interface(), nil
+}
+
+// fetchFields fetches the parameters for the fields without failing on missing parameters
+func fetchFields(c ssmiface.SSMAPI, f []*field) (map[*field]*ssm.Parameter, error) {
+	remote := make(map[*field]*ssm.Parameter, len(f))
+	var plain, decrypt []*field
+	shared := decrypted(f)
+	for _, x := range f {
+		switch {
+		case x.chunks:
+			p, err := fetchChunks(c, x.key, x.decrypt)
+			if err != nil {
+				return nil, err
+			}
+			remote[x] = p
+		case shared(x):
+			decrypt = append(decrypt, x)
+		default:
+			plain = append(plain, x)
+		}
+	}
+	for _, g := range []struct {
+		f       []*field
+		decrypt bool
+	}{{plain, false}, {decrypt, true}} {
+		decrypt := g.decrypt
+		err := batchIterateFields(groupFields(g.f), maxParameters, func(f []*field) error {
+			res, err := c.GetParameters(&ssm.GetParametersInput{
+				Names:          parameterNames(f),
+				WithDecryption: aws.Bool(decrypt),
+			})
+			if err != nil {
+				return err
+			}
+			idx := indexParameters(res.Parameters)
+			for _, x := range f {
+				remote[x] = idx[x.key]
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return remote, nil
+}
@@ -0,0 +1,65 @@
+package figgy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	m := NewMockSSMClient()
+	c := struct {
+		Same     string        `ssm:"string"`
+		Changed  int           `ssm:"int"`
+		Duration time.Duration `ssm:"durationstring"`
+		Missing  string        `ssm:"/no/such/param"`
+		Nested   Nested
+		PNested  *Nested
+	}{
+		Same:     "this is a string",
+		Changed:  1,
+		Duration: time.Hour,
+		Missing:  "local",
+		Nested:   Nested{String: "changed", PString: aws.String("this is a ptr to a string")},
+	}
+	diffs, err := Diff(m, &c)
+	assert.NoError(t, err)
+	assert.Len(t, diffs, 5)
+	byField := make(map[string]Difference)
+	for _, d := range diffs {
+		byField[d.Field] = d
+	}
+	assert.Equal(t, Difference{Field: "Changed", Key: "int", Local: 1, Remote: 2}, byField["Changed"])
+	assert.Equal(t, Difference{Field: "Missing", Key: "/no/such/param", Local: "local", Missing: true}, byField["Missing"])
+	assert.Equal(t, "this is a string", byField["Nested.String"].Remote)
+	assert.Nil(t, byField["PNested.String"].Local)
+	assert.Nil(t, byField["PNested.PString"].Local)
+	assert.Nil(t, c.PNested)
+	assert.Equal(t, "changed", c.Nested.String)
+}
+
+func TestDiffVersion(t *testing.T) {
+	m := NewMockSSMClient()
+	c := struct {
+		String string `ssm:"string"`
+	}{String: "first"}
+	assert.NoError(t, Store(m, &c, WithOverwrite()))
+	c.String = "second"
+	diffs, err := Diff(m, &c)
+	assert.NoError(t, err)
+	assert.Len(t, diffs, 1)
+	assert.Equal(t, "first", diffs[0].Remote)
+	assert.Equal(t, int64(1), diffs[0].Version)
+}
+
+func TestDiffErrors(t *testing.T) {
+	_, err := Diff(NewMockSSMClient(), struct{}{})
+	assert.Error(t, err)
+	var c struct {
+		Int int `ssm:"string"`
+	}
+	_, err = Diff(NewMockSSMClient(), &c)
+	assert.Error(t, err)
+}
@@ -0,0 +1,57 @@
+package figgy
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Markdown documents the parameters of v, a struct or pointer to a struct, as a
+// Markdown table with the key, the field it's loaded into, its type, whether it's a
+// secure parameter, and the other options of its tag.  Tag substitution uses data,
+// as with LoadWithParameters.  Every parameter is required, as Load fails when one
+// is missing.
+func Markdown(v interface{}, data interface{}) ([]byte, error) {
+	specs, err := Describe(v, data)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	b.WriteString("| Parameter | Field | Type | Secure | Options |\n")
+	b.WriteString("|-----------|-------|------|--------|---------|\n")
+	for _, s := range specs {
+		key := s.Key
+		if s.Source == "s3" {
+			key = "s3://" + key
+		}
+		secure := "no"
+		if _, ok := s.Options["decrypt"]; ok {
+			secure = "yes"
+		}
+		fmt.Fprintf(&b, "| `%s` | `%s` | `%s` | %s | %s |\n",
+			markdownCell(key), markdownCell(s.Path), markdownCell(s.Type.String()), secure, markdownCell(docOptions(s.Options)))
+	}
+	return b.Bytes(), nil
+}
+
+// docOptions lists the options of a tag other than decrypt
+func docOptions(options map[string]string) string {
+	var l []string
+	for name, v := range options {
+		switch {
+		case name == "decrypt":
+		case v == "":
+			l = append(l, name)
+		default:
+			l = append(l, name+"="+v)
+		}
+	}
+	sort.Strings(l)
+	return strings.Join(l, ", ")
+}
+
+// markdownCell escapes the pipes that would end a table cell
+func markdownCell(s string) string {
+	return strings.Replace(s, "|", "\\|", -1)
+}
@@ -0,0 +1,26 @@
+package figgy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkdown(t *testing.T) {
+	type config struct {
+		Host     string            `ssm:"/{{.env}}/db/host"`
+		Password string            `ssm:"/{{.env}}/db/password,decrypt"`
+		Timeout  Duration          `ssm:"/{{.env}}/db/timeout,refresh=fast"`
+		Region   string            `ssm:"/{{.env}}/settings,json,path=$.region|regions"`
+		Routes   map[string]string `s3:"config/{{.env}}/routes.json,json"`
+	}
+	b, err := Markdown(&config{}, map[string]string{"env": "prod"})
+	assert.NoError(t, err)
+	assert.Equal(t, "| Parameter | Field | Type | Secure | Options |\n"+
+		"|-----------|-------|------|--------|---------|\n"+
+		"| `/prod/db/host` | `Host` | `string` | no |  |\n"+
+		"| `/prod/db/password` | `Password` | `string` | yes |  |\n"+
+		"| `/prod/db/timeout` | `Timeout` | `figgy.Duration` | no | refresh=fast |\n"+
+		"| `/prod/settings` | `Region` | `string` | no | json, path=$.region\\|regions |\n"+
+		"| `s3://config/prod/routes.json` | `Routes` | `map[string]string` | no | json |\n", string(b))
+}
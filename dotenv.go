@@ -0,0 +1,77 @@
+package figgy
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// parseDotenv parses KEY=VALUE lines in the dotenv format.  Blank lines and lines
+// starting with # are skipped, a leading "export " is ignored, and values may be
+// single quoted, taken literally, or double quoted, which allows escapes like \n.
+func parseDotenv(s string) (map[string]string, error) {
+	env := make(map[string]string)
+	for i, l := range strings.Split(s, "\n") {
+		l = strings.TrimSpace(l)
+		if l == "" || strings.HasPrefix(l, "#") {
+			continue
+		}
+		l = strings.TrimPrefix(l, "export ")
+		kv := strings.SplitN(l, "=", 2)
+		k := strings.TrimSpace(kv[0])
+		if len(kv) != 2 || k == "" {
+			return nil, fmt.Errorf("invalid dotenv line %d", i+1)
+		}
+		v := strings.TrimSpace(kv[1])
+		switch {
+		case len(v) >= 2 && v[0] == '\'' && v[len(v)-1] == '\'':
+			v = v[1 : len(v)-1]
+		case len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"':
+			u, err := strconv.Unquote(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid dotenv line %d", i+1)
+			}
+			v = u
+		default:
+			// strip trailing comments from unquoted values
+			if j := strings.Index(v, " #"); j >= 0 {
+				v = strings.TrimSpace(v[:j])
+			}
+		}
+		env[k] = v
+	}
+	return env, nil
+}
+
+// setDotenv expands a dotenv formatted value into a map field, converting each
+// value to the map's element type, and into the process environment when the
+// setenv option is present.
+func setDotenv(f *field, s string) error {
+	env, err := parseDotenv(s)
+	if err != nil {
+		return fmt.Errorf("%v for field '%s'", err, f.field.Name)
+	}
+	v := f.value
+	if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+		return fmt.Errorf("cannot use 'dotenv' option on a non map[string] type: %s %s", f.field.Name, f.field.Type.String())
+	}
+	m := reflect.MakeMapWithSize(v.Type(), len(env))
+	for k, x := range env {
+		e := reflect.New(v.Type().Elem()).Elem()
+		if err := set(&field{value: e}, x); err != nil {
+			return err
+		}
+		m.SetMapIndex(reflect.ValueOf(k).Convert(v.Type().Key()), e)
+	}
+	if f.setenv {
+		for k, x := range env {
+			if err := os.Setenv(k, x); err != nil {
+				return err
+			}
+		}
+	}
+	v.Set(m)
+	return nil
+}
@@ -0,0 +1,76 @@
+package figgy
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const dotenvDocument = `
+# legacy settings
+export FIGGY_TEST_HOST=db.local
+FIGGY_TEST_PORT=5432 # inline comment
+FIGGY_TEST_SINGLE='literal \n value'
+FIGGY_TEST_DOUBLE="line one\nline two"
+`
+
+func TestDotenv(t *testing.T) {
+	defer os.Unsetenv("FIGGY_TEST_HOST")
+	defer os.Unsetenv("FIGGY_TEST_PORT")
+	defer os.Unsetenv("FIGGY_TEST_SINGLE")
+	defer os.Unsetenv("FIGGY_TEST_DOUBLE")
+
+	m := NewMockSSMClientWith(map[string]string{
+		"/app/.env":  dotenvDocument,
+		"/app/ports": "HTTP=80\nHTTPS=443",
+	})
+	var c struct {
+		Env   map[string]string `ssm:"/app/.env,dotenv"`
+		Ports map[string]int    `ssm:"/app/ports,dotenv"`
+	}
+	err := Load(m, &c)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"FIGGY_TEST_HOST":   "db.local",
+		"FIGGY_TEST_PORT":   "5432",
+		"FIGGY_TEST_SINGLE": `literal \n value`,
+		"FIGGY_TEST_DOUBLE": "line one\nline two",
+	}, c.Env)
+	assert.Equal(t, map[string]int{"HTTP": 80, "HTTPS": 443}, c.Ports)
+	assert.Equal(t, "", os.Getenv("FIGGY_TEST_HOST"))
+
+	var e struct {
+		Env map[string]string `ssm:"/app/.env,dotenv,setenv"`
+	}
+	err = Load(m, &e)
+	assert.NoError(t, err)
+	assert.Equal(t, "db.local", os.Getenv("FIGGY_TEST_HOST"))
+	assert.Equal(t, "line one\nline two", os.Getenv("FIGGY_TEST_DOUBLE"))
+}
+
+func TestDotenvErrors(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{
+		"/app/.env": "HOST=db.local",
+		"/app/bad":  "no equals sign",
+		"/app/port": "PORT=http",
+	})
+	tests := map[string]interface{}{
+		"not a map": &struct {
+			Env string `ssm:"/app/.env,dotenv"`
+		}{},
+		"invalid line": &struct {
+			Env map[string]string `ssm:"/app/bad,dotenv"`
+		}{},
+		"invalid value": &struct {
+			Env map[string]int `ssm:"/app/port,dotenv"`
+		}{},
+		"setenv without dotenv": &struct {
+			Env map[string]string `ssm:"/app/.env,setenv"`
+		}{},
+	}
+	for n, in := range tests {
+		err := Load(m, in)
+		assert.Error(t, err, "test '%s' failed", n)
+	}
+}
@@ -0,0 +1,214 @@
+package figgy
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Format is a serialization format used by Dump
+type Format string
+
+const (
+	// JSON serializes as indented JSON
+	JSON Format = "json"
+	// YAML serializes as YAML
+	YAML Format = "yaml"
+)
+
+// redacted replaces the value of secret fields in a dump
+const redacted = "[REDACTED]"
+
+// DumpOption configures Dump
+type DumpOption func(*dumpOptions)
+
+type dumpOptions struct {
+	redact bool
+}
+
+// RedactSecrets controls masking the values of fields tagged with the decrypt
+// option.  Secrets are redacted unless RedactSecrets(false) is given.
+func RedactSecrets(redact bool) DumpOption {
+	return func(o *dumpOptions) {
+		o.redact = redact
+	}
+}
+
+// Dump serializes the configuration held in v, a struct or pointer to a struct,
+// so the effective configuration can be logged or inspected.  Fields appear in
+// the order they are declared, using their Go names, and durations are written
+// in their string form.
+func Dump(v interface{}, format Format, opts ...DumpOption) ([]byte, error) {
+	o := &dumpOptions{redact: true}
+	for _, opt := range opts {
+		opt(o)
+	}
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil, &InvalidTypeError{Type: reflect.TypeOf(v)}
+	}
+	m, err := dumpStruct(rv, o)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case JSON:
+		return json.MarshalIndent(m, "", "  ")
+	case YAML:
+		return yaml.Marshal(m)
+	}
+	return nil, fmt.Errorf("unsupported dump format '%s'", format)
+}
+
+// dumpMap is an object whose keys are serialized in order
+type dumpMap []dumpItem
+
+type dumpItem struct {
+	key   string
+	value interface{}
+}
+
+func (m dumpMap) MarshalJSON() ([]byte, error) {
+	b := &bytes.Buffer{}
+	b.WriteString("{")
+	for i, x := range m {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		k, err := json.Marshal(x.key)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(k)
+		b.WriteString(":")
+		v, err := json.Marshal(x.value)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(v)
+	}
+	b.WriteString("}")
+	return b.Bytes(), nil
+}
+
+func (m dumpMap) MarshalYAML() (interface{}, error) {
+	s := make(yaml.MapSlice, len(m))
+	for i, x := range m {
+		s[i] = yaml.MapItem{Key: x.key, Value: x.value}
+	}
+	return s, nil
+}
+
+func dumpStruct(v reflect.Value, o *dumpOptions) (dumpMap, error) {
+	m := make(dumpMap, 0, v.NumField())
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		fv := v.Field(i)
+		ft := t.Field(i)
+		// ignore unexported field
+		if ft.PkgPath != "" {
+			continue
+		}
+		pf, err := tag(ft, nil)
+		if err != nil {
+			return nil, err
+		}
+		if pf != nil && pf.decrypt && o.redact {
+			if fv.Kind() == reflect.Ptr && fv.IsNil() {
+				m = append(m, dumpItem{key: ft.Name})
+				continue
+			}
+			m = append(m, dumpItem{key: ft.Name, value: redacted})
+			continue
+		}
+		// promote the fields of embedded structs without an 'ssm' tag
+		if ft.Anonymous && pf == nil && reflect.Indirect(fv).Kind() == reflect.Struct {
+			e, err := dumpStruct(reflect.Indirect(fv), o)
+			if err != nil {
+				return nil, err
+			}
+			m = append(m, e...)
+			continue
+		}
+		x, err := dumpValue(fv, o)
+		if err != nil {
+			return nil, err
+		}
+		m = append(m, dumpItem{key: ft.Name, value: x})
+	}
+	return m, nil
+}
+
+func dumpValue(v reflect.Value, o *dumpOptions) (interface{}, error) {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return nil, nil
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return dumpValue(v.Elem(), o)
+	}
+	if v.Type() == rawType {
+		return string(v.Bytes()), nil
+	}
+	if v.Type().AssignableTo(durationType) {
+		return time.Duration(v.Int()).String(), nil
+	}
+	if marshals(v.Type()) {
+		return v.Interface(), nil
+	}
+	if marshals(reflect.PtrTo(v.Type())) {
+		// methods with a pointer receiver need an addressable copy
+		p := reflect.New(v.Type())
+		p.Elem().Set(v)
+		return p.Interface(), nil
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		return dumpStruct(v, o)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil, nil
+		}
+		s := make([]interface{}, v.Len())
+		for i := range s {
+			x, err := dumpValue(v.Index(i), o)
+			if err != nil {
+				return nil, err
+			}
+			s[i] = x
+		}
+		return s, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		m := make(dumpMap, len(keys))
+		for i, k := range keys {
+			x, err := dumpValue(v.MapIndex(k), o)
+			if err != nil {
+				return nil, err
+			}
+			m[i] = dumpItem{key: fmt.Sprint(k.Interface()), value: x}
+		}
+		return m, nil
+	}
+	return v.Interface(), nil
+}
+
+// marshals reports whether a type knows how to serialize itself
+func marshals(t reflect.Type) bool {
+	return t.Implements(reflect.TypeOf((*json.Marshaler)(nil)).Elem()) ||
+		t.Implements(reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem())
+}
@@ -0,0 +1,98 @@
+package figgy
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	yaml "gopkg.in/yaml.v2"
+)
+
+type DumpBase struct {
+	Region string `ssm:"region"`
+}
+
+type DumpConfig struct {
+	DumpBase
+	Host     string        `ssm:"host"`
+	Password string        `ssm:"password,decrypt"`
+	Token    *string       `ssm:"token,decrypt"`
+	Timeout  time.Duration `ssm:"timeout"`
+	Limit    *big.Int      `ssm:"limit"`
+	Nested   Nested
+	Ports    map[string]int
+	Raw      Raw
+	hidden   string
+}
+
+func newDumpConfig() DumpConfig {
+	return DumpConfig{
+		DumpBase: DumpBase{Region: "us-east-1"},
+		Host:     "db.local",
+		Password: "hunter2",
+		Timeout:  5 * time.Second,
+		Limit:    big.NewInt(10),
+		Nested:   Nested{String: "nested"},
+		Ports:    map[string]int{"https": 443, "http": 80},
+		Raw:      Raw(`{"a":1}`),
+		hidden:   "hidden",
+	}
+}
+
+func TestDumpJSON(t *testing.T) {
+	c := newDumpConfig()
+	b, err := Dump(&c, JSON)
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+  "Region": "us-east-1",
+  "Host": "db.local",
+  "Password": "[REDACTED]",
+  "Token": null,
+  "Timeout": "5s",
+  "Limit": 10,
+  "Nested": {
+    "String": "nested",
+    "PString": null
+  },
+  "Ports": {
+    "http": 80,
+    "https": 443
+  },
+  "Raw": "{\"a\":1}"
+}`, string(b))
+
+	b, err = Dump(c, JSON, RedactSecrets(false))
+	assert.NoError(t, err)
+	var m map[string]interface{}
+	assert.NoError(t, json.Unmarshal(b, &m))
+	assert.Equal(t, "hunter2", m["Password"])
+	assert.Equal(t, float64(10), m["Limit"])
+}
+
+func TestDumpYAML(t *testing.T) {
+	c := newDumpConfig()
+	b, err := Dump(&c, YAML)
+	assert.NoError(t, err)
+	assert.False(t, strings.Contains(string(b), "hunter2"))
+	var m struct {
+		Host     string `yaml:"Host"`
+		Password string `yaml:"Password"`
+		Timeout  string `yaml:"Timeout"`
+	}
+	assert.NoError(t, yaml.Unmarshal(b, &m))
+	assert.Equal(t, "db.local", m.Host)
+	assert.Equal(t, "[REDACTED]", m.Password)
+	assert.Equal(t, "5s", m.Timeout)
+}
+
+func TestDumpErrors(t *testing.T) {
+	_, err := Dump(nil, JSON)
+	assert.Error(t, err)
+	_, err = Dump(42, JSON)
+	assert.Error(t, err)
+	_, err = Dump(&DumpConfig{}, Format("xml"))
+	assert.Error(t, err)
+}
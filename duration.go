@@ -0,0 +1,84 @@
+package figgy
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Formats of durations, set with the durfmt tag option
+const (
+	// durationGo is a Go duration such as 1m30s, the default
+	durationGo = "go"
+	// durationSeconds is a number of seconds such as 90 or 1.5
+	durationSeconds = "seconds"
+	// durationNanoseconds is an integer number of nanoseconds
+	durationNanoseconds = "ns"
+	// durationFallback is a Go duration, or else an integer number of nanoseconds, as
+	// with WithDurationFallback
+	durationFallback = "fallback"
+)
+
+// maxSeconds bounds the seconds of a duration, beyond which it overflows an int64 of
+// nanoseconds
+const maxSeconds = math.MaxInt64 / float64(time.Second)
+
+// errDurationRange is returned for a number of seconds that isn't a valid duration
+var errDurationRange = errors.New("duration out of range")
+
+// WithDurationFallback loads duration fields without the durfmt tag option from an
+// integer number of nanoseconds when their value isn't a Go duration, as figgy did
+// before durfmt was added.  A value of "60" is then 60ns rather than an error, so
+// prefer tagging the fields with durfmt=ns or durfmt=seconds.
+func WithDurationFallback() Option {
+	return func(o *options) {
+		o.durationFallback = true
+	}
+}
+
+// isDurationFormat reports whether format can be given to the durfmt tag option
+func isDurationFormat(format string) bool {
+	switch format {
+	case durationGo, durationSeconds, durationNanoseconds:
+		return true
+	}
+	return false
+}
+
+// parseDuration parses s as a duration in format
+func parseDuration(s string, format string) (time.Duration, error) {
+	switch format {
+	case durationSeconds:
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, err
+		}
+		// NaN fails the comparison too
+		if !(math.Abs(n) < maxSeconds) {
+			return 0, errDurationRange
+		}
+		return time.Duration(n * float64(time.Second)), nil
+	case durationNanoseconds:
+		n, err := strconv.ParseInt(s, 10, 64)
+		return time.Duration(n), err
+	case durationFallback:
+		if d, err := time.ParseDuration(s); err == nil {
+			return d, nil
+		}
+		n, err := strconv.ParseInt(s, 10, 64)
+		return time.Duration(n), err
+	}
+	return time.ParseDuration(s)
+}
+
+// formatDuration formats d as parseDuration parses it in format
+func formatDuration(d time.Duration, format string) string {
+	switch format {
+	case durationSeconds:
+		return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+	case durationNanoseconds:
+		return strconv.FormatInt(int64(d), 10)
+	}
+	return d.String()
+}
@@ -0,0 +1,67 @@
+package figgy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDurationFormats(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/timeout": "60", "/app/half": "1.5", "/app/go": "1m30s", "/app/list": "1,2"})
+	var cfg struct {
+		Seconds time.Duration   `ssm:"/app/timeout,durfmt=seconds"`
+		Half    *time.Duration  `ssm:"/app/half,durfmt=seconds"`
+		Nanos   time.Duration   `ssm:"/app/timeout,durfmt=ns"`
+		Go      time.Duration   `ssm:"/app/go,durfmt=go"`
+		Default time.Duration   `ssm:"/app/go"`
+		List    []time.Duration `ssm:"/app/list,durfmt=seconds"`
+	}
+	assert.NoError(t, Load(m, &cfg))
+	assert.Equal(t, time.Minute, cfg.Seconds)
+	assert.Equal(t, 1500*time.Millisecond, *cfg.Half)
+	assert.Equal(t, 60*time.Nanosecond, cfg.Nanos)
+	assert.Equal(t, 90*time.Second, cfg.Go)
+	assert.Equal(t, 90*time.Second, cfg.Default)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, cfg.List)
+
+	// an integer is no longer taken as nanoseconds by default
+	var ambiguous struct {
+		Timeout time.Duration `ssm:"/app/timeout"`
+	}
+	assert.Equal(t, &ConvertTypeError{Field: "Timeout", Type: "time.Duration", Value: "60"}, Load(m, &ambiguous))
+	assert.NoError(t, Load(m, &ambiguous, WithDurationFallback()))
+	assert.Equal(t, 60*time.Nanosecond, ambiguous.Timeout)
+
+	var invalid struct {
+		Timeout time.Duration `ssm:"/app/timeout,durfmt=minutes"`
+	}
+	assert.IsType(t, &TagParseError{}, Load(m, &invalid))
+
+	for _, v := range []string{"NaN", "Inf", "-Inf", "1e300", "9223372037", "-9223372037"} {
+		setParameter(m, "/app/timeout", v)
+		var seconds struct {
+			Timeout time.Duration `ssm:"/app/timeout,durfmt=seconds"`
+		}
+		assert.Equal(t, &ConvertTypeError{Field: "Timeout", Type: "time.Duration", Value: v}, Load(m, &seconds), v)
+	}
+	setParameter(m, "/app/timeout", "9223372036")
+	assert.NoError(t, Load(m, &cfg))
+	assert.Equal(t, 9223372036*time.Second, cfg.Seconds)
+}
+
+func TestStoreDurationFormats(t *testing.T) {
+	c := &recordingSSMClient{MockSSMClient: NewMockSSMClient()}
+	cfg := struct {
+		Seconds time.Duration `ssm:"/app/timeout,durfmt=seconds"`
+		Nanos   time.Duration `ssm:"/app/nanos,durfmt=ns"`
+		Go      time.Duration `ssm:"/app/go"`
+	}{1500 * time.Millisecond, time.Microsecond, time.Minute}
+	assert.NoError(t, Store(c, &cfg))
+	values := make(map[string]string)
+	for _, p := range c.puts {
+		values[aws.StringValue(p.Name)] = aws.StringValue(p.Value)
+	}
+	assert.Equal(t, map[string]string{"/app/timeout": "1.5", "/app/nanos": "1000", "/app/go": "1m0s"}, values)
+}
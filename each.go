@@ -0,0 +1,38 @@
+package figgy
+
+import (
+	"reflect"
+
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// LoadForEach loads a new struct from newT for each entry of data, performing parameter
+// substitution on its tags with that entry, such as once per tenant.  The parameters
+// of every struct are requested together, so they share batches and a parameter used
+// by several structs is requested once.  The structs are returned in the order of
+// data.  Validators set with WithValidator are given each struct.
+//
+//	configs, err := figgy.LoadForEach(ssmClient, []figgy.P{{"tenant": "a"}, {"tenant": "b"}}, func() interface{} {
+//		return &TenantConfig{}
+//	})
+func LoadForEach(c ssmiface.SSMAPI, data []P, newT func() interface{}, opts ...Option) ([]interface{}, error) {
+	vs := make([]interface{}, len(data))
+	var t []*field
+	for i, d := range data {
+		v := newT()
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Ptr || rv.IsNil() {
+			return nil, &InvalidTypeError{Type: reflect.TypeOf(v)}
+		}
+		f, err := walk(rv.Elem(), d)
+		if err != nil {
+			return nil, err
+		}
+		vs[i] = v
+		t = append(t, f...)
+	}
+	if err := loadFields(c, t, opts, vs...); err != nil {
+		return nil, err
+	}
+	return vs, nil
+}
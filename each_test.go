@@ -0,0 +1,45 @@
+package figgy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type tenantConfig struct {
+	Endpoint string `ssm:"/app/{{.tenant}}/endpoint"`
+	Region   string `ssm:"/app/region"`
+}
+
+func TestLoadForEach(t *testing.T) {
+	c := &countingSSMClient{MockSSMClient: NewMockSSMClientWith(map[string]string{
+		"/app/a/endpoint": "https://a",
+		"/app/b/endpoint": "https://b",
+		"/app/region":     "us-east-1",
+	})}
+	var validated []interface{}
+	vs, err := LoadForEach(c, []P{{"tenant": "a"}, {"tenant": "b"}}, func() interface{} {
+		return &tenantConfig{}
+	}, WithValidator(func(v interface{}) error {
+		validated = append(validated, v)
+		return nil
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		&tenantConfig{Endpoint: "https://a", Region: "us-east-1"},
+		&tenantConfig{Endpoint: "https://b", Region: "us-east-1"},
+	}, vs)
+	assert.Equal(t, vs, validated)
+	// the shared parameter is requested once, with the tenants' in one batch
+	assert.Equal(t, []int{3}, c.batches)
+
+	_, err = LoadForEach(c, []P{{"tenant": "c"}}, func() interface{} {
+		return &tenantConfig{}
+	})
+	assert.Error(t, err)
+
+	_, err = LoadForEach(c, []P{{"tenant": "a"}}, func() interface{} {
+		return tenantConfig{}
+	})
+	assert.IsType(t, &InvalidTypeError{}, err)
+}
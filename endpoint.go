@@ -0,0 +1,63 @@
+package figgy
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// defaultEndpointRegion is the region of clients from NewEndpointClient
+const defaultEndpointRegion = "us-east-1"
+
+// EndpointOption configures a client from NewEndpointClient
+type EndpointOption func(*aws.Config)
+
+// EndpointRegion sets the region of the client, us-east-1 by default
+func EndpointRegion(region string) EndpointOption {
+	return func(cfg *aws.Config) {
+		cfg.Region = aws.String(region)
+	}
+}
+
+// AnonymousCredentials sends requests unsigned, rather than signed with test
+// credentials, for emulators that check signatures they can't verify
+func AnonymousCredentials() EndpointOption {
+	return func(cfg *aws.Config) {
+		cfg.Credentials = credentials.AnonymousCredentials
+	}
+}
+
+// NewEndpointClient returns a client for a Parameter Store emulator, such as
+// LocalStack or moto, at endpoint.  Requests are signed with the static credentials
+// "test" and "test", which emulators accept, so nothing is read from the environment.
+//
+//	c, err := figgy.NewEndpointClient("http://localhost:4566")
+//	err = figgy.Load(c, &cfg, figgy.WithEndpoint("http://localhost:4566"))
+func NewEndpointClient(endpoint string, opts ...EndpointOption) (ssmiface.SSMAPI, error) {
+	sess, err := session.NewSession(endpointConfig(endpoint, opts))
+	if err != nil {
+		return nil, err
+	}
+	return ssm.New(sess), nil
+}
+
+func endpointConfig(endpoint string, opts []EndpointOption) *aws.Config {
+	cfg := aws.NewConfig().
+		WithEndpoint(endpoint).
+		WithRegion(defaultEndpointRegion).
+		WithCredentials(credentials.NewStaticCredentials("test", "test", ""))
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// WithEndpoint sends the requests of the clients created by WithSession to endpoint,
+// so parameters from other regions and roles are also loaded from an emulator
+func WithEndpoint(endpoint string) Option {
+	return func(o *options) {
+		o.endpoint = endpoint
+	}
+}
@@ -0,0 +1,36 @@
+package figgy
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndpointConfig(t *testing.T) {
+	cfg := endpointConfig("http://localhost:4566", nil)
+	assert.Equal(t, "http://localhost:4566", aws.StringValue(cfg.Endpoint))
+	assert.Equal(t, "us-east-1", aws.StringValue(cfg.Region))
+	assert.NotNil(t, cfg.Credentials)
+
+	cfg = endpointConfig("http://localhost:5000", []EndpointOption{EndpointRegion("eu-west-1"), AnonymousCredentials()})
+	assert.Equal(t, "eu-west-1", aws.StringValue(cfg.Region))
+	assert.True(t, cfg.Credentials == credentials.AnonymousCredentials)
+
+	c, err := NewEndpointClient("http://localhost:4566")
+	assert.NoError(t, err)
+	assert.NotNil(t, c)
+}
+
+func TestWithEndpoint(t *testing.T) {
+	sess := session.Must(session.NewSession())
+	o := newOptions([]Option{WithEndpoint("http://localhost:4566"), WithSession(sess)})
+	cfg := o.sessionConfig(sess, "us-west-2", "")
+	assert.Equal(t, "http://localhost:4566", aws.StringValue(cfg.Endpoint))
+	assert.Equal(t, "us-west-2", aws.StringValue(cfg.Region))
+
+	o = newOptions([]Option{WithSession(sess)})
+	assert.Nil(t, o.sessionConfig(sess, "us-west-2", "").Endpoint)
+}
@@ -0,0 +1,49 @@
+package figgy
+
+import (
+	"os"
+	"strings"
+	"unicode"
+)
+
+// WithEnv sets an environment variable for each loaded parameter, as well as loading
+// it into the struct, for processes that only read their environment.  name maps a
+// key to the variable's name, and EnvName is used when it's nil.  The variable holds
+// the parameter's value after any references and transforms are applied.
+func WithEnv(name func(key string) string) Option {
+	return func(o *options) {
+		if name == nil {
+			name = EnvName
+		}
+		o.envName = name
+	}
+}
+
+// WithEnvOnly is WithEnv without loading values into the struct, which is left as
+// it was
+func WithEnvOnly(name func(key string) string) Option {
+	return func(o *options) {
+		WithEnv(name)(o)
+		o.envOnly = true
+	}
+}
+
+// EnvName maps a key to an environment variable name by upper casing it and
+// replacing anything other than letters and digits with underscores, so
+// "/myapp/db-password" is MYAPP_DB_PASSWORD
+func EnvName(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r > unicode.MaxASCII || !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return '_'
+		}
+		return unicode.ToUpper(r)
+	}, strings.TrimLeft(key, "/"))
+}
+
+// setEnv sets the environment variable for a field's key when WithEnv is used
+func (o *options) setEnv(x *field, s string) error {
+	if o.envName == nil {
+		return nil
+	}
+	return os.Setenv(o.envName(x.key), s)
+}
@@ -0,0 +1,40 @@
+package figgy
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, "MYAPP_DB_PASSWORD", EnvName("/myapp/db-password"))
+	assert.Equal(t, "APP_V2_HOST_NAME", EnvName("app/v2/host.name"))
+}
+
+func TestWithEnv(t *testing.T) {
+	defer os.Unsetenv("FIGGY_TEST_HOST")
+	defer os.Unsetenv("FIGGY_TEST_PORT")
+	m := NewMockSSMClientWith(map[string]string{"/figgy/test/host": "db.local", "/figgy/test/port": "5432"})
+	type config struct {
+		Host string `ssm:"/figgy/test/host"`
+		Port int    `ssm:"/figgy/test/port"`
+	}
+
+	var c config
+	assert.NoError(t, Load(m, &c, WithEnv(nil)))
+	assert.Equal(t, config{Host: "db.local", Port: 5432}, c)
+	assert.Equal(t, "db.local", os.Getenv("FIGGY_TEST_HOST"))
+	assert.Equal(t, "5432", os.Getenv("FIGGY_TEST_PORT"))
+
+	os.Unsetenv("FIGGY_TEST_HOST")
+	var only config
+	last := func(key string) string { return strings.ToUpper(key[strings.LastIndex(key, "/")+1:]) }
+	assert.NoError(t, Load(m, &only, WithEnvOnly(last)))
+	defer os.Unsetenv("HOST")
+	defer os.Unsetenv("PORT")
+	assert.Equal(t, config{}, only)
+	assert.Equal(t, "db.local", os.Getenv("HOST"))
+	assert.Equal(t, "", os.Getenv("FIGGY_TEST_HOST"))
+}
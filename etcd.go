@@ -0,0 +1,67 @@
+package figgy
+
+import (
+	"context"
+)
+
+// EtcdAPI is the part of the etcd v3 client used by EtcdKV.  The etcd client isn't a
+// dependency of figgy, so its client is adapted to this interface.
+type EtcdAPI interface {
+	// Get returns the key, or nil when there's no such key, and the revision of the
+	// store
+	Get(ctx context.Context, key string) (*EtcdKeyValue, int64, error)
+	// Watch watches the keys beginning with prefix from revision, returning the keys
+	// of the first response's events, including deleted keys, and its revision.  It
+	// blocks until there's a response or ctx is done.
+	Watch(ctx context.Context, prefix string, revision int64) ([]*EtcdKeyValue, int64, error)
+}
+
+// EtcdKeyValue is a key of etcd
+type EtcdKeyValue struct {
+	Key   string
+	Value []byte
+	// ModRevision is the revision of the last change to the key
+	ModRevision int64
+}
+
+// EtcdKV is a KV of the keys of an etcd v3 cluster.  Versions are revisions, and
+// Watch is an etcd watch of the keys under the prefix from the revision after the
+// version.
+//
+//	kv := &figgy.EtcdKV{API: client}
+//	w, err := figgy.Watch(figgy.NewKVClient(kv), &cfg, nil, &figgy.KVNotifier{KV: kv, Prefix: "/myapp/"})
+type EtcdKV struct {
+	API EtcdAPI
+}
+
+// Get returns the value of the key and its ModRevision
+func (e *EtcdKV) Get(ctx context.Context, key string) (string, int64, bool, error) {
+	kv, _, err := e.API.Get(ctx, key)
+	if err != nil || kv == nil {
+		return "", 0, false, err
+	}
+	return string(kv.Value), kv.ModRevision, true, nil
+}
+
+// Watch watches the keys beginning with prefix from the revision after version,
+// returning the keys that changed.  With version 0 it returns the current revision
+// of the store.  A response without events, such as a progress notification, returns
+// version as the current revision.
+func (e *EtcdKV) Watch(ctx context.Context, prefix string, version int64) ([]string, int64, error) {
+	if version == 0 {
+		_, revision, err := e.API.Get(ctx, prefix)
+		return nil, revision, err
+	}
+	events, revision, err := e.API.Watch(ctx, prefix, version+1)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(events) == 0 {
+		return nil, version, nil
+	}
+	keys := make([]string, len(events))
+	for i, kv := range events {
+		keys[i] = kv.Key
+	}
+	return keys, revision, nil
+}
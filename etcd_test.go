@@ -0,0 +1,121 @@
+package figgy
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockEtcd is an etcd cluster keeping the history of its keys
+type mockEtcd struct {
+	mu       sync.Mutex
+	keys     map[string]*EtcdKeyValue
+	history  []*EtcdKeyValue
+	revision int64
+	changed  chan struct{}
+}
+
+func newMockEtcd(values map[string]string) *mockEtcd {
+	m := &mockEtcd{keys: make(map[string]*EtcdKeyValue), changed: make(chan struct{})}
+	for k, v := range values {
+		m.set(k, v)
+	}
+	return m
+}
+
+func (m *mockEtcd) Get(ctx context.Context, key string) (*EtcdKeyValue, int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.keys[key], m.revision, nil
+}
+
+func (m *mockEtcd) Watch(ctx context.Context, prefix string, revision int64) ([]*EtcdKeyValue, int64, error) {
+	for {
+		m.mu.Lock()
+		var events []*EtcdKeyValue
+		for _, kv := range m.history {
+			if kv.ModRevision >= revision && strings.HasPrefix(kv.Key, prefix) {
+				events = append(events, kv)
+			}
+		}
+		current, changed := m.revision, m.changed
+		m.mu.Unlock()
+		if len(events) > 0 {
+			return events, current, nil
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil, 0, ctx.Err()
+		}
+	}
+}
+
+// set stores value in key, or deletes it if value is empty
+func (m *mockEtcd) set(key, value string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.revision++
+	kv := &EtcdKeyValue{Key: key, Value: []byte(value), ModRevision: m.revision}
+	if value == "" {
+		delete(m.keys, key)
+	} else {
+		m.keys[key] = kv
+	}
+	m.history = append(m.history, kv)
+	close(m.changed)
+	m.changed = make(chan struct{})
+}
+
+func TestEtcdKV(t *testing.T) {
+	m := newMockEtcd(map[string]string{"/app/host": "a", "/app/password": "p", "/app/port": "1"})
+	kv := &EtcdKV{API: m}
+	var cfg WatchConfig
+	n := &KVNotifier{KV: kv, Prefix: "/app/"}
+	w, err := Watch(NewKVClient(kv), &cfg, nil, n)
+	assert.NoError(t, err)
+	defer w.Stop()
+	w.RLock()
+	assert.Equal(t, "a", cfg.Host)
+	assert.Equal(t, 1, cfg.Nested.Port)
+	w.RUnlock()
+
+	for n.current() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	m.set("/app/port", "2")
+	assert.True(t, waitChange(t, w))
+	w.RLock()
+	assert.Equal(t, 2, cfg.Nested.Port)
+	w.RUnlock()
+
+	m.set("/app/port", "")
+	assert.EqualError(t, Load(NewKVClient(kv), &cfg), "invalid parameters: /app/port")
+}
+
+func TestEtcdKVWatch(t *testing.T) {
+	m := newMockEtcd(map[string]string{"/app/host": "a", "/other": "x"})
+	kv := &EtcdKV{API: m}
+	_, revision, err := kv.Watch(context.Background(), "/app/", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), revision)
+
+	// a watch without a change under the prefix lasts until ctx is done
+	m.set("/other", "y")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	keys, _, err := kv.Watch(ctx, "/app/", revision)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Nil(t, keys)
+
+	m.set("/app/host", "b")
+	m.set("/app/host", "")
+	keys, current, err := kv.Watch(context.Background(), "/app/", revision)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/app/host", "/app/host"}, keys)
+	assert.Equal(t, int64(5), current)
+}
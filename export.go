@@ -0,0 +1,154 @@
+package figgy
+
+import (
+	"bytes"
+	"encoding/base64"
+	"reflect"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// ExportOption configures ExportEnv and ExportKubernetes
+type ExportOption func(*exportOptions)
+
+type exportOptions struct {
+	name      func(key string) string
+	namespace string
+	data      interface{}
+}
+
+// ExportNames maps keys to the names of the exported variables.  EnvName is used
+// otherwise.
+func ExportNames(name func(key string) string) ExportOption {
+	return func(o *exportOptions) {
+		o.name = name
+	}
+}
+
+// ExportNamespace sets the namespace of Kubernetes manifests
+func ExportNamespace(namespace string) ExportOption {
+	return func(o *exportOptions) {
+		o.namespace = namespace
+	}
+}
+
+// ExportParameters performs parameter substitution on field tags the same way as
+// LoadWithParameters
+func ExportParameters(data interface{}) ExportOption {
+	return func(o *exportOptions) {
+		o.data = data
+	}
+}
+
+// exported is the value of a parameter to export
+type exported struct {
+	name   string
+	value  string
+	secure bool
+}
+
+// exportValues encodes the parameter values of a loaded struct in the order of its
+// fields
+func exportValues(v interface{}, opts []ExportOption) ([]exported, *exportOptions, error) {
+	o := &exportOptions{name: EnvName}
+	for _, opt := range opts {
+		opt(o)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return nil, nil, &InvalidTypeError{Type: reflect.TypeOf(v)}
+	}
+	f, err := inspect(rv.Elem(), rv.Elem().Type(), o.data, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	var e []exported
+	seen := make(map[string]bool, len(f))
+	for _, x := range f {
+		if !x.value.IsValid() || seen[x.key] {
+			continue
+		}
+		seen[x.key] = true
+		s, err := encode(x)
+		if err != nil {
+			return nil, nil, err
+		}
+		e = append(e, exported{name: o.name(x.key), value: s, secure: x.decrypt})
+	}
+	return e, o, nil
+}
+
+// ExportEnv renders the parameters of a loaded struct as a .env file, with a
+// variable for each key named by EnvName or ExportNames.  Values that need it are
+// double quoted.
+func ExportEnv(v interface{}, opts ...ExportOption) ([]byte, error) {
+	e, _, err := exportValues(v, opts)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	for _, x := range e {
+		b.WriteString(x.name + "=" + quoteDotenv(x.value) + "\n")
+	}
+	return b.Bytes(), nil
+}
+
+// quoteDotenv quotes a value unless it can be written as is
+func quoteDotenv(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\r\n\"'#\\$`") {
+		return s
+	}
+	return strconv.Quote(s)
+}
+
+// ExportKubernetes renders the parameters of a loaded struct as Kubernetes manifests
+// called name: a ConfigMap of the plain values and a Secret of the values of fields
+// with the decrypt option, which are base64 encoded.  Keys are named by EnvName or
+// ExportNames, so the manifests can be used with envFrom.
+func ExportKubernetes(v interface{}, name string, opts ...ExportOption) ([]byte, error) {
+	e, o, err := exportValues(v, opts)
+	if err != nil {
+		return nil, err
+	}
+	var plain, secure yaml.MapSlice
+	for _, x := range e {
+		if x.secure {
+			secure = append(secure, yaml.MapItem{Key: x.name, Value: base64.StdEncoding.EncodeToString([]byte(x.value))})
+		} else {
+			plain = append(plain, yaml.MapItem{Key: x.name, Value: x.value})
+		}
+	}
+	metadata := yaml.MapSlice{{Key: "name", Value: name}}
+	if o.namespace != "" {
+		metadata = append(metadata, yaml.MapItem{Key: "namespace", Value: o.namespace})
+	}
+	var docs [][]byte
+	if len(plain) > 0 {
+		b, err := yaml.Marshal(yaml.MapSlice{
+			{Key: "apiVersion", Value: "v1"},
+			{Key: "kind", Value: "ConfigMap"},
+			{Key: "metadata", Value: metadata},
+			{Key: "data", Value: plain},
+		})
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, b)
+	}
+	if len(secure) > 0 {
+		b, err := yaml.Marshal(yaml.MapSlice{
+			{Key: "apiVersion", Value: "v1"},
+			{Key: "kind", Value: "Secret"},
+			{Key: "metadata", Value: metadata},
+			{Key: "type", Value: "Opaque"},
+			{Key: "data", Value: secure},
+		})
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, b)
+	}
+	return bytes.Join(docs, []byte("---\n")), nil
+}
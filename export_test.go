@@ -0,0 +1,64 @@
+package figgy
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	yaml "gopkg.in/yaml.v2"
+)
+
+type ExportConfig struct {
+	Host     string        `ssm:"/{{.env}}/db/host"`
+	Timeout  time.Duration `ssm:"/{{.env}}/db/timeout"`
+	Password string        `ssm:"/{{.env}}/db/password,decrypt"`
+	Banner   string        `ssm:"/{{.env}}/banner"`
+	Local    string
+}
+
+var exportConfig = ExportConfig{
+	Host:     "db.local",
+	Timeout:  5 * time.Second,
+	Password: "hunter2",
+	Banner:   "hello \"world\"",
+}
+
+func TestExportEnv(t *testing.T) {
+	b, err := ExportEnv(&exportConfig, ExportParameters(map[string]string{"env": "prod"}))
+	assert.NoError(t, err)
+	assert.Equal(t, "PROD_DB_HOST=db.local\nPROD_DB_TIMEOUT=5s\nPROD_DB_PASSWORD=hunter2\nPROD_BANNER=\"hello \\\"world\\\"\"\n", string(b))
+
+	env, err := parseDotenv(string(b))
+	assert.NoError(t, err)
+	assert.Equal(t, `hello "world"`, env["PROD_BANNER"])
+
+	_, err = ExportEnv(exportConfig)
+	assert.Error(t, err)
+}
+
+func TestExportKubernetes(t *testing.T) {
+	b, err := ExportKubernetes(&exportConfig, "myapp", ExportNamespace("prod"), ExportParameters(map[string]string{"env": "prod"}))
+	assert.NoError(t, err)
+	docs := bytes.Split(b, []byte("---\n"))
+	assert.Len(t, docs, 2)
+
+	type manifest struct {
+		Kind     string
+		Metadata map[string]string
+		Data     map[string]string
+	}
+	var configMap, secret manifest
+	assert.NoError(t, yaml.Unmarshal(docs[0], &configMap))
+	assert.NoError(t, yaml.Unmarshal(docs[1], &secret))
+	assert.Equal(t, manifest{
+		Kind:     "ConfigMap",
+		Metadata: map[string]string{"name": "myapp", "namespace": "prod"},
+		Data:     map[string]string{"PROD_DB_HOST": "db.local", "PROD_DB_TIMEOUT": "5s", "PROD_BANNER": `hello "world"`},
+	}, configMap)
+	assert.Equal(t, manifest{
+		Kind:     "Secret",
+		Metadata: map[string]string{"name": "myapp", "namespace": "prod"},
+		Data:     map[string]string{"PROD_DB_PASSWORD": "aHVudGVyMg=="},
+	}, secret)
+}
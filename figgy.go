@@ -1,14 +1,18 @@
-//Package figgy provides tags for loading parameters from AWS Parameter Store
+// Package figgy provides tags for loading parameters from AWS Parameter Store
 package figgy
 
 import (
 	"bytes"
+	"context"
+	"encoding"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"text/template"
 	"time"
 
@@ -22,10 +26,53 @@ const maxParameters = 10
 
 var durationType reflect.Type = reflect.TypeOf(time.Duration(0))
 
+var rawType reflect.Type = reflect.TypeOf(Raw(nil))
+
+// Raw is a raw parameter value.  Fields of this type, or json.RawMessage, receive
+// the parameter value verbatim.  When the json option is present the value is
+// validated as JSON but still left undecoded.
+type Raw = json.RawMessage
+
+// Unmarshaler is implemented by types that can decode themselves from a parameter value
 type Unmarshaler interface {
 	UnmarshalParameter(string) error
 }
 
+// Marshaler is implemented by types that can encode themselves as a parameter value
+type Marshaler interface {
+	MarshalParameter() (string, error)
+}
+
+// DecoderFunc decodes a parameter value into a value of a registered type
+type DecoderFunc func(string) (interface{}, error)
+
+var decoders = struct {
+	sync.RWMutex
+	m map[reflect.Type]DecoderFunc
+}{m: make(map[reflect.Type]DecoderFunc)}
+
+// RegisterDecoder registers a decoder used to set fields of type t.  This allows
+// loading types that can't implement Unmarshaler, such as those from third party
+// packages.  A registered decoder takes precedence over the type's own decoding.
+//
+// The value returned by the decoder must be assignable to t.  Registering a nil
+// decoder removes any decoder previously registered for t.
+func RegisterDecoder(t reflect.Type, f DecoderFunc) {
+	decoders.Lock()
+	defer decoders.Unlock()
+	if f == nil {
+		delete(decoders.m, t)
+		return
+	}
+	decoders.m[t] = f
+}
+
+func decoder(t reflect.Type) DecoderFunc {
+	decoders.RLock()
+	defer decoders.RUnlock()
+	return decoders.m[t]
+}
+
 // InvalidTypeError descibes an invalid argument passed to Load.
 type InvalidTypeError struct {
 	Type reflect.Type
@@ -54,6 +101,20 @@ func (e *TagParseError) Error() string {
 	return "failed to parse tag [" + e.Tag + "] for field " + e.Field
 }
 
+// TagConflictError describes fields that share a key with options that would load
+// it differently, where the field loaded last would depend on how the fields are
+// grouped into requests
+type TagConflictError struct {
+	// Key the fields share
+	Key string
+	// Fields with conflicting tags
+	Fields []string
+}
+
+func (e *TagConflictError) Error() string {
+	return "conflicting tags for key '" + e.Key + "' on fields " + strings.Join(e.Fields, ", ")
+}
+
 // ConvertTypeError describes a value that failed to be set for a field
 type ConvertTypeError struct {
 	//Field that the value was being assigned to
@@ -76,8 +137,46 @@ type field struct {
 	key     string
 	decrypt bool
 	json    bool
-	value   reflect.Value
-	field   reflect.StructField
+	chunks  bool
+	path    string
+	format  string
+	dotenv  bool
+	setenv  bool
+	refresh string
+	region  string
+	// old is the key being renamed to key, chosen between by the rename policy
+	old    string
+	rename string
+	// static fields are loaded once and left alone by watchers
+	static bool
+	// raw fields are assigned the parameter's value exactly, without conversion
+	raw bool
+	// durfmt is the format of durations: go, seconds or ns
+	durfmt string
+	// oneof are the values the field may be loaded with
+	oneof []string
+	// tree map fields are loaded from the parameters under their key, by relative name
+	tree bool
+	// glob fields are loaded from the parameters whose names match their key's wildcards
+	glob bool
+	// min and max bound the values of numeric fields
+	min, max string
+	// match is the pattern the values of string fields must match
+	match *regexp.Regexp
+	// loaded is set once the field is assigned by a load
+	loaded bool
+	// group names the section of the struct the field is loaded with by LoadGroup
+	group string
+	// object is true for fields with an s3 tag, whose keys are bucket/key
+	object bool
+	// emptyas is the value standing in for an empty one, which loads the field with
+	// its zero value: an empty string, or nil for pointers
+	emptyas string
+	// nilas is the value that loads pointer, slice and map fields with nil
+	nilas string
+	value reflect.Value
+	field reflect.StructField
+	name  string
 }
 
 func newField(key string, decrypt bool) *field {
@@ -87,6 +186,16 @@ func newField(key string, decrypt bool) *field {
 	}
 }
 
+// info describes the field for use outside of the package
+func (f *field) info() FieldInfo {
+	return FieldInfo{
+		Key:     f.key,
+		Field:   f.field,
+		Decrypt: f.decrypt,
+		JSON:    f.json,
+	}
+}
+
 // P is a convenience alias for passing paramters to LoadWithParameters
 type P map[string]interface{}
 
@@ -97,8 +206,13 @@ type P map[string]interface{}
 // match the array's typing.
 //
 // You can ignore a field by using "-" for a fields tag.  Unexported fields are also ignored.
-func Load(c ssmiface.SSMAPI, v interface{}) error {
-	return LoadWithParameters(c, v, nil)
+//
+// Load can be called again on a struct it populated.  Parameters are requested in
+// the same order each time, pointers that aren't nil are reused, and slices, maps
+// and decoded documents are replaced rather than merged into.  A parameter that no
+// longer exists fails the load, use Reload to reset its field instead.
+func Load(c ssmiface.SSMAPI, v interface{}, opts ...Option) error {
+	return LoadWithParameters(c, v, nil, opts...)
 }
 
 // LoadWithParameters loads AWS Parameter Store parameters based on the defined tags, performing parameter
@@ -109,7 +223,7 @@ func Load(c ssmiface.SSMAPI, v interface{}) error {
 // match the array's typing.
 //
 // You can ignore a field by using "-" for a fields tag.  Unexported fields are also ignored.
-func LoadWithParameters(c ssmiface.SSMAPI, v interface{}, data interface{}) error {
+func LoadWithParameters(c ssmiface.SSMAPI, v interface{}, data interface{}, opts ...Option) error {
 	rv := reflect.ValueOf(v)
 	if rv.Kind() != reflect.Ptr || rv.IsNil() {
 		return &InvalidTypeError{Type: reflect.TypeOf(v)}
@@ -118,23 +232,222 @@ func LoadWithParameters(c ssmiface.SSMAPI, v interface{}, data interface{}) erro
 	if err != nil {
 		return err
 	}
-	return load(c, t)
+	return loadFields(c, t, opts, v)
+}
+
+// loadFields loads the fields t of the structs vs, which are each validated once the
+// fields are loaded
+func loadFields(c ssmiface.SSMAPI, t []*field, opts []Option, vs ...interface{}) error {
+	o := newOptions(opts)
+	o.normalizeKeys(t)
+	if err := checkConflicts(t); err != nil {
+		return err
+	}
+	if o.budget > 0 {
+		o.deadline = time.Now().Add(o.budget)
+	}
+	if o.group != "" {
+		_, t = partitionFields(t, func(x *field) bool {
+			return x.group == o.group
+		})
+	}
+	o.logFields(t)
+	span := o.tracer.Start(nil, "figgy.Load")
+	span.SetAttribute("figgy.fields", len(t))
+	start := time.Now()
+	err := loadRemotes(c, span, t, o)
+	if err == nil {
+		err = o.checkPolicies(o.client(c, span), t)
+	}
+	if err == nil {
+		err = o.checkKMSKeys(o.client(c, span), t)
+	}
+	if err != nil && o.budget > 0 && !time.Now().Before(o.deadline) {
+		err = degraded(t, err)
+	}
+	if _, ok := err.(*DegradedError); err == nil || ok {
+		for _, v := range vs {
+			if verr := o.validate(v); verr != nil {
+				err = verr
+				break
+			}
+		}
+	}
+	o.metrics.LoadDone(time.Since(start), len(t), err)
+	span.End(err)
+	return err
+}
+
+// Reload loads v again as Load does, setting the fields of parameters that no longer
+// exist to their zero value rather than failing, so removed parameters don't leave
+// stale values behind.  Fields loaded with the chunks option or from S3 still fail
+// when missing.
+func Reload(c ssmiface.SSMAPI, v interface{}, opts ...Option) error {
+	return ReloadWithParameters(c, v, nil, opts...)
+}
+
+// LoadGroup loads only the fields of v with the tag option group=name, leaving the
+// others as they are, to load or refresh one section of a large struct.  The
+// validator set with WithValidator still checks all of v.
+func LoadGroup(c ssmiface.SSMAPI, v interface{}, name string, opts ...Option) error {
+	return LoadGroupWithParameters(c, v, name, nil, opts...)
+}
+
+// LoadGroupWithParameters loads a group of fields as LoadGroup does, performing
+// parameter substitution on field tags the same way as LoadWithParameters
+func LoadGroupWithParameters(c ssmiface.SSMAPI, v interface{}, name string, data interface{}, opts ...Option) error {
+	return LoadWithParameters(c, v, data, append(opts, func(o *options) {
+		o.group = name
+	})...)
+}
+
+// ReloadWithParameters reloads v as Reload does, performing parameter substitution on
+// field tags the same way as LoadWithParameters
+func ReloadWithParameters(c ssmiface.SSMAPI, v interface{}, data interface{}, opts ...Option) error {
+	return LoadWithParameters(c, v, data, append(opts, func(o *options) {
+		o.resetMissing = true
+	})...)
+}
+
+// resetFields sets the fields for the keys of missing parameters to their zero value,
+// returning the other fields
+func resetFields(f []*field, missing []string) []*field {
+	gone := make(map[string]bool, len(missing))
+	for _, k := range missing {
+		gone[k] = true
+	}
+	var rest []*field
+	for _, x := range f {
+		if gone[x.key] {
+			x.value.Set(reflect.Zero(x.value.Type()))
+			x.loaded = true
+			continue
+		}
+		rest = append(rest, x)
+	}
+	return rest
+}
+
+// LoadJSONParameter loads a single parameter containing a JSON document and decodes
+// it into v, which must be a pointer to a struct.  Fields of v that define an ssm tag
+// are then loaded as they would be by Load, overriding any value from the document.
+func LoadJSONParameter(c ssmiface.SSMAPI, key string, v interface{}, opts ...Option) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &InvalidTypeError{Type: reflect.TypeOf(v)}
+	}
+	o := newOptions(opts)
+	span := o.tracer.Start(nil, "figgy.LoadJSONParameter")
+	res, err := o.client(c, span).GetParameter(&ssm.GetParameterInput{
+		Name:           aws.String(key),
+		WithDecryption: aws.Bool(true),
+	})
+	span.End(err)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal([]byte(aws.StringValue(res.Parameter.Value)), v); err != nil {
+		return fmt.Errorf("json unmarshal error for parameter '%s': %v", key, err)
+	}
+	return Load(c, v, opts...)
+}
+
+// client wraps c with the request handling configured by the options, tracing
+// requests under span
+func (o *options) client(c ssmiface.SSMAPI, span Span) ssmiface.SSMAPI {
+	return o.trace(o.measure(o.guard(o.pace(o.limit(c)))), span)
 }
 
 // load fields from AWS Parameter Store
-func load(c ssmiface.SSMAPI, f []*field) error {
-	plain, decrypt := partitionFields(f, func(x *field) bool {
-		return x.decrypt
+func load(c ssmiface.SSMAPI, f []*field, o *options) error {
+	f, objects := partitionFields(f, func(x *field) bool {
+		return x.object
+	})
+	if err := loadObjects(c, objects, o); err != nil {
+		return err
+	}
+	f, trees := partitionFields(f, func(x *field) bool {
+		return x.tree
+	})
+	if err := loadTrees(c, trees, o); err != nil {
+		return err
+	}
+	f, globs := partitionFields(f, func(x *field) bool {
+		return x.glob
 	})
-	err := batchIterateFields(plain, maxParameters, func(f []*field) error {
-		return loadParameters(c, f, false)
+	if err := loadGlobs(c, globs, o); err != nil {
+		return err
+	}
+	f, renamed := partitionFields(f, func(x *field) bool {
+		return x.old != ""
 	})
+	missing, err := collectMissing(nil, loadRenamed(c, renamed, o))
 	if err != nil {
 		return err
 	}
-	return batchIterateFields(decrypt, maxParameters, func(f []*field) error {
-		return loadParameters(c, f, true)
+	f, chunked := partitionFields(f, func(x *field) bool {
+		return x.chunks
 	})
+	for _, x := range chunked {
+		if missing, err = collectMissing(missing, loadChunks(c, x, o)); err != nil {
+			return err
+		}
+	}
+	plain, decrypt := partitionFields(f, decrypted(f))
+	if missing, err = collectMissing(missing, loadGroup(c, plain, false, o)); err != nil {
+		return err
+	}
+	if missing, err = collectMissing(missing, loadDecrypted(c, decrypt, o)); err != nil {
+		return err
+	}
+	if missing != nil {
+		return missing
+	}
+	return nil
+}
+
+// decrypted returns whether a field's parameter is requested with decryption, which it
+// is for fields with the decrypt option and the fields of f sharing their keys.  A
+// parameter is requested once for all of the fields sharing its key, and each field
+// keeps its own decrypt option for logging, redaction and scrubbing.
+func decrypted(f []*field) func(x *field) bool {
+	keys := make(map[string]bool)
+	for _, x := range f {
+		if x.decrypt {
+			keys[x.key] = true
+		}
+	}
+	return func(x *field) bool {
+		return keys[x.key]
+	}
+}
+
+// checkConflicts returns a TagConflictError for the first fields sharing a key that
+// differ in how the parameter is requested: as chunks or renamed from another key
+func checkConflicts(f []*field) error {
+	type source struct {
+		key    string
+		region string
+		object bool
+	}
+	type request struct {
+		chunks bool
+		old    string
+		rename string
+	}
+	first := make(map[source]*field, len(f))
+	for _, x := range f {
+		s := source{x.key, x.region, x.object}
+		y, ok := first[s]
+		if !ok {
+			first[s] = x
+			continue
+		}
+		if (request{y.chunks, y.old, y.rename}) != (request{x.chunks, x.old, x.rename}) {
+			return &TagConflictError{Key: x.key, Fields: []string{y.field.Name, x.field.Name}}
+		}
+	}
+	return nil
 }
 
 // in place half stable partition
@@ -154,11 +467,34 @@ func partitionFields(f []*field, suffix func(*field) bool) (p1, p2 []*field) {
 	return f[:i], f[i:]
 }
 
+// groupFields stably reorders fields so those sharing a key are adjacent
+func groupFields(f []*field) []*field {
+	idx := make(map[string][]*field, len(f))
+	keys := make([]string, 0, len(f))
+	for _, x := range f {
+		if _, ok := idx[x.key]; !ok {
+			keys = append(keys, x.key)
+		}
+		idx[x.key] = append(idx[x.key], x)
+	}
+	g := make([]*field, 0, len(f))
+	for _, k := range keys {
+		g = append(g, idx[k]...)
+	}
+	return g
+}
+
+// batchIterateFields calls g with batches of fields that contain at most batchSize distinct keys
 func batchIterateFields(f []*field, batchSize int, g func([]*field) error) error {
 	for i := 0; i < len(f); {
-		j := i + batchSize
-		if j > len(f) {
-			j = len(f)
+		j, n := i, 0
+		for ; j < len(f); j++ {
+			if j == i || f[j].key != f[j-1].key {
+				if n == batchSize {
+					break
+				}
+				n++
+			}
 		}
 		if err := g(f[i:j]); err != nil {
 			return err
@@ -168,28 +504,130 @@ func batchIterateFields(f []*field, batchSize int, g func([]*field) error) error
 	return nil
 }
 
-func loadParameters(c ssmiface.SSMAPI, f []*field, decrypt bool) error {
-	params, err := getParameters(c, f, decrypt)
+func loadParameters(c ssmiface.SSMAPI, f []*field, decrypt bool, o *options) error {
+	o.logger.Debug("figgy: requesting parameters", "keys", aws.StringValueSlice(parameterNames(f)), "decrypt", decrypt)
+	get, action := getParameters, "ssm:GetParameters"
+	if len(parameterNames(f)) <= o.singleLimit {
+		get, action = getEachParameter, "ssm:GetParameter"
+	}
+	params, err := get(c, f, decrypt)
+	if _, ok := err.(*InvalidParametersError); err != nil && !ok {
+		err = requestError(err, aws.StringValueSlice(parameterNames(f)), action, decrypt)
+	}
+	idx := indexParameters(params)
+	if e, ok := err.(*InvalidParametersError); ok {
+		var missing []string
+		missing, err = o.resolveCase(c, idx, e.Names, decrypt)
+		if err == nil && len(missing) != 0 {
+			err = &InvalidParametersError{Names: missing}
+		}
+	}
+	if e, ok := err.(*InvalidParametersError); ok && o.resetMissing {
+		o.logger.Debug("figgy: resetting fields of missing parameters", "keys", e.Names)
+		f, err = resetFields(f, e.Names), nil
+	}
 	if err != nil {
+		if e, ok := err.(*InvalidParametersError); ok {
+			o.logger.Debug("figgy: invalid parameters", "keys", e.Names)
+			for _, name := range e.Names {
+				o.metrics.ParameterFailed(name)
+			}
+			// the parameters found are still assigned, so one missing parameter
+			// doesn't discard the rest of its batch
+			if err := assignParameters(c, foundFields(f, e.Names), idx, o); err != nil {
+				return err
+			}
+			e.Suggestions = suggestNames(c, e.Names)
+		}
 		return err
 	}
-	idx := indexParameters(params)
+	return assignParameters(c, f, idx, o)
+}
+
+// foundFields returns the fields of the parameters that aren't missing
+func foundFields(f []*field, missing []string) []*field {
+	gone := make(map[string]bool, len(missing))
+	for _, k := range missing {
+		gone[k] = true
+	}
+	var found []*field
+	for _, x := range f {
+		if !gone[x.key] {
+			found = append(found, x)
+		}
+	}
+	return found
+}
+
+// assignParameters assigns the fields from parameters indexed by name
+func assignParameters(c ssmiface.SSMAPI, f []*field, idx map[string]*ssm.Parameter, o *options) error {
 	for _, x := range f {
 		p, ok := idx[x.key]
 		if !ok {
+			o.metrics.ParameterFailed(x.key)
 			return fmt.Errorf("failed to load parameter for key '%s'", x.key)
 		}
-		err = set(x, aws.StringValue(p.Value))
-		if err != nil {
-			switch err := err.(type) {
-			case *ConvertTypeError:
-				//enrich the error with the field
-				err.Field = x.field.Name
-				return err
-			}
+		if err := assign(c, x, aws.StringValue(p.Value), o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// assign a loaded parameter value to its field
+func assign(c ssmiface.SSMAPI, x *field, s string, o *options) error {
+	s, err := o.dereference(c, x, s)
+	if err == nil {
+		s, err = o.transform(x, s)
+	}
+	if err == nil {
+		err = o.setEnv(x, s)
+	}
+	if err == nil && o.durationFallback && x.durfmt == "" {
+		x.durfmt = durationFallback
+	}
+	if err == nil && o.envOnly {
+		x.loaded = true
+		return nil
+	}
+	if err == nil {
+		err = parse(x, s)
+	}
+	if err != nil {
+		o.metrics.ParameterFailed(x.key)
+		switch err := err.(type) {
+		case *ConvertTypeError:
+			//enrich the error with the field
+			err.Field = x.field.Name
 			return err
 		}
+		return err
+	}
+	x.loaded = true
+	return nil
+}
+
+// parse converts s into a new value of the field's type and checks it against the
+// field's rules before setting the field, so it's left as it was when s is invalid
+func parse(x *field, s string) error {
+	if !x.value.CanSet() {
+		return errors.New(x.value.Type().String() + " cannot be set")
+	}
+	p := *x
+	p.value = reflect.New(x.value.Type()).Elem()
+	var err error
+	if p.path != "" {
+		err = setPath(&p, s)
+	} else {
+		err = set(&p, s)
+	}
+	if err == nil {
+		err = checkRules(&p)
+	}
+	if err != nil {
+		return err
 	}
+	setField(x.value, p.value)
 	return nil
 }
 
@@ -202,49 +640,102 @@ func getParameters(c ssmiface.SSMAPI, f []*field, decrypt bool) ([]*ssm.Paramete
 		return nil, err
 	}
 	if len(res.InvalidParameters) != 0 {
-		return nil, fmt.Errorf("invalid parameters: %s",
-			strings.Join(aws.StringValueSlice(res.InvalidParameters), ", "),
-		)
+		// the parameters found are returned for Reload
+		return res.Parameters, &InvalidParametersError{Names: aws.StringValueSlice(res.InvalidParameters)}
 	}
 	return res.Parameters, nil
 }
 
+// InvalidParametersError lists the requested parameters that don't exist, with the
+// names of similar parameters that do, so tooling can create the missing parameters
+type InvalidParametersError struct {
+	// Names of the parameters that don't exist
+	Names []string
+	// Suggestions of similar names that exist, by the name of a missing parameter
+	Suggestions map[string]string
+}
+
+func (e *InvalidParametersError) Error() string {
+	names := make([]string, len(e.Names))
+	for i, name := range e.Names {
+		names[i] = name
+		if s, ok := e.Suggestions[name]; ok {
+			names[i] += " (did you mean " + s + "?)"
+		}
+	}
+	return "invalid parameters: " + strings.Join(names, ", ")
+}
+
+// parameterNames returns the distinct keys of the fields
 func parameterNames(f []*field) []*string {
-	names := make([]*string, len(f))
+	names := make([]*string, 0, len(f))
+	seen := make(map[string]bool, len(f))
 	for i := range f {
-		names[i] = aws.String(f[i].key)
+		if seen[f[i].key] {
+			continue
+		}
+		seen[f[i].key] = true
+		names = append(names, aws.String(f[i].key))
 	}
 	return names
 }
 
+// indexParameters indexes parameters by name and by ARN, since parameters of the
+// account requested by ARN are returned with their name, while those shared with it
+// through AWS RAM are named by their ARN
 func indexParameters(params []*ssm.Parameter) map[string]*ssm.Parameter {
-	idx := make(map[string]*ssm.Parameter, len(params))
+	idx := make(map[string]*ssm.Parameter, 2*len(params))
 	for _, p := range params {
 		idx[aws.StringValue(p.Name)] = p
+		if p.ARN != nil {
+			idx[aws.StringValue(p.ARN)] = p
+		}
 	}
 	return idx
 }
 
-// walk the value recursively to initialize pointers and build a graph of fields and tag options
+// isARN reports whether a key is the ARN of a parameter rather than its name
+func isARN(key string) bool {
+	return strings.HasPrefix(key, "arn:")
+}
+
+// walk the value recursively to initialize pointers and build a graph of fields and tag options.
+// A tagged field is loaded whole from its parameter, even a struct whose own fields are
+// tagged.  Untagged structs, including those embedded by value or by pointer, are walked
+// for their fields, as are unexported embedded structs for their promoted fields.
+// Fields tagged "-" are skipped with everything in them.
 func walk(v reflect.Value, data interface{}) ([]*field, error) {
+	data = resolveData(context.Background(), data)
 	p := make([]*field, 0)
 	t := v.Type()
 	for i := 0; i < v.NumField(); i++ {
 		fv := v.Field(i)
 		ft := t.Field(i)
-		// ignore unexported field
-		if ft.PkgPath != "" {
+		if skipField(ft) {
 			continue
 		}
-		// handles initializing a ptr and gets the underlying value to operate on
-		if fv.Kind() == reflect.Ptr {
-			fv.Set(reflect.New(fv.Type().Elem()))
-			fv = reflect.Indirect(fv)
-		}
 		pf, err := tag(ft, data)
 		if err != nil {
 			return nil, err
 		}
+		// handles initializing a ptr and gets the underlying value to operate on.  Nil
+		// pointers are left nil unless they lead to fields that will be loaded.
+		if fv.Kind() == reflect.Ptr {
+			// pointers that may be loaded as nil are set as they are
+			if pf != nil && pf.nilable() {
+				pf.field = ft
+				pf.value = fv
+				p = append(p, pf)
+				continue
+			}
+			if fv.IsNil() {
+				if pf == nil && !hasTags(fv.Type().Elem(), nil) || !fv.CanSet() {
+					continue
+				}
+				fv.Set(reflect.New(fv.Type().Elem()))
+			}
+			fv = reflect.Indirect(fv)
+		}
 		if pf != nil {
 			pf.field = ft
 			pf.value = fv
@@ -265,64 +756,370 @@ func walk(v reflect.Value, data interface{}) ([]*field, error) {
 	return p, nil
 }
 
+// hasTags reports whether t is a struct with tagged fields, directly or in the
+// untagged structs it contains.  Types already seen aren't searched again.
+func hasTags(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if t.Kind() != reflect.Struct || seen[t] {
+		return false
+	}
+	if seen == nil {
+		seen = make(map[reflect.Type]bool)
+	}
+	seen[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		ft := t.Field(i)
+		if skipField(ft) {
+			continue
+		}
+		if fieldTag(ft) != "" {
+			return true
+		}
+		et := ft.Type
+		if et.Kind() == reflect.Ptr {
+			et = et.Elem()
+		}
+		if hasTags(et, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldTag returns the ssm or s3 tag of a struct field
+func fieldTag(ft reflect.StructField) string {
+	if t := ft.Tag.Get("ssm"); t != "" {
+		return t
+	}
+	return ft.Tag.Get("s3")
+}
+
+// skipField reports whether a struct field is skipped by walks: fields tagged "-" and
+// unexported fields, other than untagged embedded structs, whose exported fields are
+// promoted
+func skipField(ft reflect.StructField) bool {
+	t := fieldTag(ft)
+	if t == "-" {
+		return true
+	}
+	if ft.PkgPath == "" {
+		return false
+	}
+	et := ft.Type
+	if et.Kind() == reflect.Ptr {
+		et = et.Elem()
+	}
+	return !ft.Anonymous || et.Kind() != reflect.Struct || t != ""
+}
+
+// inspect builds a graph of fields like walk, but without initializing pointers.
+// Fields behind a nil pointer are given an invalid value and each field is named
+// with its path from the top level struct, prefixed by parent.
+func inspect(v reflect.Value, t reflect.Type, data interface{}, parent string) ([]*field, error) {
+	data = resolveData(context.Background(), data)
+	p := make([]*field, 0)
+	for i := 0; i < t.NumField(); i++ {
+		ft := t.Field(i)
+		if skipField(ft) {
+			continue
+		}
+		pf, err := tag(ft, data)
+		if err != nil {
+			return nil, err
+		}
+		// pointers that may be loaded as nil are inspected as they are, like walk
+		// sets them
+		var fv reflect.Value
+		if v.IsValid() {
+			fv = v.Field(i)
+			if fv.Kind() == reflect.Ptr && (pf == nil || !pf.nilable()) {
+				fv = fv.Elem()
+			}
+		}
+		name := ft.Name
+		if parent != "" {
+			name = parent + "." + ft.Name
+		}
+		if pf != nil {
+			pf.field = ft
+			pf.value = fv
+			pf.name = name
+			p = append(p, pf)
+			continue
+		}
+		et := ft.Type
+		if et.Kind() == reflect.Ptr {
+			et = et.Elem()
+		}
+		if et.Kind() == reflect.Struct {
+			tags, err := inspect(fv, et, data, name)
+			if err != nil {
+				return nil, err
+			}
+			p = append(p, tags...)
+		}
+	}
+	return p, nil
+}
+
 // tag parses the ssm tag from a given field
 func tag(f reflect.StructField, data interface{}) (*field, error) {
 	t := f.Tag.Get("ssm")
+	object := false
+	if t == "" {
+		t, object = f.Tag.Get("s3"), true
+	}
 	if t == "" || t == "-" {
 		return nil, nil
 	}
 	o := strings.Split(t, ",")
 	fld := newField(strings.TrimSpace(o[0]), false)
-	if fld.key == "" {
+	fld.object = object
+	if fld.key == "" || object && !strings.Contains(strings.Trim(fld.key, "/"), "/") {
 		return nil, &TagParseError{Tag: t, Field: f.Name}
 	}
-	tpl, err := template.New(fld.key).Parse(fld.key)
-	if err == nil {
-		b := &bytes.Buffer{}
-		err = tpl.Execute(b, data)
-		if err == nil {
-			fld.key = b.String()
-		}
-	}
+	fld.key = expandKey(fld.key, data)
 	for _, option := range o[1:] {
-		switch strings.TrimSpace(option) {
+		name, value := splitOption(option)
+		switch name {
 		case "decrypt":
 			fld.decrypt = true
 		case "json":
 			fld.json = true
+		case "chunks":
+			fld.chunks = true
+		case "path":
+			// a bare path option loads a map, path= is a JSONPath
+			fld.path, fld.tree = value, !strings.Contains(option, "=")
+		case "dotenv":
+			fld.dotenv = true
+		case "setenv":
+			fld.setenv = true
+		case "refresh":
+			fld.refresh = value
+		case "region":
+			fld.region = value
+		case "old":
+			fld.old = expandKey(value, data)
+		case "rename":
+			fld.rename = value
+		case "group":
+			fld.group = value
+		case "static":
+			fld.static = true
+		case "raw":
+			fld.raw = true
+		case "durfmt":
+			if !isDurationFormat(value) {
+				return nil, &TagParseError{Tag: t, Field: f.Name}
+			}
+			fld.durfmt = value
+		case "oneof":
+			fld.oneof = strings.Split(value, "|")
+		case "min":
+			fld.min = value
+		case "max":
+			fld.max = value
+		case "match":
+			re, err := regexp.Compile(value)
+			if err != nil {
+				return nil, &TagParseError{Tag: t, Field: f.Name}
+			}
+			fld.match = re
+		case "emptyas":
+			fld.emptyas = unquoteOption(value)
+			if fld.emptyas == "" {
+				return nil, &TagParseError{Tag: t, Field: f.Name}
+			}
+		case "nilas":
+			fld.nilas = unquoteOption(value)
+			if fld.nilas == "" || !isNilType(f.Type) {
+				return nil, &TagParseError{Tag: t, Field: f.Name}
+			}
+		default:
+			if isFormat(name) {
+				fld.format = name
+			}
 		}
 	}
+	if fld.path != "" && !fld.json {
+		return nil, &TagParseError{Tag: t, Field: f.Name}
+	}
+	if fld.setenv && !fld.dotenv {
+		return nil, &TagParseError{Tag: t, Field: f.Name}
+	}
+	if fld.object && (fld.decrypt || fld.chunks || fld.region != "") {
+		return nil, &TagParseError{Tag: t, Field: f.Name}
+	}
+	if fld.rename != "" && (fld.old == "" || !isRenamePolicy(fld.rename)) {
+		return nil, &TagParseError{Tag: t, Field: f.Name}
+	}
+	if fld.old != "" && (fld.object || fld.chunks) {
+		return nil, &TagParseError{Tag: t, Field: f.Name}
+	}
+	fld.glob = isGlob(fld.key)
+	if fld.glob && (!isGlobType(f.Type) || fld.tree || fld.object || fld.chunks || fld.dotenv || fld.raw || fld.old != "") {
+		return nil, &TagParseError{Tag: t, Field: f.Name}
+	}
+	if fld.tree && (!isTreeType(f.Type) || fld.object || fld.chunks || fld.dotenv || fld.raw || fld.old != "" || isARN(fld.key)) {
+		return nil, &TagParseError{Tag: t, Field: f.Name}
+	}
+	if fld.raw && (fld.json || fld.dotenv || fld.format != "" || !isRawType(f.Type)) {
+		return nil, &TagParseError{Tag: t, Field: f.Name}
+	}
+	if (fld.min != "" || fld.max != "") && !isNumeric(f.Type) || fld.match != nil && !isString(f.Type) {
+		return nil, &TagParseError{Tag: t, Field: f.Name}
+	}
+	rules := fld.min != "" || fld.max != "" || len(fld.oneof) != 0 || fld.match != nil
+	if rules && (fld.tree || fld.glob || f.Type.Kind() == reflect.Map) {
+		return nil, &TagParseError{Tag: t, Field: f.Name}
+	}
 	return fld, nil
 }
 
+// expandKey performs parameter substitution on a key, leaving it as is when the template fails
+func expandKey(key string, data interface{}) string {
+	tpl, err := template.New(key).Parse(key)
+	if err != nil {
+		return key
+	}
+	b := &bytes.Buffer{}
+	if err := tpl.Execute(b, data); err != nil {
+		return key
+	}
+	return b.String()
+}
+
+// splitOption splits a tag option of the form name=value
+func splitOption(option string) (name, value string) {
+	option = strings.TrimSpace(option)
+	if i := strings.Index(option, "="); i >= 0 {
+		return strings.TrimSpace(option[:i]), strings.TrimSpace(option[i+1:])
+	}
+	return option, ""
+}
+
+// unquoteOption returns the value of an option without the quotes around it, so
+// sentinels such as "-" can be written emptyas="-"
+func unquoteOption(value string) string {
+	if u, err := strconv.Unquote(value); err == nil {
+		return u
+	}
+	return value
+}
+
+// nilable reports whether the field may be loaded with nil, in which case the value
+// of a pointer field is the pointer rather than what it points to
+func (f *field) nilable() bool {
+	return f.emptyas != "" || f.nilas != ""
+}
+
+// isSentinel reports whether s is the value loading the field with its zero value
+func (f *field) isSentinel(s string) bool {
+	return f.emptyas != "" && s == f.emptyas || f.nilas != "" && s == f.nilas
+}
+
+// isNilType reports whether fields of type t can have the nilas option
+func isNilType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map:
+		return true
+	}
+	return false
+}
+
+// isRawType reports whether fields of type t can have the raw option, which assigns
+// the parameter's value as a string or byte slice
+func isRawType(t reflect.Type) bool {
+	return t.Kind() == reflect.String || t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
 // set will attempt to set the underlying value based on the value's type
 func set(f *field, s string) error {
 	v := f.value
 	if !v.CanSet() {
 		return errors.New(v.Type().String() + " cannot be set")
 	}
+	if f.isSentinel(s) {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	if v.Kind() == reflect.Ptr && f.nilable() {
+		n := reflect.New(v.Type().Elem())
+		e := *f
+		e.value = n.Elem()
+		if err := set(&e, s); err != nil {
+			return err
+		}
+		v.Set(n)
+		return nil
+	}
+	if f.raw {
+		if v.Kind() == reflect.String {
+			v.SetString(s)
+		} else {
+			v.SetBytes([]byte(s))
+		}
+		return nil
+	}
+	if d := decoder(v.Type()); d != nil {
+		if f.json {
+			return fmt.Errorf("cannot use 'json' option on a type with a registered decoder: %s %s", f.field.Name, f.field.Type.String())
+		}
+		return setDecoded(v, d, s)
+	}
 	if u := unmarshaler(v); u != nil {
 		if f.json {
 			return fmt.Errorf("cannot use 'json' option on a type with a custom unmarshaller: %s %s", f.field.Name, f.field.Type.String())
 		}
+		if f.format != "" {
+			return fmt.Errorf("cannot use '%s' option on a type with a custom unmarshaller: %s %s", f.format, f.field.Name, f.field.Type.String())
+		}
 		return u.UnmarshalParameter(s)
 	}
+	if f.format != "" {
+		return setFormat(f, s)
+	}
+	if f.dotenv {
+		return setDotenv(f, s)
+	}
+	if v.Type() == rawType {
+		if f.json && !json.Valid([]byte(s)) {
+			return fmt.Errorf("json unmarshal error for field '%s'", f.field.Name)
+		}
+		v.SetBytes([]byte(s))
+		return nil
+	}
 	if f.json {
 		return setJSON(f, s)
 	}
+	// types such as big.Int and big.Float know how to decode themselves from text
+	if u := textUnmarshaler(v); u != nil {
+		if err := u.UnmarshalText([]byte(s)); err != nil {
+			return &ConvertTypeError{
+				Type:  v.Type().String(),
+				Value: s,
+			}
+		}
+		return nil
+	}
 	// special case with time.Duration and assignable types
 	if v.Type().AssignableTo(durationType) {
-		if p, err := time.ParseDuration(s); err == nil {
-			v.Set(reflect.ValueOf(p))
-			return nil
+		d, err := parseDuration(s, f.durfmt)
+		if err != nil {
+			return &ConvertTypeError{
+				Type:  v.Type().String(),
+				Value: s,
+			}
 		}
+		v.Set(reflect.ValueOf(d))
+		return nil
 	}
 	switch v.Kind() {
 	// handles the case data types are wrapped in other constructs, EG slices
 	case reflect.Ptr:
 		// create new pointer to a zero value
 		new := reflect.New(v.Type().Elem())
-		set(&field{value: new.Elem()}, s)
+		set(&field{value: new.Elem(), durfmt: f.durfmt}, s)
 		// assign new pointer
 		v.Set(new)
 		break
@@ -332,7 +1129,7 @@ func set(f *field, s string) error {
 		sz := len(l)
 		v.Set(reflect.MakeSlice(v.Type(), sz, sz))
 		for i, w := range l {
-			set(&field{value: v.Index(i)}, w)
+			set(&field{value: v.Index(i), durfmt: f.durfmt}, w)
 		}
 		break
 	case reflect.String:
@@ -378,6 +1175,16 @@ func set(f *field, s string) error {
 		}
 		v.SetFloat(n)
 		break
+	case reflect.Complex64, reflect.Complex128:
+		n, err := strconv.ParseComplex(s, v.Type().Bits())
+		if err != nil || v.OverflowComplex(n) {
+			return &ConvertTypeError{
+				Type:  v.Type().String(),
+				Value: s,
+			}
+		}
+		v.SetComplex(n)
+		break
 	}
 	return nil
 }
@@ -397,19 +1204,55 @@ func unmarshaler(v reflect.Value) Unmarshaler {
 	return nil
 }
 
-func setJSON(f *field, s string) error {
-	v := f.value
-	if v.Kind() != reflect.Ptr {
-		if !v.CanAddr() {
-			return fmt.Errorf("%s is not addressable", v.Type().String())
-		}
+func setDecoded(v reflect.Value, d DecoderFunc, s string) error {
+	x, err := d(s)
+	if err != nil {
+		return err
+	}
+	if x == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	xv := reflect.ValueOf(x)
+	if !xv.Type().AssignableTo(v.Type()) {
+		return fmt.Errorf("decoder returned %s, which is not assignable to %s", xv.Type().String(), v.Type().String())
+	}
+	v.Set(xv)
+	return nil
+}
+
+func textUnmarshaler(v reflect.Value) encoding.TextUnmarshaler {
+	if v.Kind() != reflect.Ptr && v.Type().Name() != "" && v.CanAddr() {
 		v = v.Addr()
 	}
-	if !v.CanInterface() {
-		return fmt.Errorf("%s is not interfaceable", v.Type().String())
+	// a nil pointer can't be unmarshaled into, let set allocate it first
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil
+	}
+	if v.Type().NumMethod() > 0 && v.CanInterface() {
+		if u, ok := v.Interface().(encoding.TextUnmarshaler); ok {
+			return u
+		}
+	}
+	return nil
+}
+
+func setJSON(f *field, s string) error {
+	return setUnmarshal(f, s, "json", json.Unmarshal)
+}
+
+// setUnmarshal decodes a value in the named format into the field.  The value is
+// decoded into a new value that replaces the field's, so loading again doesn't merge
+// into maps or keep members missing from the document.
+func setUnmarshal(f *field, s string, format string, unmarshal func([]byte, interface{}) error) error {
+	v := f.value
+	if !v.CanSet() {
+		return fmt.Errorf("%s cannot be set", v.Type().String())
 	}
-	if err := json.Unmarshal([]byte(s), v.Interface()); err != nil {
-		return fmt.Errorf("json unmarshal error for field '%s'", f.field.Name)
+	fresh := reflect.New(v.Type())
+	if err := unmarshal([]byte(s), fresh.Interface()); err != nil {
+		return fmt.Errorf("%s unmarshal error for field '%s'", format, f.field.Name)
 	}
+	v.Set(fresh.Elem())
 	return nil
 }
@@ -1,14 +1,20 @@
 package figgy
 
 import (
+	"encoding/json"
 	"fmt"
+	"math/big"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/ssm"
 	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
 )
@@ -19,8 +25,11 @@ type MockSSMClient struct {
 }
 
 func (c MockSSMClient) GetParameter(i *ssm.GetParameterInput) (*ssm.GetParameterOutput, error) {
-	//TODO: Lookup key and mimic more closely how the aws sdk works, no key causes a panic
-	return c.Data[*i.Name], nil
+	p, ok := c.Data[aws.StringValue(i.Name)]
+	if !ok {
+		return nil, awserr.New(ssm.ErrCodeParameterNotFound, "parameter not found", nil)
+	}
+	return p, nil
 }
 
 func (c MockSSMClient) GetParameters(i *ssm.GetParametersInput) (*ssm.GetParametersOutput, error) {
@@ -39,6 +48,84 @@ func (c MockSSMClient) GetParameters(i *ssm.GetParametersInput) (*ssm.GetParamet
 	return out, nil
 }
 
+func (c MockSSMClient) PutParameter(i *ssm.PutParameterInput) (*ssm.PutParameterOutput, error) {
+	name := aws.StringValue(i.Name)
+	version := int64(1)
+	if p, ok := c.Data[name]; ok {
+		if !aws.BoolValue(i.Overwrite) {
+			return nil, awserr.New(ssm.ErrCodeParameterAlreadyExists, "parameter already exists", nil)
+		}
+		version = aws.Int64Value(p.Parameter.Version) + 1
+	}
+	c.Data[name] = &ssm.GetParameterOutput{
+		Parameter: &ssm.Parameter{
+			Name:    i.Name,
+			Type:    i.Type,
+			Value:   i.Value,
+			Version: aws.Int64(version),
+		},
+	}
+	return &ssm.PutParameterOutput{Version: aws.Int64(version)}, nil
+}
+
+func (c MockSSMClient) DeleteParameters(i *ssm.DeleteParametersInput) (*ssm.DeleteParametersOutput, error) {
+	var out = new(ssm.DeleteParametersOutput)
+	if len(i.Names) > maxParameters {
+		return nil, fmt.Errorf("max parameters exceeded: received %d, max %d", len(i.Names), maxParameters)
+	}
+	for _, n := range i.Names {
+		if _, ok := c.Data[aws.StringValue(n)]; !ok {
+			out.InvalidParameters = append(out.InvalidParameters, n)
+			continue
+		}
+		delete(c.Data, aws.StringValue(n))
+		out.DeletedParameters = append(out.DeletedParameters, n)
+	}
+	return out, nil
+}
+
+func (c MockSSMClient) DescribeParameters(i *ssm.DescribeParametersInput) (*ssm.DescribeParametersOutput, error) {
+	var out = new(ssm.DescribeParametersOutput)
+	for name, p := range c.Data {
+		for _, f := range i.ParameterFilters {
+			for _, v := range f.Values {
+				if name == aws.StringValue(v) || aws.StringValue(f.Option) == "BeginsWith" && strings.HasPrefix(name, aws.StringValue(v)) {
+					out.Parameters = append(out.Parameters, &ssm.ParameterMetadata{
+						Name:    p.Parameter.Name,
+						Version: p.Parameter.Version,
+					})
+				}
+			}
+		}
+	}
+	return out, nil
+}
+
+// GetParametersByPath returns the parameters under the path, a page of MaxResults at a time
+func (c MockSSMClient) GetParametersByPath(i *ssm.GetParametersByPathInput) (*ssm.GetParametersByPathOutput, error) {
+	var names []string
+	for name := range c.Data {
+		if strings.HasPrefix(name, aws.StringValue(i.Path)+"/") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	start, _ := strconv.Atoi(aws.StringValue(i.NextToken))
+	size := int(aws.Int64Value(i.MaxResults))
+	if size == 0 {
+		size = 10
+	}
+	out := new(ssm.GetParametersByPathOutput)
+	for _, name := range names[start:] {
+		if len(out.Parameters) == size {
+			out.NextToken = aws.String(strconv.Itoa(start + size))
+			break
+		}
+		out.Parameters = append(out.Parameters, c.Data[name].Parameter)
+	}
+	return out, nil
+}
+
 func NewMockSSMClient() *MockSSMClient {
 	m := &MockSSMClient{}
 	m.Data = map[string]*ssm.GetParameterOutput{
@@ -301,6 +388,27 @@ func NewMockSSMClient() *MockSSMClient {
 				Value: aws.String(`[{"F1": 1, "F2": "2"}]`),
 			},
 		},
+		"complex": {
+			Parameter: &ssm.Parameter{
+				Name:  aws.String("complex"),
+				Type:  aws.String("string"),
+				Value: aws.String("1+2i"),
+			},
+		},
+		"bigint": {
+			Parameter: &ssm.Parameter{
+				Name:  aws.String("bigint"),
+				Type:  aws.String("string"),
+				Value: aws.String("123456789012345678901234567890"),
+			},
+		},
+		"bigfloat": {
+			Parameter: &ssm.Parameter{
+				Name:  aws.String("bigfloat"),
+				Type:  aws.String("string"),
+				Value: aws.String("1.5"),
+			},
+		},
 		"badjson": {
 			Parameter: &ssm.Parameter{
 				Name:  aws.String("badjson"),
@@ -312,6 +420,21 @@ func NewMockSSMClient() *MockSSMClient {
 	return m
 }
 
+// NewMockSSMClientWith returns the mock client with additional string parameters
+func NewMockSSMClientWith(values map[string]string) *MockSSMClient {
+	m := NewMockSSMClient()
+	for k, v := range values {
+		m.Data[k] = &ssm.GetParameterOutput{
+			Parameter: &ssm.Parameter{
+				Name:  aws.String(k),
+				Type:  aws.String("string"),
+				Value: aws.String(v),
+			},
+		}
+	}
+	return m
+}
+
 func NewTypes() *Types {
 	return &Types{
 		unexported: 100,
@@ -333,7 +456,7 @@ type Types struct {
 	Uintptr        uintptr       `ssm:"uintptr"`
 	Float32        float32       `ssm:"float32"`
 	Float64        float64       `ssm:"float64"`
-	Duration       time.Duration `ssm:"duration"`
+	Duration       time.Duration `ssm:"duration,durfmt=ns"`
 	DurationString time.Duration `ssm:"durationstring"`
 
 	//UintptrStr uintptr
@@ -442,6 +565,63 @@ func TestUnmarshalIface(t *testing.T) {
 	}
 }
 
+type thirdParty struct {
+	Value string
+}
+
+func TestRegisterDecoder(t *testing.T) {
+	tp := reflect.TypeOf(thirdParty{})
+	RegisterDecoder(tp, func(s string) (interface{}, error) {
+		if s == "invalid" {
+			return nil, fmt.Errorf("invalid third party value")
+		}
+		return thirdParty{Value: "decoded-" + s}, nil
+	})
+	defer RegisterDecoder(tp, nil)
+
+	var c struct {
+		Value  thirdParty    `ssm:"string"`
+		PValue *thirdParty   `ssm:"string"`
+		Slice  []thirdParty  `ssm:"sliceint"`
+		SliceP []*thirdParty `ssm:"sliceint"`
+	}
+	err := Load(NewMockSSMClient(), &c)
+	assert.NoError(t, err)
+	assert.Equal(t, "decoded-this is a string", c.Value.Value)
+	assert.NotNil(t, c.PValue)
+	assert.Equal(t, "decoded-this is a string", c.PValue.Value)
+	assert.Len(t, c.Slice, 5)
+	assert.Equal(t, "decoded-1", c.Slice[0].Value)
+	assert.Len(t, c.SliceP, 5)
+	assert.Equal(t, "decoded-5", c.SliceP[4].Value)
+
+	var e struct {
+		Value thirdParty `ssm:"badjson"`
+	}
+	err = Load(NewMockSSMClient(), &e)
+	assert.EqualError(t, err, "invalid third party value")
+
+	var j struct {
+		Value thirdParty `ssm:"simplejson,json"`
+	}
+	err = Load(NewMockSSMClient(), &j)
+	assert.Error(t, err)
+}
+
+func TestRegisterDecoderNotAssignable(t *testing.T) {
+	tp := reflect.TypeOf(thirdParty{})
+	RegisterDecoder(tp, func(s string) (interface{}, error) {
+		return s, nil
+	})
+	defer RegisterDecoder(tp, nil)
+
+	var c struct {
+		Value thirdParty `ssm:"string"`
+	}
+	err := Load(NewMockSSMClient(), &c)
+	assert.Error(t, err)
+}
+
 func TestTypeConvertErrors(t *testing.T) {
 	tests := map[string]struct {
 		in   interface{}
@@ -462,6 +642,12 @@ func TestTypeConvertErrors(t *testing.T) {
 		"invalid time.Duration convert": {in: &struct {
 			Duration time.Duration `ssm:"string"`
 		}{}, want: &ConvertTypeError{Field: "Duration", Type: "time.Duration", Value: "this is a string"}},
+		"invalid complex convert": {in: &struct {
+			Complex complex128 `ssm:"string"`
+		}{}, want: &ConvertTypeError{Field: "Complex", Type: "complex128", Value: "this is a string"}},
+		"invalid big.Int convert": {in: &struct {
+			BigInt big.Int `ssm:"string"`
+		}{}, want: &ConvertTypeError{Field: "BigInt", Type: "big.Int", Value: "this is a string"}},
 	}
 
 	for n, tc := range tests {
@@ -470,12 +656,41 @@ func TestTypeConvertErrors(t *testing.T) {
 	}
 }
 
+func TestBigAndComplex(t *testing.T) {
+	var c struct {
+		Complex64  complex64  `ssm:"complex"`
+		Complex128 complex128 `ssm:"complex"`
+		BigInt     big.Int    `ssm:"bigint"`
+		PBigInt    *big.Int   `ssm:"bigint"`
+		BigFloat   big.Float  `ssm:"bigfloat"`
+	}
+	err := Load(NewMockSSMClient(), &c)
+	assert.NoError(t, err)
+	assert.Equal(t, complex64(1+2i), c.Complex64)
+	assert.Equal(t, 1+2i, c.Complex128)
+	assert.Equal(t, "123456789012345678901234567890", c.BigInt.String())
+	assert.NotNil(t, c.PBigInt)
+	assert.Equal(t, "123456789012345678901234567890", c.PBigInt.String())
+	assert.Equal(t, "1.5", c.BigFloat.String())
+}
+
 func TestInvalidParams(t *testing.T) {
 	var c struct {
 		Invalid string `ssm:"/no/such/param"`
 	}
 	err := Load(NewMockSSMClient(), &c)
 	assert.Error(t, err)
+
+	m := NewMockSSMClientWith(map[string]string{"/app/db_host": "h"})
+	var d struct {
+		Host string `ssm:"/app/db-host"`
+		Port int    `ssm:"/other/port"`
+	}
+	err = Load(m, &d)
+	if e, ok := err.(*InvalidParametersError); assert.True(t, ok, "%v", err) {
+		assert.Equal(t, []string{"/app/db-host", "/other/port"}, e.Names)
+		assert.Equal(t, map[string]string{"/app/db-host": "/app/db_host"}, e.Suggestions)
+	}
 }
 
 func TestMixedPlainAndDecryptParams(t *testing.T) {
@@ -521,6 +736,37 @@ func TestJSON(t *testing.T) {
 	assert.Equal(t, s, j.AJSON[0])
 }
 
+func TestLoadJSONParameter(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{
+		"/app/config": `{"Host": "localhost", "Port": 80, "Nested": {"String": "json"}, "PNested": {"String": "pjson"}}`,
+	})
+	var c struct {
+		Host    string
+		Port    int `ssm:"int"`
+		Nested  Nested
+		PNested *Nested
+	}
+	err := LoadJSONParameter(m, "/app/config", &c)
+	assert.NoError(t, err)
+	assert.Equal(t, "localhost", c.Host)
+	assert.Equal(t, 2, c.Port)
+	assert.Equal(t, "this is a string", c.Nested.String)
+	assert.Equal(t, "this is a string", c.PNested.String)
+	assert.Equal(t, "this is a ptr to a string", *c.PNested.PString)
+}
+
+func TestLoadJSONParameterErrors(t *testing.T) {
+	var c struct {
+		Host string
+	}
+	err := LoadJSONParameter(NewMockSSMClient(), "badjson", &c)
+	assert.Error(t, err)
+	err = LoadJSONParameter(NewMockSSMClient(), "/no/such/param", &c)
+	assert.Error(t, err)
+	err = LoadJSONParameter(NewMockSSMClient(), "simplejson", c)
+	assert.Error(t, err)
+}
+
 func TestJSONError(t *testing.T) {
 	var j struct {
 		SimpleJSON `ssm:"badjson,json"`
@@ -529,6 +775,30 @@ func TestJSONError(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestRaw(t *testing.T) {
+	var r struct {
+		Raw      Raw             `ssm:"simplejson"`
+		Message  json.RawMessage `ssm:"simplejson,json"`
+		PMessage *Raw            `ssm:"simplejson,json"`
+		String   Raw             `ssm:"string"`
+	}
+	err := Load(NewMockSSMClient(), &r)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"F1": 1, "F2": "2"}`, string(r.Raw))
+	assert.Equal(t, `{"F1": 1, "F2": "2"}`, string(r.Message))
+	assert.NotNil(t, r.PMessage)
+	assert.Equal(t, `{"F1": 1, "F2": "2"}`, string(*r.PMessage))
+	assert.Equal(t, "this is a string", string(r.String))
+}
+
+func TestRawJSONError(t *testing.T) {
+	var r struct {
+		Raw Raw `ssm:"badjson,json"`
+	}
+	err := Load(NewMockSSMClient(), &r)
+	assert.Error(t, err)
+}
+
 func TestJSONWithUnmarshallerError(t *testing.T) {
 	var j struct {
 		Test str `ssm:"string,json"`
@@ -566,6 +836,12 @@ func TestTagParse(t *testing.T) {
 		"with json": {in: struct {
 			Field string `ssm:"simplejson,json"`
 		}{}, want: &field{key: "simplejson", json: true}, err: nil},
+		"with path": {in: struct {
+			Field string `ssm:"simplejson,json,path=$.F2"`
+		}{}, want: &field{key: "simplejson", json: true, path: "$.F2"}, err: nil},
+		"path without json": {in: struct {
+			Field string `ssm:"simplejson,path=$.F2"`
+		}{}, want: nil, err: &TagParseError{Tag: "simplejson,path=$.F2", Field: "Field"}},
 	}
 
 	for n, tc := range tests {
@@ -574,6 +850,7 @@ func TestTagParse(t *testing.T) {
 		if tc.want != nil {
 			assert.Equalf(t, tc.want.key, tag.key, "keys are do not match for test %s", n)
 			assert.Equalf(t, tc.want.decrypt, tag.decrypt, "decrypt flag does not match for test %s", n)
+			assert.Equalf(t, tc.want.path, tag.path, "path does not match for test %s", n)
 		}
 		if err != nil {
 			assert.EqualError(t, err, tc.err.Error())
@@ -628,3 +905,392 @@ func makePartitionFields(x []bool) []*field {
 	}
 	return f
 }
+
+func TestBatchIterateFields(t *testing.T) {
+	var f []*field
+	for _, k := range []string{"a", "b", "a", "c", "b", "d", "e"} {
+		f = append(f, &field{key: k})
+	}
+	var batches [][]*field
+	err := batchIterateFields(groupFields(f), 2, func(b []*field) error {
+		batches = append(batches, b)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, batches, 3)
+	assert.Len(t, batches[0], 4)
+	assert.Len(t, parameterNames(batches[0]), 2)
+	assert.Len(t, parameterNames(batches[1]), 2)
+	assert.Len(t, parameterNames(batches[2]), 1)
+}
+
+// arnSSMClient answers requests for parameters by ARN with their name, as Parameter
+// Store does
+type arnSSMClient struct {
+	*MockSSMClient
+}
+
+func (c arnSSMClient) GetParameters(i *ssm.GetParametersInput) (*ssm.GetParametersOutput, error) {
+	out := new(ssm.GetParametersOutput)
+	for _, n := range i.Names {
+		name := aws.StringValue(n)
+		if j := strings.Index(name, ":parameter"); j >= 0 {
+			name = name[j+len(":parameter"):]
+		}
+		p, ok := c.Data[name]
+		if !ok {
+			out.InvalidParameters = append(out.InvalidParameters, n)
+			continue
+		}
+		param := *p.Parameter
+		param.ARN = aws.String("arn:aws:ssm:us-east-1:123456789012:parameter" + name)
+		out.Parameters = append(out.Parameters, &param)
+	}
+	return out, nil
+}
+
+func TestLoadARN(t *testing.T) {
+	c := arnSSMClient{NewMockSSMClientWith(map[string]string{"/app/host": "a", "/shared/name": "b"})}
+	var cfg struct {
+		Host   string `ssm:"/app/host"`
+		Shared string `ssm:"arn:aws:ssm:us-east-1:123456789012:parameter/shared/name"`
+		Again  string `ssm:"arn:aws:ssm:us-east-1:123456789012:parameter/app/host"`
+	}
+	assert.NoError(t, Load(c, &cfg))
+	assert.Equal(t, "a", cfg.Host)
+	assert.Equal(t, "b", cfg.Shared)
+	assert.Equal(t, "a", cfg.Again)
+
+	var missing struct {
+		Missing string `ssm:"arn:aws:ssm:us-east-1:123456789012:parameter/missing"`
+	}
+	assert.EqualError(t, Load(c, &missing), "invalid parameters: arn:aws:ssm:us-east-1:123456789012:parameter/missing")
+}
+
+// ramSSMClient answers requests for the parameters another account shares through
+// AWS RAM, which are named by their ARN, along with the account's own
+type ramSSMClient struct {
+	*MockSSMClient
+	shared map[string]string
+}
+
+func (c ramSSMClient) GetParameters(i *ssm.GetParametersInput) (*ssm.GetParametersOutput, error) {
+	var local []*string
+	out := new(ssm.GetParametersOutput)
+	for _, n := range i.Names {
+		v, ok := c.shared[aws.StringValue(n)]
+		if !ok {
+			local = append(local, n)
+			continue
+		}
+		out.Parameters = append(out.Parameters, &ssm.Parameter{Name: n, ARN: n, Value: aws.String(v), Type: aws.String(ssm.ParameterTypeString)})
+	}
+	if len(local) > 0 {
+		res, err := c.MockSSMClient.GetParameters(&ssm.GetParametersInput{Names: local, WithDecryption: i.WithDecryption})
+		if err != nil {
+			return nil, err
+		}
+		out.Parameters = append(out.Parameters, res.Parameters...)
+		out.InvalidParameters = res.InvalidParameters
+	}
+	return out, nil
+}
+
+func TestLoadSharedARN(t *testing.T) {
+	c := ramSSMClient{
+		MockSSMClient: NewMockSSMClientWith(map[string]string{"/shared/host": "local"}),
+		shared:        map[string]string{"arn:aws:ssm:us-east-1:210987654321:parameter/shared/host": "shared"},
+	}
+	var cfg struct {
+		Local  string `ssm:"/shared/host"`
+		Shared string `ssm:"arn:aws:ssm:{{.region}}:{{.owner}}:parameter/shared/host"`
+	}
+	assert.NoError(t, LoadWithParameters(c, &cfg, P{"region": "us-east-1", "owner": "210987654321"}))
+	assert.Equal(t, "local", cfg.Local)
+	assert.Equal(t, "shared", cfg.Shared)
+
+	err := LoadWithParameters(c, &cfg, P{"region": "us-east-1", "owner": "999999999999"})
+	assert.EqualError(t, err, "invalid parameters: arn:aws:ssm:us-east-1:999999999999:parameter/shared/host")
+	assert.Equal(t, "local", cfg.Local)
+}
+
+func TestLoadNilPointers(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/host": "h", "/app/port": "1"})
+	type optional struct {
+		Enabled bool
+	}
+	var cfg struct {
+		Host   *string `ssm:"/app/host"`
+		Nested *struct {
+			Port int `ssm:"/app/port"`
+		}
+		Optional *optional
+		Count    *int
+	}
+	assert.NoError(t, Load(m, &cfg))
+	assert.Equal(t, "h", *cfg.Host)
+	assert.Equal(t, 1, cfg.Nested.Port)
+	// pointers that don't lead to tagged fields are left nil
+	assert.Nil(t, cfg.Optional)
+	assert.Nil(t, cfg.Count)
+
+	cfg.Optional = &optional{Enabled: true}
+	assert.NoError(t, Load(m, &cfg))
+	assert.True(t, cfg.Optional.Enabled)
+}
+
+func TestLoadEmptyAs(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/host": "-", "/app/port": "-", "/app/name": "n"})
+	var cfg struct {
+		Host string  `ssm:"/app/host,emptyas=\"-\""`
+		Port *int    `ssm:"/app/port,emptyas=-"`
+		Name *string `ssm:"/app/name,emptyas=-"`
+	}
+	cfg.Host = "h"
+	assert.NoError(t, Load(m, &cfg))
+	assert.Equal(t, "", cfg.Host)
+	assert.Nil(t, cfg.Port)
+	assert.Equal(t, "n", *cfg.Name)
+
+	// watchers move pointers between nil and a value
+	n := make(chanNotifier)
+	w, err := Watch(m, &cfg, nil, n)
+	assert.NoError(t, err)
+	defer w.Stop()
+	setParameter(m, "/app/port", "80")
+	setParameter(m, "/app/name", "-")
+	n <- []string{"/app/port", "/app/name"}
+	assert.True(t, waitChange(t, w))
+	w.RLock()
+	assert.Equal(t, 80, *cfg.Port)
+	assert.Nil(t, cfg.Name)
+	w.RUnlock()
+
+	var bad struct {
+		Host string `ssm:"/app/host,emptyas="`
+	}
+	assert.IsType(t, &TagParseError{}, Load(m, &bad))
+}
+
+func TestLoadNilAs(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/limit": "unset", "/app/retries": "0", "/app/hosts": "unset"})
+	var cfg struct {
+		Limit   *int     `ssm:"/app/limit,nilas=unset"`
+		Retries *int     `ssm:"/app/retries,nilas=unset"`
+		Hosts   []string `ssm:"/app/hosts,nilas=unset"`
+	}
+	cfg.Hosts = []string{"a"}
+	assert.NoError(t, Load(m, &cfg))
+	assert.Nil(t, cfg.Limit)
+	assert.Equal(t, 0, *cfg.Retries)
+	assert.Nil(t, cfg.Hosts)
+
+	var bad struct {
+		Limit int `ssm:"/app/limit,nilas=unset"`
+	}
+	assert.IsType(t, &TagParseError{}, Load(m, &bad))
+}
+
+type EmbeddedBase struct {
+	Host string `ssm:"/app/host"`
+}
+
+type embeddedBase struct {
+	Port int `ssm:"/app/port"`
+}
+
+type EmbeddedPointer struct {
+	Region string `ssm:"/app/region"`
+}
+
+type EmbeddedDoc struct {
+	Host string `ssm:"/ignored/host"`
+	Port int
+}
+
+func TestLoadEmbedded(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{
+		"/app/host":   "h",
+		"/app/port":   "1",
+		"/app/region": "r",
+		"/app/doc":    `{"Host": "doc", "Port": 2}`,
+	})
+	var cfg struct {
+		EmbeddedBase
+		embeddedBase
+		*EmbeddedPointer
+		Doc struct {
+			EmbeddedDoc `ssm:"/app/doc,json"`
+		}
+		Skipped struct {
+			EmbeddedBase `ssm:"-"`
+		}
+	}
+	assert.NoError(t, Load(m, &cfg))
+	assert.Equal(t, "h", cfg.Host)
+	assert.Equal(t, 1, cfg.Port)
+	assert.Equal(t, "r", cfg.Region)
+	// a tagged struct is loaded whole, and the tags of its fields are ignored
+	assert.Equal(t, EmbeddedDoc{Host: "doc", Port: 2}, cfg.Doc.EmbeddedDoc)
+	assert.Equal(t, "", cfg.Skipped.Host)
+}
+
+type EmbeddedChain struct {
+	*EmbeddedPointer
+}
+
+func TestLoadPointerEmbedded(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/region": "r"})
+	var cfg struct {
+		*EmbeddedChain
+	}
+	assert.NoError(t, Load(m, &cfg))
+	assert.Equal(t, "r", cfg.Region)
+
+	// fields behind nil embedded pointers are described with their path
+	f, err := Describe(&struct{ *EmbeddedChain }{}, nil)
+	if assert.NoError(t, err) && assert.Len(t, f, 1) {
+		assert.Equal(t, "EmbeddedChain.EmbeddedPointer.Region", f[0].Path)
+	}
+
+	// watchers reload them
+	n := make(chanNotifier)
+	w, err := Watch(m, &cfg, nil, n)
+	assert.NoError(t, err)
+	defer w.Stop()
+	setParameter(m, "/app/region", "r2")
+	n <- []string{"/app/region"}
+	assert.True(t, waitChange(t, w))
+	w.RLock()
+	assert.Equal(t, "r2", cfg.Region)
+	w.RUnlock()
+}
+
+func TestLoadAgain(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{
+		"/app/hosts":    "a,b,c",
+		"/app/settings": `{"a":"1","b":"2"}`,
+		"/app/limits":   `{"Max":10,"Min":1}`,
+	})
+	type limits struct{ Max, Min int }
+	var c struct {
+		Hosts    []string          `ssm:"/app/hosts"`
+		Settings map[string]string `ssm:"/app/settings,json"`
+		Limits   *limits           `ssm:"/app/limits,json"`
+	}
+	assert.NoError(t, Load(m, &c))
+	setParameter(m, "/app/hosts", "d")
+	setParameter(m, "/app/settings", `{"c":"3"}`)
+	setParameter(m, "/app/limits", `{"Max":5}`)
+	assert.NoError(t, Load(m, &c))
+	assert.Equal(t, []string{"d"}, c.Hosts)
+	assert.Equal(t, map[string]string{"c": "3"}, c.Settings)
+	assert.Equal(t, &limits{Max: 5}, c.Limits)
+}
+
+func TestReloadMissing(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/host": "a", "/app/port": "1", "/app/password": "p"})
+	var c struct {
+		Host     string `ssm:"/app/host"`
+		Port     int    `ssm:"/app/port"`
+		Password string `ssm:"/app/password,decrypt"`
+	}
+	assert.NoError(t, Load(m, &c))
+	delete(m.Data, "/app/port")
+	delete(m.Data, "/app/password")
+	assert.EqualError(t, Load(m, &c), "invalid parameters: /app/port, /app/password")
+	assert.NoError(t, Reload(m, &c))
+	assert.Equal(t, "a", c.Host)
+	assert.Equal(t, 0, c.Port)
+	assert.Equal(t, "", c.Password)
+
+	// missing parameters are also reset when loading by path
+	m = NewMockSSMClientWith(map[string]string{"/app/host": "a", "/app/port": "1", "/app/password": "p"})
+	assert.NoError(t, Load(m, &c))
+	delete(m.Data, "/app/port")
+	assert.NoError(t, Reload(m, &c, WithPathThreshold(1)))
+	assert.Equal(t, 0, c.Port)
+	assert.Equal(t, "a", c.Host)
+}
+
+func TestLoadGroup(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/db/host": "h", "/db/password": "p", "/flags/beta": "true"})
+	var c struct {
+		Host     string `ssm:"/db/host,group=db"`
+		Password string `ssm:"/db/password,decrypt,group=db"`
+		Beta     bool   `ssm:"/flags/beta,group=flags"`
+		Other    string `ssm:"/other"`
+	}
+	assert.NoError(t, LoadGroup(m, &c, "flags"))
+	assert.True(t, c.Beta)
+	assert.Equal(t, "", c.Host)
+	assert.NoError(t, LoadGroup(m, &c, "db"))
+	assert.Equal(t, "h", c.Host)
+	assert.Equal(t, "p", c.Password)
+	assert.Equal(t, "", c.Other)
+	assert.NoError(t, LoadGroup(m, &c, "none"))
+}
+
+func TestLoadSharedDecryptKey(t *testing.T) {
+	c := &countingSSMClient{MockSSMClient: NewMockSSMClientWith(map[string]string{"/app/doc": `{"host":"h"}`, "/app/port": "1"})}
+	var cfg struct {
+		Doc  string `ssm:"/app/doc"`
+		Host string `ssm:"/app/doc,decrypt,json,path=$.host"`
+		Port string `ssm:"/app/port"`
+	}
+	assert.NoError(t, Load(c, &cfg, WithBatchSize(1)))
+	assert.Equal(t, `{"host":"h"}`, cfg.Doc)
+	assert.Equal(t, "h", cfg.Host)
+	// the shared parameter is requested once, with decryption
+	assert.Equal(t, []int{1, 1}, c.batches)
+	assert.Equal(t, []bool{false, true}, c.decrypt)
+}
+
+func TestLoadRaw(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/timeout": "300s", "/app/doc": `{"a":1}`})
+	var cfg struct {
+		Timeout string `ssm:"/app/timeout,raw"`
+		Custom  str    `ssm:"/app/timeout,raw"`
+		Doc     []byte `ssm:"/app/doc,raw"`
+	}
+	assert.NoError(t, Load(m, &cfg))
+	assert.Equal(t, "300s", cfg.Timeout)
+	// custom unmarshalers are skipped too
+	assert.Equal(t, str("300s"), cfg.Custom)
+	assert.Equal(t, []byte(`{"a":1}`), cfg.Doc)
+
+	var duration struct {
+		Timeout time.Duration `ssm:"/app/timeout,raw"`
+	}
+	assert.IsType(t, &TagParseError{}, Load(m, &duration))
+	var decoded struct {
+		Doc map[string]int `ssm:"/app/doc,raw,json"`
+	}
+	assert.IsType(t, &TagParseError{}, Load(m, &decoded))
+}
+
+func TestLoadTagConflict(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/doc": `{"host":"h"}`})
+	var ok struct {
+		Doc  string `ssm:"/app/doc,decrypt"`
+		Host string `ssm:"/app/doc,decrypt,json,path=$.host"`
+	}
+	assert.NoError(t, Load(m, &ok))
+	assert.Equal(t, "h", ok.Host)
+
+	var chunks struct {
+		Doc   string `ssm:"/app/doc"`
+		Parts string `ssm:"/app/doc,chunks"`
+	}
+	err := Load(m, &chunks)
+	assert.Equal(t, &TagConflictError{Key: "/app/doc", Fields: []string{"Doc", "Parts"}}, err)
+	assert.EqualError(t, err, "conflicting tags for key '/app/doc' on fields Doc, Parts")
+
+	// the same key in another region is another parameter
+	var region struct {
+		Doc   string `ssm:"/app/doc"`
+		Other string `ssm:"/app/doc,decrypt,region=us-west-2"`
+	}
+	_, isConflict := Load(m, &region).(*TagConflictError)
+	assert.False(t, isConflict)
+}
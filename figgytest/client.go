@@ -0,0 +1,125 @@
+package figgytest
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// Client is an in-memory Parameter Store client, for unit tests that don't need a
+// Server.  It supports the same requests as a Server.
+type Client struct {
+	ssmiface.SSMAPI
+	*store
+}
+
+// NewClient returns a client with no parameters
+func NewClient() *Client {
+	return &Client{store: newStore()}
+}
+
+// NewClientFromFixture returns a client with the parameters of the fixture file at
+// path, as read by ReadFixture
+func NewClientFromFixture(path string) (*Client, error) {
+	c := NewClient()
+	if err := c.LoadFixture(path); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Client) GetParameter(in *ssm.GetParameterInput) (*ssm.GetParameterOutput, error) {
+	params, _ := c.get([]string{aws.StringValue(in.Name)}, aws.BoolValue(in.WithDecryption))
+	if len(params) == 0 {
+		return nil, awserr.New(ssm.ErrCodeParameterNotFound, "parameter not found", nil)
+	}
+	return &ssm.GetParameterOutput{Parameter: ssmParameter(params[0])}, nil
+}
+
+func (c *Client) GetParameterWithContext(ctx aws.Context, in *ssm.GetParameterInput, opts ...request.Option) (*ssm.GetParameterOutput, error) {
+	return c.GetParameter(in)
+}
+
+func (c *Client) GetParameters(in *ssm.GetParametersInput) (*ssm.GetParametersOutput, error) {
+	if len(in.Names) > maxParameters {
+		return nil, awserr.New("ValidationException", fmt.Sprintf("at most %d names can be requested", maxParameters), nil)
+	}
+	params, invalid := c.get(aws.StringValueSlice(in.Names), aws.BoolValue(in.WithDecryption))
+	out := &ssm.GetParametersOutput{InvalidParameters: aws.StringSlice(invalid)}
+	for _, p := range params {
+		out.Parameters = append(out.Parameters, ssmParameter(p))
+	}
+	return out, nil
+}
+
+func (c *Client) GetParametersWithContext(ctx aws.Context, in *ssm.GetParametersInput, opts ...request.Option) (*ssm.GetParametersOutput, error) {
+	return c.GetParameters(in)
+}
+
+func (c *Client) GetParametersByPath(in *ssm.GetParametersByPathInput) (*ssm.GetParametersByPathOutput, error) {
+	params, next := c.byPath(aws.StringValue(in.Path), aws.BoolValue(in.Recursive), aws.BoolValue(in.WithDecryption),
+		int(aws.Int64Value(in.MaxResults)), aws.StringValue(in.NextToken))
+	out := &ssm.GetParametersByPathOutput{}
+	for _, p := range params {
+		out.Parameters = append(out.Parameters, ssmParameter(p))
+	}
+	if next != "" {
+		out.NextToken = aws.String(next)
+	}
+	return out, nil
+}
+
+func (c *Client) GetParametersByPathWithContext(ctx aws.Context, in *ssm.GetParametersByPathInput, opts ...request.Option) (*ssm.GetParametersByPathOutput, error) {
+	return c.GetParametersByPath(in)
+}
+
+func (c *Client) DescribeParameters(in *ssm.DescribeParametersInput) (*ssm.DescribeParametersOutput, error) {
+	var filters []filter
+	for _, f := range in.ParameterFilters {
+		filters = append(filters, filter{
+			Key:    aws.StringValue(f.Key),
+			Option: aws.StringValue(f.Option),
+			Values: aws.StringValueSlice(f.Values),
+		})
+	}
+	params, err := c.describe(filters)
+	if err != nil {
+		return nil, awserr.New(err.code, err.message, nil)
+	}
+	out := &ssm.DescribeParametersOutput{}
+	for _, p := range params {
+		out.Parameters = append(out.Parameters, &ssm.ParameterMetadata{
+			Name:             aws.String(p.Name),
+			Type:             aws.String(p.Type),
+			Version:          aws.Int64(p.Version),
+			LastModifiedDate: aws.Time(modified(p)),
+		})
+	}
+	return out, nil
+}
+
+func (c *Client) DescribeParametersWithContext(ctx aws.Context, in *ssm.DescribeParametersInput, opts ...request.Option) (*ssm.DescribeParametersOutput, error) {
+	return c.DescribeParameters(in)
+}
+
+func ssmParameter(p parameter) *ssm.Parameter {
+	return &ssm.Parameter{
+		ARN:              aws.String(p.ARN),
+		LastModifiedDate: aws.Time(modified(p)),
+		Name:             aws.String(p.Name),
+		Type:             aws.String(p.Type),
+		Value:            aws.String(p.Value),
+		Version:          aws.Int64(p.Version),
+	}
+}
+
+func modified(p parameter) time.Time {
+	sec, frac := math.Modf(p.LastModifiedDate)
+	return time.Unix(int64(sec), int64(frac*float64(time.Second)))
+}
@@ -0,0 +1,60 @@
+package figgytest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	figgy "github.com/Syncbak-Git/go-figgy"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/stretchr/testify/assert"
+)
+
+type config struct {
+	Host     string `ssm:"/app/host"`
+	Port     int    `ssm:"/app/port"`
+	Debug    bool   `ssm:"/app/debug"`
+	Password string `ssm:"/app/password,decrypt"`
+}
+
+func TestClientFromFixture(t *testing.T) {
+	for _, path := range []string{"testdata/params.json", "testdata/params.yaml"} {
+		c, err := NewClientFromFixture(path)
+		if !assert.NoError(t, err, path) {
+			continue
+		}
+		var cfg config
+		assert.NoError(t, figgy.Load(c, &cfg), path)
+		assert.Equal(t, config{Host: "localhost", Port: 5432, Debug: true, Password: "hunter2"}, cfg, path)
+
+		out, err := c.GetParameter(&ssm.GetParameterInput{Name: aws.String("/app/password")})
+		assert.NoError(t, err)
+		assert.NotEqual(t, "hunter2", aws.StringValue(out.Parameter.Value))
+	}
+
+	_, err := NewClientFromFixture("testdata/missing.json")
+	assert.Error(t, err)
+	_, err = ReadFixture("testdata/params.txt")
+	assert.EqualError(t, err, "unsupported fixture file testdata/params.txt, use .json, .yaml or .yml")
+}
+
+func TestClientWatch(t *testing.T) {
+	c := NewClient()
+	c.Set("/app/host", "a")
+	c.Set("/app/port", "1")
+	c.Set("/app/debug", "false")
+	c.SetSecure("/app/password", "p")
+
+	var cfg config
+	w, err := figgy.Watch(c, &cfg, nil, figgy.Poll(time.Hour))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer w.Stop()
+	c.Set("/app/host", "b")
+	assert.NoError(t, w.Reload(context.Background()))
+	w.RLock()
+	assert.Equal(t, "b", cfg.Host)
+	w.RUnlock()
+}
@@ -0,0 +1,220 @@
+package figgytest
+
+import (
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// FaultClient wraps a client to inject the faults of a misbehaving Parameter Store on
+// demand, for testing how a service copes with them.  Faults apply to GetParameter,
+// GetParameters and GetParametersByPath, and throttling also to DescribeParameters.
+type FaultClient struct {
+	ssmiface.SSMAPI
+
+	mu       sync.Mutex
+	throttle int
+	partial  int
+	invalid  map[string]bool
+	stale    map[string]*ssm.Parameter
+}
+
+// NewFaultClient returns a client that passes requests to c until faults are injected
+func NewFaultClient(c ssmiface.SSMAPI) *FaultClient {
+	return &FaultClient{
+		SSMAPI:  c,
+		invalid: make(map[string]bool),
+		stale:   make(map[string]*ssm.Parameter),
+	}
+}
+
+// Throttle fails the next n requests with a ThrottlingException
+func (f *FaultClient) Throttle(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.throttle = n
+}
+
+// Invalidate reports the parameters called names as missing, whether or not they
+// exist
+func (f *FaultClient) Invalidate(names ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, name := range names {
+		f.invalid[name] = true
+	}
+}
+
+// Stale freezes the parameters called names at the values they have when next
+// requested, so later changes aren't seen
+func (f *FaultClient) Stale(names ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, name := range names {
+		f.stale[name] = nil
+	}
+}
+
+// FailPartially makes the next n GetParameters requests of more than one parameter
+// report the second half of their parameters as invalid
+func (f *FaultClient) FailPartially(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.partial = n
+}
+
+// Reset removes every injected fault
+func (f *FaultClient) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.throttle = 0
+	f.partial = 0
+	f.invalid = make(map[string]bool)
+	f.stale = make(map[string]*ssm.Parameter)
+}
+
+// throttled reports whether to throttle a request
+func (f *FaultClient) throttled() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.throttle == 0 {
+		return nil
+	}
+	f.throttle--
+	return awserr.New("ThrottlingException", "Rate exceeded", nil)
+}
+
+// filter applies the invalid and stale faults to the parameters of a response,
+// returning those that remain and the names of those made invalid
+func (f *FaultClient) filter(params []*ssm.Parameter) ([]*ssm.Parameter, []*string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var kept []*ssm.Parameter
+	var invalid []*string
+	for _, p := range params {
+		name := aws.StringValue(p.Name)
+		if f.invalid[name] {
+			invalid = append(invalid, p.Name)
+			continue
+		}
+		if s, ok := f.stale[name]; ok {
+			if s == nil {
+				f.stale[name] = p
+			} else {
+				p = s
+			}
+		}
+		kept = append(kept, p)
+	}
+	return kept, invalid
+}
+
+func (f *FaultClient) GetParameter(in *ssm.GetParameterInput) (*ssm.GetParameterOutput, error) {
+	if err := f.throttled(); err != nil {
+		return nil, err
+	}
+	return f.getParameter(f.SSMAPI.GetParameter(in))
+}
+
+func (f *FaultClient) GetParameterWithContext(ctx aws.Context, in *ssm.GetParameterInput, opts ...request.Option) (*ssm.GetParameterOutput, error) {
+	if err := f.throttled(); err != nil {
+		return nil, err
+	}
+	return f.getParameter(f.SSMAPI.GetParameterWithContext(ctx, in, opts...))
+}
+
+func (f *FaultClient) getParameter(out *ssm.GetParameterOutput, err error) (*ssm.GetParameterOutput, error) {
+	if err != nil {
+		return nil, err
+	}
+	params, _ := f.filter([]*ssm.Parameter{out.Parameter})
+	if len(params) == 0 {
+		return nil, awserr.New(ssm.ErrCodeParameterNotFound, "parameter not found", nil)
+	}
+	return &ssm.GetParameterOutput{Parameter: params[0]}, nil
+}
+
+func (f *FaultClient) GetParameters(in *ssm.GetParametersInput) (*ssm.GetParametersOutput, error) {
+	if err := f.throttled(); err != nil {
+		return nil, err
+	}
+	out, err := f.SSMAPI.GetParameters(in)
+	return f.getParameters(in, out, err)
+}
+
+func (f *FaultClient) GetParametersWithContext(ctx aws.Context, in *ssm.GetParametersInput, opts ...request.Option) (*ssm.GetParametersOutput, error) {
+	if err := f.throttled(); err != nil {
+		return nil, err
+	}
+	out, err := f.SSMAPI.GetParametersWithContext(ctx, in, opts...)
+	return f.getParameters(in, out, err)
+}
+
+func (f *FaultClient) getParameters(in *ssm.GetParametersInput, out *ssm.GetParametersOutput, err error) (*ssm.GetParametersOutput, error) {
+	if err != nil {
+		return nil, err
+	}
+	params, invalid := f.filter(out.Parameters)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.partial > 0 && len(in.Names) > 1 {
+		f.partial--
+		failed := make(map[string]bool)
+		for _, name := range in.Names[len(in.Names)/2:] {
+			failed[aws.StringValue(name)] = true
+		}
+		var kept []*ssm.Parameter
+		for _, p := range params {
+			if failed[aws.StringValue(p.Name)] {
+				invalid = append(invalid, p.Name)
+			} else {
+				kept = append(kept, p)
+			}
+		}
+		params = kept
+	}
+	return &ssm.GetParametersOutput{
+		Parameters:        params,
+		InvalidParameters: append(out.InvalidParameters, invalid...),
+	}, nil
+}
+
+func (f *FaultClient) GetParametersByPath(in *ssm.GetParametersByPathInput) (*ssm.GetParametersByPathOutput, error) {
+	if err := f.throttled(); err != nil {
+		return nil, err
+	}
+	return f.getParametersByPath(f.SSMAPI.GetParametersByPath(in))
+}
+
+func (f *FaultClient) GetParametersByPathWithContext(ctx aws.Context, in *ssm.GetParametersByPathInput, opts ...request.Option) (*ssm.GetParametersByPathOutput, error) {
+	if err := f.throttled(); err != nil {
+		return nil, err
+	}
+	return f.getParametersByPath(f.SSMAPI.GetParametersByPathWithContext(ctx, in, opts...))
+}
+
+func (f *FaultClient) getParametersByPath(out *ssm.GetParametersByPathOutput, err error) (*ssm.GetParametersByPathOutput, error) {
+	if err != nil {
+		return nil, err
+	}
+	params, _ := f.filter(out.Parameters)
+	return &ssm.GetParametersByPathOutput{Parameters: params, NextToken: out.NextToken}, nil
+}
+
+func (f *FaultClient) DescribeParameters(in *ssm.DescribeParametersInput) (*ssm.DescribeParametersOutput, error) {
+	if err := f.throttled(); err != nil {
+		return nil, err
+	}
+	return f.SSMAPI.DescribeParameters(in)
+}
+
+func (f *FaultClient) DescribeParametersWithContext(ctx aws.Context, in *ssm.DescribeParametersInput, opts ...request.Option) (*ssm.DescribeParametersOutput, error) {
+	if err := f.throttled(); err != nil {
+		return nil, err
+	}
+	return f.SSMAPI.DescribeParametersWithContext(ctx, in, opts...)
+}
@@ -0,0 +1,64 @@
+package figgytest
+
+import (
+	"errors"
+	"testing"
+
+	figgy "github.com/Syncbak-Git/go-figgy"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/stretchr/testify/assert"
+)
+
+func newFaultClient(t *testing.T) (*Client, *FaultClient) {
+	c, err := NewClientFromFixture("testdata/params.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, NewFaultClient(c)
+}
+
+func TestFaultThrottle(t *testing.T) {
+	_, f := newFaultClient(t)
+	f.Throttle(1)
+	var cfg config
+	err := figgy.Load(f, &cfg)
+	if assert.Error(t, err) {
+		var aerr awserr.Error
+		assert.True(t, errors.As(err, &aerr))
+		assert.Equal(t, "ThrottlingException", aerr.Code())
+	}
+	assert.NoError(t, figgy.Load(f, &cfg))
+}
+
+func TestFaultInvalidate(t *testing.T) {
+	_, f := newFaultClient(t)
+	f.Invalidate("/app/host")
+	var cfg config
+	assert.EqualError(t, figgy.Load(f, &cfg), "invalid parameters: /app/host")
+	f.Reset()
+	assert.NoError(t, figgy.Load(f, &cfg))
+}
+
+func TestFaultPartial(t *testing.T) {
+	_, f := newFaultClient(t)
+	f.FailPartially(1)
+	var cfg struct {
+		Host string `ssm:"/app/host"`
+		Port int    `ssm:"/app/port"`
+	}
+	assert.EqualError(t, figgy.Load(f, &cfg), "invalid parameters: /app/port")
+	assert.NoError(t, figgy.Load(f, &cfg))
+}
+
+func TestFaultStale(t *testing.T) {
+	c, f := newFaultClient(t)
+	f.Stale("/app/host")
+	var cfg config
+	assert.NoError(t, figgy.Load(f, &cfg))
+	c.Set("/app/host", "changed")
+	assert.NoError(t, figgy.Load(f, &cfg))
+	assert.Equal(t, "localhost", cfg.Host)
+	f.Reset()
+	assert.NoError(t, figgy.Load(f, &cfg))
+	assert.Equal(t, "changed", cfg.Host)
+}
@@ -0,0 +1,116 @@
+package figgytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Parameter is a parameter of a fixture
+type Parameter struct {
+	Value  string
+	Secure bool
+}
+
+// UnmarshalJSON reads a parameter from a scalar value or a {"value", "secure"} object
+func (p *Parameter) UnmarshalJSON(b []byte) error {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return err
+	}
+	if _, ok := v.(map[string]interface{}); !ok {
+		return p.scalar(v)
+	}
+	var o struct {
+		Value  string `json:"value"`
+		Secure bool   `json:"secure"`
+	}
+	if err := json.Unmarshal(b, &o); err != nil {
+		return err
+	}
+	p.Value, p.Secure = o.Value, o.Secure
+	return nil
+}
+
+// UnmarshalYAML reads a parameter from a scalar value or a value and secure mapping
+func (p *Parameter) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var v interface{}
+	if err := unmarshal(&v); err != nil {
+		return err
+	}
+	if _, ok := v.(map[interface{}]interface{}); !ok {
+		return p.scalar(v)
+	}
+	var o struct {
+		Value  string `yaml:"value"`
+		Secure bool   `yaml:"secure"`
+	}
+	if err := unmarshal(&o); err != nil {
+		return err
+	}
+	p.Value, p.Secure = o.Value, o.Secure
+	return nil
+}
+
+func (p *Parameter) scalar(v interface{}) error {
+	switch v.(type) {
+	case []interface{}:
+		return fmt.Errorf("fixture parameter cannot be a list")
+	case nil:
+		p.Value = ""
+	default:
+		p.Value = fmt.Sprint(v)
+	}
+	return nil
+}
+
+// ReadFixture reads parameters from a JSON or YAML file, chosen by its extension,
+// mapping names to values.  A value may instead be an object with value and secure
+// members, for SecureString parameters:
+//
+//	{
+//	  "/myapp/host": "localhost",
+//	  "/myapp/port": 5432,
+//	  "/myapp/password": {"value": "hunter2", "secure": true}
+//	}
+func ReadFixture(path string) (map[string]Parameter, error) {
+	unmarshal := json.Unmarshal
+	switch filepath.Ext(path) {
+	case ".json":
+	case ".yaml", ".yml":
+		unmarshal = yaml.Unmarshal
+	default:
+		return nil, fmt.Errorf("unsupported fixture file %s, use .json, .yaml or .yml", path)
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	params := make(map[string]Parameter)
+	if err := unmarshal(b, &params); err != nil {
+		return nil, fmt.Errorf("failed to read fixture %s: %v", path, err)
+	}
+	return params, nil
+}
+
+// LoadFixture sets the parameters of the fixture file at path, as read by ReadFixture
+func (s *store) LoadFixture(path string) error {
+	params, err := ReadFixture(path)
+	if err != nil {
+		return err
+	}
+	for name, p := range params {
+		if p.Secure {
+			s.SetSecure(name, p.Value)
+		} else {
+			s.Set(name, p.Value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+// Package figgytest provides a fake Parameter Store server for integration tests.
+// Unlike a mocked client, the server is called by a real aws-sdk-go client over HTTP,
+// so retries, backoff and timeouts behave as they would against AWS:
+//
+//	s := figgytest.NewServer()
+//	defer s.Close()
+//	s.Set("/myapp/host", "localhost")
+//	c, err := figgy.NewEndpointClient(s.URL)
+//	err = figgy.Load(c, &cfg)
+package figgytest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxParameters is the most parameters GetParameters accepts
+const maxParameters = 10
+
+// Server is a fake Parameter Store that serves GetParameter, GetParameters,
+// GetParametersByPath and DescribeParameters, with injected throttling and latency
+type Server struct {
+	// URL is the endpoint of the server, for the client's aws.Config
+	URL string
+
+	*store
+	srv      *httptest.Server
+	mu       sync.Mutex
+	throttle int
+	latency  time.Duration
+	calls    map[string]int
+}
+
+// NewServer starts a server with no parameters
+func NewServer() *Server {
+	s := &Server{
+		store: newStore(),
+		calls: make(map[string]int),
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.srv.URL
+	return s
+}
+
+// Close shuts the server down
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Throttle fails the next n requests with a ThrottlingException, which clients retry
+// with backoff
+func (s *Server) Throttle(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.throttle = n
+}
+
+// SetLatency delays every response by d
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// Calls returns the number of requests received for an action, such as
+// "GetParameters", including throttled requests
+func (s *Server) Calls(action string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[action]
+}
+
+// apiError is an error response of the JSON protocol
+type apiError struct {
+	status  int
+	code    string
+	message string
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	action := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "AmazonSSM.")
+	s.mu.Lock()
+	s.calls[action]++
+	latency := s.latency
+	throttled := s.throttle > 0
+	if throttled {
+		s.throttle--
+	}
+	s.mu.Unlock()
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if throttled {
+		writeError(w, &apiError{http.StatusBadRequest, "ThrottlingException", "Rate exceeded"})
+		return
+	}
+	var out interface{}
+	var err *apiError
+	switch action {
+	case "GetParameter":
+		var in struct {
+			Name           string
+			WithDecryption bool
+		}
+		if err = decode(r, &in); err == nil {
+			out, err = s.getParameter(in.Name, in.WithDecryption)
+		}
+	case "GetParameters":
+		var in struct {
+			Names          []string
+			WithDecryption bool
+		}
+		if err = decode(r, &in); err == nil {
+			out, err = s.getParameters(in.Names, in.WithDecryption)
+		}
+	case "GetParametersByPath":
+		var in struct {
+			Path           string
+			Recursive      bool
+			WithDecryption bool
+			MaxResults     int
+			NextToken      string
+		}
+		if err = decode(r, &in); err == nil {
+			out, err = s.getParametersByPath(in.Path, in.Recursive, in.WithDecryption, in.MaxResults, in.NextToken)
+		}
+	case "DescribeParameters":
+		var in struct {
+			ParameterFilters []filter
+		}
+		if err = decode(r, &in); err == nil {
+			out, err = s.describeParameters(in.ParameterFilters)
+		}
+	default:
+		err = &apiError{http.StatusBadRequest, "UnknownOperationException", fmt.Sprintf("unsupported action %q", action)}
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+	json.NewEncoder(w).Encode(out)
+}
+
+func decode(r *http.Request, in interface{}) *apiError {
+	if err := json.NewDecoder(r.Body).Decode(in); err != nil {
+		return &apiError{http.StatusBadRequest, "SerializationException", err.Error()}
+	}
+	return nil
+}
+
+func writeError(w http.ResponseWriter, err *apiError) {
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+	w.WriteHeader(err.status)
+	json.NewEncoder(w).Encode(map[string]string{"__type": err.code, "message": err.message})
+}
+
+func (s *Server) getParameter(name string, decrypt bool) (interface{}, *apiError) {
+	params, _ := s.get([]string{name}, decrypt)
+	if len(params) == 0 {
+		return nil, &apiError{http.StatusBadRequest, "ParameterNotFound", ""}
+	}
+	return map[string]interface{}{"Parameter": params[0]}, nil
+}
+
+func (s *Server) getParameters(names []string, decrypt bool) (interface{}, *apiError) {
+	if len(names) > maxParameters {
+		return nil, &apiError{http.StatusBadRequest, "ValidationException", fmt.Sprintf("at most %d names can be requested", maxParameters)}
+	}
+	params, invalid := s.get(names, decrypt)
+	return map[string]interface{}{"Parameters": params, "InvalidParameters": invalid}, nil
+}
+
+func (s *Server) getParametersByPath(path string, recursive, decrypt bool, max int, token string) (interface{}, *apiError) {
+	params, next := s.byPath(path, recursive, decrypt, max, token)
+	out := map[string]interface{}{"Parameters": params}
+	if next != "" {
+		out["NextToken"] = next
+	}
+	return out, nil
+}
+
+func (s *Server) describeParameters(filters []filter) (interface{}, *apiError) {
+	params, err := s.describe(filters)
+	if err != nil {
+		return nil, err
+	}
+	type metadata struct {
+		Name             string
+		Type             string
+		Version          int64
+		LastModifiedDate float64
+	}
+	out := make([]metadata, len(params))
+	for i, p := range params {
+		out[i] = metadata{p.Name, p.Type, p.Version, p.LastModifiedDate}
+	}
+	return map[string]interface{}{"Parameters": out}, nil
+}
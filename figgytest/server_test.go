@@ -0,0 +1,117 @@
+package figgytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// call makes a request to the server as the aws-sdk-go JSON protocol does
+func call(t *testing.T, s *Server, action string, in interface{}, out interface{}) int {
+	b, _ := json.Marshal(in)
+	req, _ := http.NewRequest("POST", s.URL+"/", bytes.NewReader(b))
+	req.Header.Set("X-Amz-Target", "AmazonSSM."+action)
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	res, err := http.DefaultClient.Do(req)
+	if !assert.NoError(t, err) {
+		return 0
+	}
+	defer res.Body.Close()
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(out))
+	return res.StatusCode
+}
+
+type result struct {
+	Type              string `json:"__type"`
+	Parameter         parameter
+	Parameters        []parameter
+	InvalidParameters []string
+	NextToken         string
+}
+
+func TestGetParameters(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.Set("/app/host", "localhost")
+	s.SetSecure("/app/password", "hunter2")
+
+	var r result
+	assert.Equal(t, 200, call(t, s, "GetParameter", map[string]interface{}{"Name": "/app/password", "WithDecryption": true}, &r))
+	assert.Equal(t, "hunter2", r.Parameter.Value)
+	assert.Equal(t, int64(1), r.Parameter.Version)
+
+	r = result{}
+	assert.Equal(t, 200, call(t, s, "GetParameters", map[string]interface{}{"Names": []string{"/app/host", "/app/password", "/app/port"}}, &r))
+	assert.Len(t, r.Parameters, 2)
+	assert.Equal(t, "localhost", r.Parameters[0].Value)
+	assert.NotEqual(t, "hunter2", r.Parameters[1].Value)
+	assert.Equal(t, []string{"/app/port"}, r.InvalidParameters)
+
+	r = result{}
+	assert.Equal(t, 400, call(t, s, "GetParameter", map[string]interface{}{"Name": "/app/port"}, &r))
+	assert.Equal(t, "ParameterNotFound", r.Type)
+}
+
+func TestGetParametersByPath(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	for _, k := range []string{"/app/a", "/app/b", "/app/c", "/app/nested/d", "/other"} {
+		s.Set(k, k)
+	}
+
+	var names []string
+	in := map[string]interface{}{"Path": "/app", "MaxResults": 2}
+	for {
+		var r result
+		assert.Equal(t, 200, call(t, s, "GetParametersByPath", in, &r))
+		for _, p := range r.Parameters {
+			names = append(names, p.Name)
+		}
+		if r.NextToken == "" {
+			break
+		}
+		in["NextToken"] = r.NextToken
+	}
+	assert.Equal(t, []string{"/app/a", "/app/b", "/app/c"}, names)
+
+	var r result
+	call(t, s, "GetParametersByPath", map[string]interface{}{"Path": "/app/", "Recursive": true}, &r)
+	assert.Len(t, r.Parameters, 4)
+}
+
+func TestDescribeParameters(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.Set("/app/host", "a")
+	s.Set("/app/host", "b")
+	s.Set("/app/port", "1")
+
+	var r result
+	filters := []filter{{Key: "Name", Option: "Equals", Values: []string{"/app/host"}}}
+	assert.Equal(t, 200, call(t, s, "DescribeParameters", map[string]interface{}{"ParameterFilters": filters}, &r))
+	assert.Len(t, r.Parameters, 1)
+	assert.Equal(t, int64(2), r.Parameters[0].Version)
+}
+
+func TestThrottleAndLatency(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.Set("/app/host", "localhost")
+	s.Throttle(2)
+
+	for i := 0; i < 2; i++ {
+		var r result
+		assert.Equal(t, 400, call(t, s, "GetParameter", map[string]interface{}{"Name": "/app/host"}, &r))
+		assert.Equal(t, "ThrottlingException", r.Type)
+	}
+	s.SetLatency(20 * time.Millisecond)
+	start := time.Now()
+	var r result
+	assert.Equal(t, 200, call(t, s, "GetParameter", map[string]interface{}{"Name": "/app/host"}, &r))
+	assert.True(t, time.Since(start) >= 20*time.Millisecond)
+	assert.Equal(t, 3, s.Calls("GetParameter"))
+}
@@ -0,0 +1,166 @@
+package figgytest
+
+import (
+	"encoding/base64"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// store holds the parameters of a Server or Client
+type store struct {
+	mu     sync.Mutex
+	params map[string]*parameter
+}
+
+type parameter struct {
+	Name             string
+	Type             string
+	Value            string
+	Version          int64
+	LastModifiedDate float64
+	ARN              string
+}
+
+func newStore() *store {
+	return &store{params: make(map[string]*parameter)}
+}
+
+// Set creates or updates a String parameter, incrementing its version
+func (s *store) Set(name, value string) {
+	s.put(name, value, "String")
+}
+
+// SetSecure creates or updates a SecureString parameter, whose value is only returned
+// as is by requests with decryption
+func (s *store) SetSecure(name, value string) {
+	s.put(name, value, "SecureString")
+}
+
+func (s *store) put(name, value, typ string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.params[name]
+	if !ok {
+		p = &parameter{
+			Name: name,
+			ARN:  "arn:aws:ssm:us-east-1:123456789012:parameter/" + strings.TrimPrefix(name, "/"),
+		}
+		s.params[name] = p
+	}
+	p.Type = typ
+	p.Value = value
+	p.Version++
+	p.LastModifiedDate = float64(time.Now().UnixNano()) / float64(time.Second)
+}
+
+// Delete removes a parameter
+func (s *store) Delete(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.params, name)
+}
+
+// value returns a copy of p as returned to a request, with the value of a
+// SecureString encoded unless it's decrypted
+func value(p *parameter, decrypt bool) parameter {
+	v := *p
+	if v.Type == "SecureString" && !decrypt {
+		v.Value = base64.StdEncoding.EncodeToString([]byte(v.Value))
+	}
+	return v
+}
+
+// get returns the parameters called names, and the names that don't exist
+func (s *store) get(names []string, decrypt bool) ([]parameter, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	params := []parameter{}
+	invalid := []string{}
+	for _, name := range names {
+		if p, ok := s.params[name]; ok {
+			params = append(params, value(p, decrypt))
+		} else {
+			invalid = append(invalid, name)
+		}
+	}
+	return params, invalid
+}
+
+// byPath returns a page of at most max parameters under path, starting from token,
+// and the token of the next page
+func (s *store) byPath(path string, recursive, decrypt bool, max int, token string) ([]parameter, string) {
+	if max <= 0 {
+		max = maxParameters
+	}
+	prefix := strings.TrimSuffix(path, "/") + "/"
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var names []string
+	for name := range s.params {
+		rest := strings.TrimPrefix(name, prefix)
+		if rest == name || !recursive && strings.Contains(rest, "/") {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	start := 0
+	if token != "" {
+		start = sort.SearchStrings(names, token)
+	}
+	params := []parameter{}
+	for i := start; i < len(names); i++ {
+		if len(params) == max {
+			return params, names[i]
+		}
+		params = append(params, value(s.params[names[i]], decrypt))
+	}
+	return params, ""
+}
+
+// filter is a parameter filter of DescribeParameters
+type filter struct {
+	Key    string
+	Option string
+	Values []string
+}
+
+// describe returns the parameters matching filters, which support the Name filter
+// with the Equals and BeginsWith options
+func (s *store) describe(filters []filter) ([]parameter, *apiError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var names []string
+	for name := range s.params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	params := []parameter{}
+	for _, name := range names {
+		match := true
+		for _, f := range filters {
+			if f.Key != "Name" {
+				return nil, &apiError{http.StatusBadRequest, "InvalidFilterKey", f.Key}
+			}
+			found := false
+			for _, v := range f.Values {
+				switch f.Option {
+				case "", "Equals":
+					found = found || name == v
+				case "BeginsWith":
+					found = found || strings.HasPrefix(name, v)
+				default:
+					return nil, &apiError{http.StatusBadRequest, "InvalidFilterOption", f.Option}
+				}
+			}
+			match = match && found
+		}
+		if match {
+			params = append(params, *s.params[name])
+		}
+	}
+	return params, nil
+}
@@ -0,0 +1,215 @@
+// Package figgyvet defines an Analyzer that checks the ssm tags of struct types,
+// reporting problems that figgy would otherwise only find when loading, or silently
+// ignore:
+//
+//   - empty keys and unknown or empty tag options
+//   - path without json, setenv without dotenv, and conflicting decoding options
+//   - decrypt on fields that can't reasonably hold a secret, such as bools, numbers,
+//     and durations
+//   - fields of a struct sharing a key that request it differently, as chunks or
+//     renamed from another key, which figgy.Load fails with a TagConflictError
+//
+// The Analyzer runs with go vet through cmd/figgyvet, or alongside other analyzers in
+// a multichecker.
+package figgyvet
+
+import (
+	"go/ast"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Analyzer checks the ssm tags of struct fields
+var Analyzer = &analysis.Analyzer{
+	Name: "figgyvet",
+	Doc:  "check the ssm tags of struct fields loaded by figgy",
+	Run:  run,
+}
+
+// options are the tag options known to figgy.  Formats registered at runtime with
+// figgy.RegisterFormat aren't known here and are reported.
+var options = map[string]bool{
+	"decrypt": true,
+	"json":    true,
+	"chunks":  true,
+	"path":    true,
+	"dotenv":  true,
+	"setenv":  true,
+	"refresh": true,
+	"region":  true,
+	"old":     true,
+	"rename":  true,
+	"group":   true,
+	"static":  true,
+	"raw":     true,
+	"durfmt":  true,
+	"oneof":   true,
+	"min":     true,
+	"max":     true,
+	"match":   true,
+	"emptyas": true,
+	"nilas":   true,
+	"toml":    true,
+	"hcl":     true,
+}
+
+// durationFormats are the values of the durfmt option
+var durationFormats = map[string]bool{"go": true, "seconds": true, "ns": true}
+
+// nonSecret are field types that can't reasonably hold a secret
+var nonSecret = map[string]bool{
+	"bool": true, "time.Duration": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+	"float32": true, "float64": true, "complex64": true, "complex128": true,
+}
+
+// request is how a field requests its parameter, which fields sharing a key must agree on
+type request struct {
+	chunks bool
+	old    string
+	rename string
+}
+
+// source identifies a parameter
+type source struct {
+	key    string
+	region string
+}
+
+// sharedKey is the first field of a struct requesting a parameter
+type sharedKey struct {
+	pos     token.Pos
+	request request
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	for _, f := range pass.Files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			st, ok := n.(*ast.StructType)
+			if !ok {
+				return true
+			}
+			checkStruct(pass, st)
+			return true
+		})
+	}
+	return nil, nil
+}
+
+// checkStruct reports the problems with the ssm tags of a struct's fields, and fields
+// sharing a key that request it differently
+func checkStruct(pass *analysis.Pass, st *ast.StructType) {
+	keys := make(map[source]sharedKey)
+	for _, fld := range st.Fields.List {
+		if fld.Tag == nil {
+			continue
+		}
+		s, err := strconv.Unquote(fld.Tag.Value)
+		if err != nil {
+			continue
+		}
+		tag, ok := reflect.StructTag(s).Lookup("ssm")
+		if !ok || tag == "-" {
+			continue
+		}
+		src, req := checkTag(pass, fld, tag)
+		if src.key == "" {
+			continue
+		}
+		first, ok := keys[src]
+		if !ok {
+			keys[src] = sharedKey{pos: fld.Tag.Pos(), request: req}
+			continue
+		}
+		if first.request != req {
+			pass.Reportf(fld.Tag.Pos(), "key %s is requested differently by the field at %s", src.key, pass.Fset.Position(first.pos))
+		}
+	}
+}
+
+// checkTag reports the problems with a field's ssm tag, returning the parameter it
+// loads and how it requests it
+func checkTag(pass *analysis.Pass, fld *ast.Field, tag string) (source, request) {
+	pos := fld.Tag.Pos()
+	var src source
+	var req request
+	o := strings.Split(tag, ",")
+	key := strings.TrimSpace(o[0])
+	if key == "" {
+		pass.Reportf(pos, "ssm tag %q has an empty key", tag)
+	}
+	set := make(map[string]bool)
+	for _, option := range o[1:] {
+		option = strings.TrimSpace(option)
+		name, value := option, ""
+		if i := strings.Index(option, "="); i >= 0 {
+			name, value = option[:i], option[i+1:]
+		}
+		switch {
+		case option == "":
+			pass.Reportf(pos, "ssm tag %q has an empty option", tag)
+		case !options[name]:
+			pass.Reportf(pos, "ssm tag %q has unknown option %q", tag, name)
+		case name == "durfmt" && !durationFormats[value]:
+			pass.Reportf(pos, "ssm tag %q has unknown duration format %q", tag, value)
+		case name == "path" && !strings.Contains(option, "="):
+			// a bare path option loads a map from the parameters under the key
+		default:
+			set[name] = true
+		}
+		switch name {
+		case "region":
+			src.region = value
+		case "old":
+			req.old = value
+		case "rename":
+			req.rename = value
+		}
+	}
+	if set["path"] && !set["json"] {
+		pass.Reportf(pos, "ssm tag %q uses path without json", tag)
+	}
+	if set["setenv"] && !set["dotenv"] {
+		pass.Reportf(pos, "ssm tag %q uses setenv without dotenv", tag)
+	}
+	decoders := 0
+	for _, d := range []string{"json", "dotenv", "toml", "hcl"} {
+		if set[d] {
+			decoders++
+		}
+	}
+	if decoders > 1 {
+		pass.Reportf(pos, "ssm tag %q has more than one of json, dotenv, toml, and hcl", tag)
+	}
+	if set["raw"] && decoders > 0 {
+		pass.Reportf(pos, "ssm tag %q uses raw with a decoding option", tag)
+	}
+	if set["decrypt"] {
+		t := fld.Type
+		if s, ok := t.(*ast.StarExpr); ok {
+			t = s.X
+		}
+		if typ := exprString(t); nonSecret[typ] {
+			pass.Reportf(pos, "ssm tag %q decrypts a %s field, which is unlikely to hold a secret", tag, typ)
+		}
+	}
+	src.key = key
+	req.chunks = set["chunks"]
+	return src, req
+}
+
+// exprString returns the source of simple type expressions, such as int or time.Duration
+func exprString(e ast.Expr) string {
+	switch e := e.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		return exprString(e.X) + "." + e.Sel.Name
+	}
+	return ""
+}
@@ -0,0 +1,65 @@
+package figgyvet
+
+import (
+	"bufio"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/tools/go/analysis"
+)
+
+// wants returns the expected diagnostics in a file by line, written as comments of the
+// form // want `regexp`
+func wants(t *testing.T, file string) map[int]*regexp.Regexp {
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	w := make(map[int]*regexp.Regexp)
+	re := regexp.MustCompile("// want `(.*)`$")
+	s := bufio.NewScanner(f)
+	for line := 1; s.Scan(); line++ {
+		if m := re.FindStringSubmatch(s.Text()); m != nil {
+			w[line] = regexp.MustCompile(m[1])
+		}
+	}
+	return w
+}
+
+func TestAnalyzer(t *testing.T) {
+	const file = "testdata/tags/tags.go"
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var diags []analysis.Diagnostic
+	pass := &analysis.Pass{
+		Analyzer: Analyzer,
+		Fset:     fset,
+		Files:    []*ast.File{f},
+		Report:   func(d analysis.Diagnostic) { diags = append(diags, d) },
+	}
+	_, err = Analyzer.Run(pass)
+	assert.NoError(t, err)
+	want := wants(t, file)
+	for _, d := range diags {
+		pos := fset.Position(d.Pos)
+		re, ok := want[pos.Line]
+		if !ok {
+			t.Errorf("%s: unexpected diagnostic %s", pos, d.Message)
+			continue
+		}
+		assert.True(t, re.MatchString(d.Message), "line %d: %q doesn't match %q", pos.Line, d.Message, re)
+		delete(want, pos.Line)
+	}
+	for line, re := range want {
+		t.Errorf("missing diagnostic at line %d: %s", line, re)
+	}
+}
@@ -0,0 +1,33 @@
+package tags
+
+import "time"
+
+type Config struct {
+	Server    string            `ssm:"/myapp/server"`
+	Empty     string            `ssm:",decrypt"`                // want `ssm tag ",decrypt" has an empty key`
+	Blank     string            `ssm:"/myapp/blank,,json"`      // want `ssm tag "/myapp/blank,,json" has an empty option`
+	Unknown   string            `ssm:"/myapp/unknown,decrpyt"`  // want `ssm tag "/myapp/unknown,decrpyt" has unknown option "decrpyt"`
+	Path      string            `ssm:"/myapp/doc,path=$.host"`  // want `ssm tag "/myapp/doc,path=\$.host" uses path without json`
+	Env       map[string]string `ssm:"/myapp/env,setenv"`       // want `ssm tag "/myapp/env,setenv" uses setenv without dotenv`
+	Both      map[string]string `ssm:"/myapp/both,json,dotenv"` // want `ssm tag "/myapp/both,json,dotenv" has more than one of json, dotenv, toml, and hcl`
+	Debug     bool              `ssm:"/myapp/debug,decrypt"`    // want `ssm tag "/myapp/debug,decrypt" decrypts a bool field, which is unlikely to hold a secret`
+	Timeout   *time.Duration    `ssm:"/myapp/timeout,decrypt"`  // want `ssm tag "/myapp/timeout,decrypt" decrypts a time.Duration field, which is unlikely to hold a secret`
+	Password  string            `ssm:"/myapp/password,decrypt"`
+	Again     string            `ssm:"/myapp/server"`
+	Chunked   string            `ssm:"/myapp/server,chunks"` // want `key /myapp/server is requested differently by the field at .*tags.go:6:[0-9]+`
+	Regional  string            `ssm:"/myapp/server,region=us-west-2,chunks"`
+	Host      string            `ssm:"/myapp/db,json,path=$.host"`
+	Port      int               `ssm:"/myapp/db,json,path=$.port"`
+	Settings  map[string]string `ssm:"/myapp/settings,toml,chunks"`
+	Verbatim  string            `ssm:"/myapp/verbatim,raw"`
+	Interval  time.Duration     `ssm:"/myapp/interval,durfmt=seconds"`
+	Minutes   time.Duration     `ssm:"/myapp/minutes,durfmt=minutes"` // want `ssm tag "/myapp/minutes,durfmt=minutes" has unknown duration format "minutes"`
+	RawJSON   string            `ssm:"/myapp/rawjson,raw,json"`       // want `ssm tag "/myapp/rawjson,raw,json" uses raw with a decoding option`
+	Ignored   string            `ssm:"-"`
+	Untagged  string
+	Templated string `ssm:"/myapp/{{.env}}/server"`
+}
+
+type Other struct {
+	Server string `ssm:"/myapp/server"`
+}
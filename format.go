@@ -0,0 +1,59 @@
+package figgy
+
+import (
+	"fmt"
+	"sync"
+)
+
+// UnmarshalFunc decodes data in a format into v, matching the signature of json.Unmarshal
+type UnmarshalFunc func(data []byte, v interface{}) error
+
+// formats maps a tag option to the unmarshal function for that format.  The
+// toml and hcl options are always recognized but need a registered decoder.
+var formats = struct {
+	sync.RWMutex
+	m map[string]UnmarshalFunc
+}{m: map[string]UnmarshalFunc{
+	"toml": nil,
+	"hcl":  nil,
+}}
+
+// RegisterFormat registers an unmarshal function for a tag option naming a format,
+// allowing parameters stored as TOML, HCL, or another format to be decoded into a
+// field the same way the json option is.  For example:
+//
+//	figgy.RegisterFormat("toml", toml.Unmarshal)
+//	figgy.RegisterFormat("hcl", hcl.Unmarshal)
+//
+//	type Config struct {
+//		Settings Settings `ssm:"/myapp/settings,toml"`
+//	}
+//
+// The name can't be one of the options built in to figgy, such as json or decrypt.
+func RegisterFormat(name string, unmarshal UnmarshalFunc) {
+	switch name {
+	case "", "decrypt", "json", "chunks", "path", "dotenv", "setenv", "refresh", "region", "old", "rename", "group", "static", "raw", "durfmt", "oneof", "min", "max", "match", "emptyas", "nilas":
+		panic("figgy: cannot register format " + name)
+	}
+	formats.Lock()
+	defer formats.Unlock()
+	formats.m[name] = unmarshal
+}
+
+func isFormat(name string) bool {
+	formats.RLock()
+	defer formats.RUnlock()
+	_, ok := formats.m[name]
+	return ok
+}
+
+// setFormat decodes a value using the unmarshal function registered for the field's format
+func setFormat(f *field, s string) error {
+	formats.RLock()
+	unmarshal := formats.m[f.format]
+	formats.RUnlock()
+	if unmarshal == nil {
+		return fmt.Errorf("no decoder registered for the '%s' option on field %s", f.format, f.field.Name)
+	}
+	return setUnmarshal(f, s, f.format, unmarshal)
+}
@@ -0,0 +1,68 @@
+package figgy
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// unmarshalKV decodes lines of key = value into a *map[string]string
+func unmarshalKV(data []byte, v interface{}) error {
+	m, ok := v.(*map[string]string)
+	if !ok {
+		return errors.New("unsupported type")
+	}
+	*m = make(map[string]string)
+	for _, l := range strings.Split(string(data), "\n") {
+		kv := strings.SplitN(l, "=", 2)
+		if len(kv) != 2 {
+			return errors.New("invalid line")
+		}
+		(*m)[strings.TrimSpace(kv[0])] = strings.Trim(strings.TrimSpace(kv[1]), `"`)
+	}
+	return nil
+}
+
+func TestRegisterFormat(t *testing.T) {
+	RegisterFormat("toml", unmarshalKV)
+	defer RegisterFormat("toml", nil)
+
+	m := NewMockSSMClientWith(map[string]string{"/app/config.toml": "host = \"db.local\"\nport = 5432"})
+	var c struct {
+		Config  map[string]string  `ssm:"/app/config.toml,toml"`
+		PConfig *map[string]string `ssm:"/app/config.toml,toml"`
+	}
+	err := Load(m, &c)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"host": "db.local", "port": "5432"}, c.Config)
+	assert.NotNil(t, c.PConfig)
+	assert.Equal(t, c.Config, *c.PConfig)
+
+	var e struct {
+		Config map[string]string `ssm:"string,toml"`
+	}
+	err = Load(m, &e)
+	assert.EqualError(t, err, "toml unmarshal error for field 'Config'")
+
+	var u struct {
+		Test str `ssm:"string,toml"`
+	}
+	err = Load(m, &u)
+	assert.Error(t, err)
+}
+
+func TestUnregisteredFormat(t *testing.T) {
+	var c struct {
+		Config map[string]string `ssm:"string,hcl"`
+	}
+	err := Load(NewMockSSMClient(), &c)
+	assert.EqualError(t, err, "no decoder registered for the 'hcl' option on field Config")
+}
+
+func TestRegisterBuiltinFormat(t *testing.T) {
+	assert.Panics(t, func() {
+		RegisterFormat("json", unmarshalKV)
+	})
+}
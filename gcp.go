@@ -0,0 +1,59 @@
+package figgy
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// GCPSecretManagerAPI is the part of the Google Cloud Secret Manager client used by
+// GCPSecretManager.  The Google Cloud SDK isn't a dependency of figgy, so its client
+// is adapted to this interface.
+type GCPSecretManagerAPI interface {
+	// AccessSecretVersion returns the secret version called name, of the form
+	// projects/*/secrets/*/versions/*, or nil when there's no such secret or version
+	AccessSecretVersion(ctx context.Context, name string) (*GCPSecretVersion, error)
+}
+
+// GCPSecretVersion is a version of a Secret Manager secret
+type GCPSecretVersion struct {
+	// Name is the resolved name of the version, so accessing the latest version
+	// gives the name of the version it refers to
+	Name    string
+	Payload []byte
+}
+
+// GCPSecretManager is a KV of the secrets of a Google Cloud project.  Secret names
+// can't contain slashes, so a key's slashes are replaced by dashes and the tag
+// `ssm:"/myapp/db/password"` loads the latest version of the secret myapp-db-password.
+// Secret Manager has no watch, so Watch checks the secrets that have been loaded every
+// Interval, a minute by default.
+//
+//	kv := &figgy.GCPSecretManager{API: client, Project: "my-project"}
+//	err := figgy.Load(figgy.NewKVClient(kv), &cfg)
+type GCPSecretManager struct {
+	API      GCPSecretManagerAPI
+	Project  string
+	Interval time.Duration
+
+	poll pollingKV
+}
+
+// Get returns the latest version of the secret for key
+func (g *GCPSecretManager) Get(ctx context.Context, key string) (string, int64, bool, error) {
+	return g.poll.get(ctx, key, g.fetch)
+}
+
+// Watch polls the secrets for keys beginning with prefix until one changes
+func (g *GCPSecretManager) Watch(ctx context.Context, prefix string, version int64) ([]string, int64, error) {
+	return g.poll.watch(ctx, prefix, version, g.Interval, g.fetch)
+}
+
+func (g *GCPSecretManager) fetch(ctx context.Context, key string) (string, string, bool, error) {
+	name := fmt.Sprintf("projects/%s/secrets/%s/versions/latest", g.Project, secretName(key))
+	v, err := g.API.AccessSecretVersion(ctx, name)
+	if err != nil || v == nil {
+		return "", "", false, err
+	}
+	return string(v.Payload), v.Name, true, nil
+}
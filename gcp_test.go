@@ -0,0 +1,37 @@
+package figgy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockSecretManager map[string]string
+
+func (m mockSecretManager) AccessSecretVersion(ctx context.Context, name string) (*GCPSecretVersion, error) {
+	v, ok := m[name]
+	if !ok {
+		return nil, nil
+	}
+	return &GCPSecretVersion{Name: name, Payload: []byte(v)}, nil
+}
+
+func TestGCPSecretManager(t *testing.T) {
+	m := mockSecretManager{
+		"projects/p/secrets/app-host/versions/latest":     "a",
+		"projects/p/secrets/app-password/versions/latest": "p",
+		"projects/p/secrets/app-port/versions/latest":     "1",
+	}
+	kv := &GCPSecretManager{API: m, Project: "p"}
+	var cfg WatchConfig
+	assert.NoError(t, Load(NewKVClient(kv), &cfg))
+	assert.Equal(t, "a", cfg.Host)
+	assert.Equal(t, "p", cfg.Password)
+	assert.Equal(t, 1, cfg.Nested.Port)
+
+	_, version, ok, err := kv.Get(context.Background(), "/app/host")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), version)
+}
@@ -0,0 +1,60 @@
+package figgy
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// ParameterRequest is a parameter to fetch with FetchParameters
+type ParameterRequest struct {
+	// Key of the parameter
+	Key string
+	// Decrypt loads the parameter with decryption
+	Decrypt bool
+}
+
+// FetchParameters fetches parameter values keyed by name, batching requests the same
+// way as Load.  It fails when any parameter is missing.
+//
+// FetchParameters is used by code generated by figgygen, which assigns the values
+// without reflection.
+func FetchParameters(c ssmiface.SSMAPI, reqs []ParameterRequest) (map[string]string, error) {
+	f := make([]*field, len(reqs))
+	for i, r := range reqs {
+		f[i] = newField(r.Key, r.Decrypt)
+	}
+	values := make(map[string]string, len(reqs))
+	plain, decrypt := partitionFields(f, decrypted(f))
+	for _, g := range []struct {
+		f       []*field
+		decrypt bool
+	}{{plain, false}, {decrypt, true}} {
+		decrypt := g.decrypt
+		err := batchIterateFields(groupFields(g.f), maxParameters, func(f []*field) error {
+			params, err := getParameters(c, f, decrypt)
+			if err != nil {
+				return err
+			}
+			idx := indexParameters(params)
+			for _, x := range f {
+				p, ok := idx[x.key]
+				if !ok {
+					return fmt.Errorf("failed to load parameter for key '%s'", x.key)
+				}
+				values[x.key] = aws.StringValue(p.Value)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// ExpandKey performs parameter substitution on a key the same way as LoadWithParameters
+func ExpandKey(key string, data interface{}) string {
+	return expandKey(key, data)
+}
@@ -0,0 +1,33 @@
+package figgy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchParameters(t *testing.T) {
+	reqs := []ParameterRequest{
+		{Key: "string"},
+		{Key: "int", Decrypt: true},
+		{Key: "string"},
+	}
+	for _, k := range []string{"int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr"} {
+		reqs = append(reqs, ParameterRequest{Key: k})
+	}
+	m := &countingSSMClient{MockSSMClient: NewMockSSMClient()}
+	values, err := FetchParameters(m, reqs)
+	assert.NoError(t, err)
+	assert.Len(t, values, 12)
+	assert.Equal(t, "this is a string", values["string"])
+	assert.Equal(t, "2", values["int"])
+	assert.Equal(t, 3, m.calls)
+
+	_, err = FetchParameters(m, []ParameterRequest{{Key: "/no/such/param"}})
+	assert.Error(t, err)
+}
+
+func TestExpandKey(t *testing.T) {
+	assert.Equal(t, "/app/dev/key", ExpandKey("/app/{{.env}}/key", P{"env": "dev"}))
+	assert.Equal(t, "/app/{{.env/key", ExpandKey("/app/{{.env/key", P{"env": "dev"}))
+}
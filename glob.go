@@ -0,0 +1,110 @@
+package figgy
+
+import (
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// isGlob reports whether key has wildcards, matching the parameters whose names
+// match it as path.Match does, such as /app/*/endpoint
+func isGlob(key string) bool {
+	return strings.Contains(key, "*") && !isARN(key)
+}
+
+// isGlobType reports whether fields of type t can have a key with wildcards, which
+// loads a map keyed by the segments matching the wildcards or a slice of the values
+// in order of name
+func isGlobType(t reflect.Type) bool {
+	return isTreeType(t) || t.Kind() == reflect.Slice && t != rawType
+}
+
+// loadGlobs loads the fields whose keys have wildcards from the parameters matching
+// them, found with DescribeParameters
+func loadGlobs(c ssmiface.SSMAPI, f []*field, o *options) error {
+	for _, x := range f {
+		names, err := globNames(c, x.key)
+		if err != nil {
+			return err
+		}
+		o.logger.Debug("figgy: requesting parameters matching key", "field", x.field.Name, "key", x.key, "names", names, "decrypt", x.decrypt)
+		var params []*ssm.Parameter
+		for i := 0; i < len(names); i += o.batchSize {
+			j := i + o.batchSize
+			if j > len(names) {
+				j = len(names)
+			}
+			res, err := c.GetParameters(&ssm.GetParametersInput{
+				Names:          aws.StringSlice(names[i:j]),
+				WithDecryption: aws.Bool(x.decrypt),
+			})
+			if err != nil {
+				return err
+			}
+			params = append(params, res.Parameters...)
+		}
+		sort.Slice(params, func(i, j int) bool {
+			return aws.StringValue(params[i].Name) < aws.StringValue(params[j].Name)
+		})
+		err = setCollection(x, params, func(name string) string {
+			return globKey(x.key, name)
+		})
+		if err != nil {
+			o.metrics.ParameterFailed(x.key)
+			return err
+		}
+		x.loaded = true
+	}
+	return nil
+}
+
+// globNames returns the sorted names of the parameters matching pattern, describing
+// those that begin with the part of the pattern before its first wildcard
+func globNames(c ssmiface.SSMAPI, pattern string) ([]string, error) {
+	in := &ssm.DescribeParametersInput{
+		MaxResults: aws.Int64(maxDescribeParameters),
+		ParameterFilters: []*ssm.ParameterStringFilter{{
+			Key:    aws.String(ssm.ParametersFilterKeyName),
+			Option: aws.String("BeginsWith"),
+			Values: aws.StringSlice([]string{pattern[:strings.Index(pattern, "*")]}),
+		}},
+	}
+	var names []string
+	for {
+		res, err := c.DescribeParameters(in)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range res.Parameters {
+			name := aws.StringValue(p.Name)
+			if ok, _ := path.Match(pattern, name); ok {
+				names = append(names, name)
+			}
+		}
+		if aws.StringValue(res.NextToken) == "" {
+			break
+		}
+		in.NextToken = res.NextToken
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// globKey returns the segments of name matching the segments of pattern with
+// wildcards, joined with slashes, such as "tenant-a" for /app/tenant-a/endpoint
+// matching /app/*/endpoint
+func globKey(pattern, name string) string {
+	p, n := strings.Split(pattern, "/"), strings.Split(name, "/")
+	var key []string
+	for i := range p {
+		if i < len(n) && strings.Contains(p[i], "*") {
+			key = append(key, n[i])
+		}
+	}
+	return strings.Join(key, "/")
+}
@@ -0,0 +1,40 @@
+package figgy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadGlob(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{
+		"/app/tenant-a/endpoint":     "https://a",
+		"/app/tenant-b/endpoint":     "https://b",
+		"/app/tenant-b/token":        "t",
+		"/app/tenant-c/nested/x":     "x",
+		"/app/prod/tenant-a/timeout": "1s",
+		"/app/prod/tenant-b/timeout": "2s",
+	})
+	var cfg struct {
+		Endpoints map[string]string `ssm:"/app/*/endpoint"`
+		List      []string          `ssm:"/app/*/endpoint"`
+		Timeouts  map[string]string `ssm:"/app/*/*/timeout"`
+		None      map[string]string `ssm:"/app/*/missing"`
+	}
+	assert.NoError(t, Load(m, &cfg))
+	assert.Equal(t, map[string]string{"tenant-a": "https://a", "tenant-b": "https://b"}, cfg.Endpoints)
+	assert.Equal(t, []string{"https://a", "https://b"}, cfg.List)
+	assert.Equal(t, map[string]string{"prod/tenant-a": "1s", "prod/tenant-b": "2s"}, cfg.Timeouts)
+	assert.Equal(t, map[string]string{}, cfg.None)
+
+	var scalar struct {
+		Endpoint string `ssm:"/app/*/endpoint"`
+	}
+	assert.IsType(t, &TagParseError{}, Load(m, &scalar))
+}
+
+func TestGlobKey(t *testing.T) {
+	assert.Equal(t, "tenant-a", globKey("/app/*/endpoint", "/app/tenant-a/endpoint"))
+	assert.Equal(t, "a/b", globKey("/app/*/x/*", "/app/a/x/b"))
+	assert.Equal(t, "tenant-a", globKey("/app/tenant-*/endpoint", "/app/tenant-a/endpoint"))
+}
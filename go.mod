@@ -3,7 +3,8 @@ module github.com/Syncbak-Git/go-figgy
 require (
 	github.com/aws/aws-sdk-go v1.23.13
 	github.com/stretchr/testify v1.4.0
-	golang.org/x/net v0.0.0-20190827160401-ba9fcec4b297 // indirect
+	golang.org/x/tools v0.1.0
+	gopkg.in/yaml.v2 v2.2.2
 )
 
-go 1.13
+go 1.15
@@ -0,0 +1,38 @@
+package figgy
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// WatcherStatus describes the health of a Watcher
+type WatcherStatus struct {
+	Healthy     bool             `json:"healthy"`
+	LastRefresh time.Time        `json:"lastRefresh"`
+	LastError   string           `json:"lastError,omitempty"`
+	Versions    map[string]int64 `json:"versions,omitempty"`
+}
+
+// HealthHandler returns an HTTP handler for readiness and liveness probes that
+// responds with the watcher's status as JSON.  The status code is 503 Service
+// Unavailable when the last check for changes failed, or when the struct hasn't been
+// refreshed within maxAge, and 200 OK otherwise.  A maxAge of 0 skips the age check.
+func (w *Watcher) HealthHandler(maxAge time.Duration) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		s := WatcherStatus{
+			LastRefresh: w.LastRefresh(),
+			Versions:    w.CurrentVersions(),
+		}
+		err := w.LastError()
+		if err != nil {
+			s.LastError = err.Error()
+		}
+		s.Healthy = err == nil && (maxAge == 0 || time.Since(s.LastRefresh) <= maxAge)
+		rw.Header().Set("Content-Type", "application/json")
+		if !s.Healthy {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(rw).Encode(s)
+	})
+}
@@ -0,0 +1,49 @@
+package figgy
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthHandler(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/host": "a", "/app/password": "p", "/app/port": "1"})
+	n := make(chanNotifier)
+	var cfg WatchConfig
+	w, err := Watch(m, &cfg, nil, n)
+	assert.NoError(t, err)
+	defer w.Stop()
+	start := w.LastRefresh()
+	assert.False(t, start.IsZero())
+
+	setParameter(m, "/app/host", "a")
+	n <- nil
+	n.settle()
+	assert.True(t, w.LastRefresh().After(start))
+	assert.Equal(t, map[string]int64{"/app/host": 1, "/app/password": 0, "/app/port": 0}, w.CurrentVersions())
+
+	rec := httptest.NewRecorder()
+	w.HealthHandler(time.Minute).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var s WatcherStatus
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &s))
+	assert.True(t, s.Healthy)
+	assert.Equal(t, w.CurrentVersions(), s.Versions)
+
+	rec = httptest.NewRecorder()
+	w.HealthHandler(time.Nanosecond).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	w.setStatus(errors.New("refresh failed"))
+	rec = httptest.NewRecorder()
+	w.HealthHandler(0).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &s))
+	assert.False(t, s.Healthy)
+	assert.Equal(t, "refresh failed", s.LastError)
+}
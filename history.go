@@ -0,0 +1,120 @@
+package figgy
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// Revision is a previous value of a parameter
+type Revision struct {
+	// Value decoded to the field's type
+	Value interface{}
+	// Version of the parameter
+	Version int64
+	// LastModified is when this version was written
+	LastModified time.Time
+	// LastModifiedUser is the ARN of the user that wrote this version
+	LastModifiedUser string
+}
+
+// History returns up to n of the most recent values, newest first, of the parameter
+// loaded into a field.  The field is given as a pointer to a field of the struct v,
+// for example:
+//
+//	revs, err := figgy.History(c, &cfg, &cfg.Timeout, 5)
+//
+// Each value is decoded to the field's type the same way as Load.
+func History(c ssmiface.SSMAPI, v interface{}, fieldPtr interface{}, n int, opts ...Option) ([]Revision, error) {
+	return HistoryWithParameters(c, v, nil, fieldPtr, n, opts...)
+}
+
+// HistoryWithParameters returns the most recent values of the parameter loaded into a
+// field, performing parameter substitution on field tags the same way as LoadWithParameters.
+func HistoryWithParameters(c ssmiface.SSMAPI, v interface{}, data interface{}, fieldPtr interface{}, n int, opts ...Option) ([]Revision, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return nil, &InvalidTypeError{Type: reflect.TypeOf(v)}
+	}
+	fp := reflect.ValueOf(fieldPtr)
+	if fp.Kind() != reflect.Ptr || fp.IsNil() {
+		return nil, &InvalidTypeError{Type: reflect.TypeOf(fieldPtr)}
+	}
+	f, err := inspect(rv.Elem(), rv.Elem().Type(), data, "")
+	if err != nil {
+		return nil, err
+	}
+	x := findField(f, fp)
+	if x == nil {
+		return nil, errors.New("field is not a tagged field of the struct")
+	}
+	if x.chunks {
+		return nil, fmt.Errorf("cannot get the history of field %s using the 'chunks' option", x.name)
+	}
+	if x.object {
+		return nil, fmt.Errorf("cannot get the history of field %s loaded from S3", x.name)
+	}
+	if x.tree {
+		return nil, fmt.Errorf("cannot get the history of field %s using the 'path' option", x.name)
+	}
+	if x.glob {
+		return nil, fmt.Errorf("cannot get the history of field %s with a wildcard key", x.name)
+	}
+	o := newOptions(opts)
+	o.normalizeKeys([]*field{x})
+	hist, err := parameterHistory(c, x.key, x.decrypt)
+	if err != nil {
+		return nil, err
+	}
+	var revs []Revision
+	for i := len(hist) - 1; i >= 0 && len(revs) < n; i-- {
+		h := hist[i]
+		value, err := decodeField(c, x, aws.StringValue(h.Value), o)
+		if err != nil {
+			return nil, fmt.Errorf("version %d: %v", aws.Int64Value(h.Version), err)
+		}
+		revs = append(revs, Revision{
+			Value:            value,
+			Version:          aws.Int64Value(h.Version),
+			LastModified:     aws.TimeValue(h.LastModifiedDate),
+			LastModifiedUser: aws.StringValue(h.LastModifiedUser),
+		})
+	}
+	return revs, nil
+}
+
+// findField finds the field whose value is addressed by ptr
+func findField(f []*field, ptr reflect.Value) *field {
+	for _, x := range f {
+		if x.value.IsValid() && x.value.CanAddr() &&
+			x.value.Addr().Pointer() == ptr.Pointer() && x.value.Type() == ptr.Type().Elem() {
+			return x
+		}
+	}
+	return nil
+}
+
+// parameterHistory fetches every version of a parameter, oldest first
+func parameterHistory(c ssmiface.SSMAPI, key string, decrypt bool) ([]*ssm.ParameterHistory, error) {
+	var hist []*ssm.ParameterHistory
+	in := &ssm.GetParameterHistoryInput{
+		Name:           aws.String(key),
+		WithDecryption: aws.Bool(decrypt),
+	}
+	for {
+		res, err := c.GetParameterHistory(in)
+		if err != nil {
+			return nil, err
+		}
+		hist = append(hist, res.Parameters...)
+		if aws.StringValue(res.NextToken) == "" {
+			return hist, nil
+		}
+		in.NextToken = res.NextToken
+	}
+}
@@ -0,0 +1,102 @@
+package figgy
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/stretchr/testify/assert"
+)
+
+type historySSMClient struct {
+	*MockSSMClient
+	History map[string][]*ssm.ParameterHistory
+}
+
+// GetParameterHistory returns two versions per page, oldest first
+func (c *historySSMClient) GetParameterHistory(i *ssm.GetParameterHistoryInput) (*ssm.GetParameterHistoryOutput, error) {
+	h, ok := c.History[aws.StringValue(i.Name)]
+	if !ok {
+		return nil, awserr.New(ssm.ErrCodeParameterNotFound, "parameter not found", nil)
+	}
+	start, _ := strconv.Atoi(aws.StringValue(i.NextToken))
+	end := start + 2
+	out := &ssm.GetParameterHistoryOutput{}
+	if end < len(h) {
+		out.NextToken = aws.String(strconv.Itoa(end))
+	} else {
+		end = len(h)
+	}
+	out.Parameters = h[start:end]
+	return out, nil
+}
+
+func newHistorySSMClient() *historySSMClient {
+	modified := time.Date(2019, 9, 1, 0, 0, 0, 0, time.UTC)
+	var h []*ssm.ParameterHistory
+	for i, v := range []string{"1s", "2s", "3s", "4s", "5s"} {
+		h = append(h, &ssm.ParameterHistory{
+			Name:             aws.String("/app/timeout"),
+			Value:            aws.String(v),
+			Version:          aws.Int64(int64(i + 1)),
+			LastModifiedDate: aws.Time(modified.Add(time.Duration(i) * time.Hour)),
+			LastModifiedUser: aws.String("arn:aws:iam::123456789012:user/ops"),
+		})
+	}
+	return &historySSMClient{
+		MockSSMClient: NewMockSSMClient(),
+		History:       map[string][]*ssm.ParameterHistory{"/app/timeout": h},
+	}
+}
+
+func TestHistory(t *testing.T) {
+	var c struct {
+		Name   string
+		Nested struct {
+			Timeout time.Duration `ssm:"/{{.env}}/timeout"`
+		}
+	}
+	m := newHistorySSMClient()
+	revs, err := HistoryWithParameters(m, &c, P{"env": "app"}, &c.Nested.Timeout, 3)
+	assert.NoError(t, err)
+	assert.Len(t, revs, 3)
+	assert.Equal(t, 5*time.Second, revs[0].Value)
+	assert.Equal(t, int64(5), revs[0].Version)
+	assert.Equal(t, time.Date(2019, 9, 1, 4, 0, 0, 0, time.UTC), revs[0].LastModified)
+	assert.Equal(t, "arn:aws:iam::123456789012:user/ops", revs[0].LastModifiedUser)
+	assert.Equal(t, 3*time.Second, revs[2].Value)
+	assert.Equal(t, time.Duration(0), c.Nested.Timeout)
+
+	revs, err = HistoryWithParameters(m, &c, P{"env": "app"}, &c.Nested.Timeout, 10)
+	assert.NoError(t, err)
+	assert.Len(t, revs, 5)
+}
+
+func TestHistoryErrors(t *testing.T) {
+	var c struct {
+		Untagged string
+		Timeout  time.Duration `ssm:"/app/timeout"`
+		Missing  string        `ssm:"/no/such/param"`
+		Chunked  string        `ssm:"/app/chunked,chunks"`
+	}
+	m := newHistorySSMClient()
+	tests := map[string]struct {
+		v     interface{}
+		field interface{}
+	}{
+		"non ptr struct":  {v: c, field: &c.Timeout},
+		"non ptr field":   {v: &c, field: c.Timeout},
+		"untagged field":  {v: &c, field: &c.Untagged},
+		"other variable":  {v: &c, field: new(time.Duration)},
+		"missing":         {v: &c, field: &c.Missing},
+		"chunks":          {v: &c, field: &c.Chunked},
+		"field of struct": {v: &struct{}{}, field: &c.Timeout},
+	}
+	for n, tc := range tests {
+		_, err := History(m, tc.v, tc.field, 1)
+		assert.Error(t, err, "test '%s' failed", n)
+	}
+}
@@ -0,0 +1,137 @@
+package figgy
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// policyDocument is an IAM policy document
+type policyDocument struct {
+	Version   string
+	Statement []policyStatement
+}
+
+type policyStatement struct {
+	Effect    string
+	Action    []string
+	Resource  []string
+	Condition map[string]map[string][]string `json:",omitempty"`
+}
+
+// IAMPolicy returns the least privileged IAM policy, as JSON, that allows Load to load
+// the struct v in the account and region given.  Tag substitution uses data, as with
+// LoadWithParameters.  It allows getting the exact parameters named by the tags, and
+// decrypting them through SSM when fields have the decrypt option.  Fields with an s3
+// tag allow getting their objects.
+//
+// Map fields with the bare path option are allowed ssm:GetParametersByPath on their key,
+// and fields with wildcard keys are allowed ssm:DescribeParameters to find their
+// parameters.
+//
+// Watchers, WithPolicyReport and WithRequiredKMSKey also need ssm:DescribeParameters,
+// and WithPathThreshold needs ssm:GetParametersByPath, which aren't included.  Without
+// ssm:GetParametersByPath, errors for missing parameters don't suggest similar names.
+func IAMPolicy(v interface{}, data interface{}, accountID, region string) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return nil, &InvalidTypeError{Type: reflect.TypeOf(v)}
+	}
+	f, err := inspect(rv.Elem(), rv.Elem().Type(), data, "")
+	if err != nil {
+		return nil, err
+	}
+	params := make(map[string]bool)
+	paths := make(map[string]bool)
+	globs := false
+	objects := make(map[string]bool)
+	decrypt := make(map[string]bool)
+	for _, x := range f {
+		if x.object {
+			objects["arn:aws:s3:::"+x.key] = true
+			continue
+		}
+		r := region
+		if x.region != "" {
+			r = x.region
+		}
+		arn := parameterARN(x.key, accountID, r)
+		if x.chunks {
+			arn += "/part-*"
+		}
+		globs = globs || x.glob
+		if x.tree {
+			paths[strings.TrimSuffix(arn, "/")] = true
+		} else {
+			params[arn] = true
+		}
+		if x.old != "" {
+			params[parameterARN(x.old, accountID, r)] = true
+		}
+		if x.decrypt {
+			if isARN(x.key) {
+				r = strings.Split(x.key, ":")[3]
+			}
+			decrypt[fmt.Sprintf("ssm.%s.amazonaws.com", r)] = true
+		}
+	}
+	doc := policyDocument{Version: "2012-10-17"}
+	if len(params) > 0 {
+		doc.Statement = append(doc.Statement, policyStatement{
+			Effect:   "Allow",
+			Action:   []string{"ssm:GetParameter", "ssm:GetParameters"},
+			Resource: sortedKeys(params),
+		})
+	}
+	if len(paths) > 0 {
+		doc.Statement = append(doc.Statement, policyStatement{
+			Effect:   "Allow",
+			Action:   []string{"ssm:GetParametersByPath"},
+			Resource: sortedKeys(paths),
+		})
+	}
+	if globs {
+		doc.Statement = append(doc.Statement, policyStatement{
+			Effect:   "Allow",
+			Action:   []string{"ssm:DescribeParameters"},
+			Resource: []string{"*"},
+		})
+	}
+	if len(decrypt) > 0 {
+		doc.Statement = append(doc.Statement, policyStatement{
+			Effect:   "Allow",
+			Action:   []string{"kms:Decrypt"},
+			Resource: []string{"*"},
+			Condition: map[string]map[string][]string{
+				"StringEquals": {"kms:ViaService": sortedKeys(decrypt)},
+			},
+		})
+	}
+	if len(objects) > 0 {
+		doc.Statement = append(doc.Statement, policyStatement{
+			Effect:   "Allow",
+			Action:   []string{"s3:GetObject"},
+			Resource: sortedKeys(objects),
+		})
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// parameterARN returns the ARN of the parameter for key, which may already be an ARN
+func parameterARN(key, accountID, region string) string {
+	if isARN(key) {
+		return key
+	}
+	return fmt.Sprintf("arn:aws:ssm:%s:%s:parameter/%s", region, accountID, strings.TrimPrefix(key, "/"))
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
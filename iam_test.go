@@ -0,0 +1,56 @@
+package figgy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIAMPolicy(t *testing.T) {
+	var cfg struct {
+		Host     string          `ssm:"/{{.env}}/db/host"`
+		Password string          `ssm:"/{{.env}}/db/password,decrypt"`
+		Standby  string          `ssm:"/{{.env}}/db/host,region=us-west-2"`
+		Shared   string          `ssm:"arn:aws:ssm:eu-west-1:210987654321:parameter/shared/key,decrypt"`
+		Settings string          `ssm:"/{{.env}}/settings,chunks"`
+		Routes   string          `s3:"config/{{.env}}/routes.json"`
+		Features map[string]bool `ssm:"/{{.env}}/features/,path,decrypt"`
+	}
+	b, err := IAMPolicy(&cfg, map[string]string{"env": "prod"}, "123456789012", "us-east-1")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"Version": "2012-10-17",
+		"Statement": [
+			{
+				"Effect": "Allow",
+				"Action": ["ssm:GetParameter", "ssm:GetParameters"],
+				"Resource": [
+					"arn:aws:ssm:eu-west-1:210987654321:parameter/shared/key",
+					"arn:aws:ssm:us-east-1:123456789012:parameter/prod/db/host",
+					"arn:aws:ssm:us-east-1:123456789012:parameter/prod/db/password",
+					"arn:aws:ssm:us-east-1:123456789012:parameter/prod/settings/part-*",
+					"arn:aws:ssm:us-west-2:123456789012:parameter/prod/db/host"
+				]
+			},
+			{
+				"Effect": "Allow",
+				"Action": ["ssm:GetParametersByPath"],
+				"Resource": ["arn:aws:ssm:us-east-1:123456789012:parameter/prod/features"]
+			},
+			{
+				"Effect": "Allow",
+				"Action": ["kms:Decrypt"],
+				"Resource": ["*"],
+				"Condition": {"StringEquals": {"kms:ViaService": ["ssm.eu-west-1.amazonaws.com", "ssm.us-east-1.amazonaws.com"]}}
+			},
+			{
+				"Effect": "Allow",
+				"Action": ["s3:GetObject"],
+				"Resource": ["arn:aws:s3:::config/prod/routes.json"]
+			}
+		]
+	}`, string(b))
+
+	_, err = IAMPolicy(cfg, nil, "123456789012", "us-east-1")
+	assert.Error(t, err)
+}
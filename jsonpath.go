@@ -0,0 +1,104 @@
+package figgy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// setPath extracts the value at the field's path from a JSON document and sets it.
+// Scalar values are converted the same way as a plain parameter value, while
+// objects and arrays are decoded as JSON.
+func setPath(f *field, s string) error {
+	var doc interface{}
+	d := json.NewDecoder(strings.NewReader(s))
+	d.UseNumber()
+	if err := d.Decode(&doc); err != nil {
+		return fmt.Errorf("json unmarshal error for field '%s'", f.field.Name)
+	}
+	x, err := evalPath(doc, f.path)
+	if err != nil {
+		return fmt.Errorf("%v for field '%s'", err, f.field.Name)
+	}
+	g := *f
+	g.path = ""
+	switch x := x.(type) {
+	case string:
+		g.json = false
+		return set(&g, x)
+	case json.Number:
+		g.json = false
+		return set(&g, x.String())
+	case bool:
+		g.json = false
+		return set(&g, strconv.FormatBool(x))
+	}
+	b := &bytes.Buffer{}
+	if err := json.NewEncoder(b).Encode(x); err != nil {
+		return err
+	}
+	return set(&g, b.String())
+}
+
+// evalPath evaluates a simple JSONPath expression, such as $.database.hosts[0], against
+// a decoded JSON document.  Only child members, by name or in brackets, and array
+// indexes are supported.
+func evalPath(doc interface{}, path string) (interface{}, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("path '%s' must start with '$'", path)
+	}
+	p := path[1:]
+	x := doc
+	for p != "" {
+		var member string
+		var index = -1
+		switch p[0] {
+		case '.':
+			p = p[1:]
+			i := strings.IndexAny(p, ".[")
+			if i < 0 {
+				i = len(p)
+			}
+			member, p = p[:i], p[i:]
+			if member == "" {
+				return nil, fmt.Errorf("invalid path '%s'", path)
+			}
+		case '[':
+			i := strings.Index(p, "]")
+			if i < 0 {
+				return nil, fmt.Errorf("invalid path '%s'", path)
+			}
+			sel := p[1:i]
+			p = p[i+1:]
+			if len(sel) >= 2 && (sel[0] == '\'' || sel[0] == '"') && sel[len(sel)-1] == sel[0] {
+				member = sel[1 : len(sel)-1]
+				break
+			}
+			n, err := strconv.Atoi(sel)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid index '%s' in path '%s'", sel, path)
+			}
+			index = n
+		default:
+			return nil, fmt.Errorf("invalid path '%s'", path)
+		}
+		if index >= 0 {
+			a, ok := x.([]interface{})
+			if !ok || index >= len(a) {
+				return nil, fmt.Errorf("path '%s' not found", path)
+			}
+			x = a[index]
+			continue
+		}
+		m, ok := x.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("path '%s' not found", path)
+		}
+		if x, ok = m[member]; !ok {
+			return nil, fmt.Errorf("path '%s' not found", path)
+		}
+	}
+	return x, nil
+}
@@ -0,0 +1,123 @@
+package figgy
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/stretchr/testify/assert"
+)
+
+type countingSSMClient struct {
+	*MockSSMClient
+	calls   int
+	names   int
+	batches []int
+	decrypt []bool
+	paths   []string
+}
+
+func (c *countingSSMClient) GetParameters(i *ssm.GetParametersInput) (*ssm.GetParametersOutput, error) {
+	c.calls++
+	c.names += len(i.Names)
+	c.batches = append(c.batches, len(i.Names))
+	c.decrypt = append(c.decrypt, aws.BoolValue(i.WithDecryption))
+	return c.MockSSMClient.GetParameters(i)
+}
+
+func (c *countingSSMClient) GetParametersByPath(i *ssm.GetParametersByPathInput) (*ssm.GetParametersByPathOutput, error) {
+	c.paths = append(c.paths, *i.Path)
+	return c.MockSSMClient.GetParametersByPath(i)
+}
+
+const pathDocument = `{
+	"database": {"host": "db.local", "port": 5432, "timeout": "5s", "replicas": ["r1", "r2"]},
+	"debug": true,
+	"weird.key": {"value": 1.5}
+}`
+
+func TestPath(t *testing.T) {
+	m := &countingSSMClient{MockSSMClient: NewMockSSMClientWith(map[string]string{"/app/config": pathDocument})}
+	var c struct {
+		Host     string        `ssm:"/app/config,json,path=$.database.host"`
+		Port     int           `ssm:"/app/config,json,path=$.database.port"`
+		Timeout  time.Duration `ssm:"/app/config,json,path=$.database.timeout"`
+		Replica  string        `ssm:"/app/config,json,path=$.database.replicas[1]"`
+		Replicas []string      `ssm:"/app/config,json,path=$.database.replicas"`
+		Debug    bool          `ssm:"/app/config,json,path=$.debug"`
+		Weird    float64       `ssm:"/app/config,json,path=$['weird.key'].value"`
+		Database struct {
+			Host string
+			Port int
+		} `ssm:"/app/config,json,path=$.database"`
+	}
+	err := Load(m, &c)
+	assert.NoError(t, err)
+	assert.Equal(t, "db.local", c.Host)
+	assert.Equal(t, 5432, c.Port)
+	assert.Equal(t, 5*time.Second, c.Timeout)
+	assert.Equal(t, "r2", c.Replica)
+	assert.Equal(t, []string{"r1", "r2"}, c.Replicas)
+	assert.Equal(t, true, c.Debug)
+	assert.Equal(t, 1.5, c.Weird)
+	assert.Equal(t, "db.local", c.Database.Host)
+	assert.Equal(t, 5432, c.Database.Port)
+	assert.Equal(t, 1, m.calls)
+	assert.Equal(t, 1, m.names)
+}
+
+func TestPathErrors(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/config": pathDocument})
+	tests := map[string]interface{}{
+		"missing member": &struct {
+			Host string `ssm:"/app/config,json,path=$.database.nope"`
+		}{},
+		"index out of range": &struct {
+			Host string `ssm:"/app/config,json,path=$.database.replicas[2]"`
+		}{},
+		"bad convert": &struct {
+			Port int `ssm:"/app/config,json,path=$.database.host"`
+		}{},
+		"not json": &struct {
+			Host string `ssm:"string,json,path=$.database.host"`
+		}{},
+		"without json": &struct {
+			Host string `ssm:"/app/config,path=$.database.host"`
+		}{},
+	}
+	for n, in := range tests {
+		err := Load(m, in)
+		assert.Error(t, err, "test '%s' failed", n)
+	}
+}
+
+func TestEvalPath(t *testing.T) {
+	var doc interface{}
+	err := json.Unmarshal([]byte(`{"a": {"b": [{"c": "d"}]}, "e.f": "g"}`), &doc)
+	assert.NoError(t, err)
+	tests := map[string]struct {
+		path string
+		want interface{}
+		err  bool
+	}{
+		"root":         {path: "$", want: doc},
+		"member":       {path: "$.a.b[0].c", want: "d"},
+		"bracket":      {path: `$["e.f"]`, want: "g"},
+		"no root":      {path: "a.b", err: true},
+		"empty member": {path: "$..a", err: true},
+		"bad index":    {path: "$.a.b[x]", err: true},
+		"unterminated": {path: "$.a.b[0", err: true},
+		"not object":   {path: "$.a.b.c", err: true},
+	}
+	for n, tc := range tests {
+		x, err := evalPath(doc, tc.path)
+		if tc.err {
+			assert.Error(t, err, "test '%s' failed", n)
+			continue
+		}
+		assert.NoError(t, err, "test '%s' failed", n)
+		assert.Equal(t, tc.want, x, "test '%s' failed", n)
+	}
+}
@@ -0,0 +1,56 @@
+package figgy
+
+import (
+	"strings"
+)
+
+// WithCleanKeys collapses repeated slashes and trims trailing slashes from the keys of
+// the fields once their templates are expanded, so composing a key from a prefix and a
+// name, as in `ssm:"{{.prefix}}/{{.env}}/db/host"` with a prefix that ends in a slash,
+// loads /app/prod/db/host rather than /app//prod/db/host.
+func WithCleanKeys() Option {
+	return func(o *options) {
+		o.cleanKeys = true
+	}
+}
+
+// WithLowercaseKeys lowercases the keys of the fields once their templates are
+// expanded, for template data that doesn't match the case of the parameter names.
+func WithLowercaseKeys() Option {
+	return func(o *options) {
+		o.lowercaseKeys = true
+	}
+}
+
+// normalizeKeys cleans the keys of the fields as the options ask.  The keys of S3
+// objects are left alone, since they're case sensitive and may contain "//".
+func (o *options) normalizeKeys(f []*field) {
+	if !o.cleanKeys && !o.lowercaseKeys {
+		return
+	}
+	for _, x := range f {
+		if x.object {
+			continue
+		}
+		x.key = o.normalizeKey(x.key)
+		if x.old != "" {
+			x.old = o.normalizeKey(x.old)
+		}
+	}
+}
+
+// normalizeKey returns key cleaned as the options ask
+func (o *options) normalizeKey(key string) string {
+	if o.cleanKeys {
+		for strings.Contains(key, "//") {
+			key = strings.Replace(key, "//", "/", -1)
+		}
+		if k := strings.TrimRight(key, "/"); k != "" {
+			key = k
+		}
+	}
+	if o.lowercaseKeys {
+		key = strings.ToLower(key)
+	}
+	return key
+}
@@ -0,0 +1,73 @@
+package figgy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithCleanKeys(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/prod/host": "h", "/app/prod/port": "1"})
+	var cfg struct {
+		Host string `ssm:"{{.prefix}}/{{.env}}/host"`
+		Port int    `ssm:"{{.prefix}}/{{.env}}//port/"`
+	}
+	data := P{"prefix": "/app/", "env": "prod"}
+	assert.Error(t, LoadWithParameters(m, &cfg, data))
+	assert.NoError(t, LoadWithParameters(m, &cfg, data, WithCleanKeys()))
+	assert.Equal(t, "h", cfg.Host)
+	assert.Equal(t, 1, cfg.Port)
+}
+
+func TestWithLowercaseKeys(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/prod/host": "h"})
+	var cfg struct {
+		Host string `ssm:"/app/{{.env}}/host"`
+	}
+	assert.NoError(t, LoadWithParameters(m, &cfg, P{"env": "PROD"}, WithLowercaseKeys()))
+	assert.Equal(t, "h", cfg.Host)
+}
+
+func TestWatchCleanKeys(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/prod/host": "h"})
+	var cfg struct {
+		Host string `ssm:"/app//{{.env}}/host"`
+	}
+	n := make(chanNotifier)
+	w, err := Watch(m, &cfg, P{"env": "prod"}, n, WithCleanKeys())
+	assert.NoError(t, err)
+	defer w.Stop()
+	setParameter(m, "/app/prod/host", "h2")
+	n <- []string{"/app/prod/host"}
+	assert.True(t, waitChange(t, w))
+	w.RLock()
+	assert.Equal(t, "h2", cfg.Host)
+	w.RUnlock()
+}
+
+func TestStoreCleanKeys(t *testing.T) {
+	m := NewMockSSMClient()
+	cfg := struct {
+		Host string `ssm:"{{.prefix}}/prod/host"`
+	}{Host: "h"}
+	data := P{"prefix": "/app/"}
+	assert.NoError(t, StoreWithParameters(m, &cfg, data, WithCleanKeys()))
+	_, ok := m.Data["/app/prod/host"]
+	assert.True(t, ok)
+
+	diffs, err := DiffWithParameters(m, &cfg, data, WithCleanKeys())
+	assert.NoError(t, err)
+	assert.Empty(t, diffs)
+
+	deleted, err := DeleteWithParameters(m, &cfg, data, WithCleanKeys())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/app/prod/host"}, deleted)
+}
+
+func TestNormalizeObjectKeys(t *testing.T) {
+	o := newOptions([]Option{WithCleanKeys(), WithLowercaseKeys()})
+	f := []*field{{key: "/App//Host"}, {key: "bucket/Path//Object", object: true}}
+	o.normalizeKeys(f)
+	assert.Equal(t, "/app/host", f[0].key)
+	assert.Equal(t, "bucket/Path//Object", f[1].key)
+}
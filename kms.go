@@ -0,0 +1,73 @@
+package figgy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// WithRequiredKMSKey fails a load when a SecureString parameter loaded into a field
+// with the decrypt option isn't encrypted with one of the KMS keys ids, given as key
+// IDs, ARNs or aliases, such as a parameter accidentally created with the account's
+// default key.  Keys are described with DescribeParameters, which requires the
+// ssm:DescribeParameters permission, and aren't checked for chunks or parameters
+// from other regions or accounts.
+func WithRequiredKMSKey(ids ...string) Option {
+	return func(o *options) {
+		o.kmsKeys = append(o.kmsKeys, ids...)
+	}
+}
+
+// KMSKeyError is returned when a parameter is encrypted with a KMS key other than
+// those required by WithRequiredKMSKey
+type KMSKeyError struct {
+	// Key of the parameter
+	Key string
+	// KeyID of the KMS key the parameter is encrypted with
+	KeyID string
+}
+
+func (e *KMSKeyError) Error() string {
+	return fmt.Sprintf("figgy: parameter %s is encrypted with KMS key %s", e.Key, e.KeyID)
+}
+
+// checkKMSKeys describes the SecureString parameters of the decrypted fields, failing
+// with a KMSKeyError for the first that isn't encrypted with a required key
+func (o *options) checkKMSKeys(c ssmiface.SSMAPI, f []*field) error {
+	if len(o.kmsKeys) == 0 {
+		return nil
+	}
+	_, decrypted := partitionFields(f, func(x *field) bool {
+		return x.decrypt
+	})
+	keys := o.policyKeys(decrypted)
+	metadata, err := describeMetadata(c, keys)
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		p, ok := metadata[k]
+		if !ok || aws.StringValue(p.Type) != ssm.ParameterTypeSecureString {
+			continue
+		}
+		id := aws.StringValue(p.KeyId)
+		if !o.requiredKMSKey(id) {
+			return &KMSKeyError{Key: k, KeyID: id}
+		}
+	}
+	return nil
+}
+
+// requiredKMSKey reports whether id, as described for a parameter, is one of the
+// required keys.  A key ARN matches the key's ID, and an alias ARN matches the alias.
+func (o *options) requiredKMSKey(id string) bool {
+	for _, k := range o.kmsKeys {
+		if id == k || strings.HasSuffix(id, ":"+k) || strings.HasSuffix(id, ":key/"+k) {
+			return true
+		}
+	}
+	return false
+}
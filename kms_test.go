@@ -0,0 +1,67 @@
+package figgy
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/stretchr/testify/assert"
+)
+
+// kmsSSMClient describes parameters with the KMS keys in its map as SecureStrings
+type kmsSSMClient struct {
+	*MockSSMClient
+	keys map[string]string
+}
+
+func (c *kmsSSMClient) DescribeParameters(i *ssm.DescribeParametersInput) (*ssm.DescribeParametersOutput, error) {
+	out, err := c.MockSSMClient.DescribeParameters(i)
+	if err == nil {
+		for _, p := range out.Parameters {
+			if id, ok := c.keys[aws.StringValue(p.Name)]; ok {
+				p.Type = aws.String(ssm.ParameterTypeSecureString)
+				p.KeyId = aws.String(id)
+			}
+		}
+	}
+	return out, err
+}
+
+func TestWithRequiredKMSKey(t *testing.T) {
+	c := &kmsSSMClient{
+		MockSSMClient: NewMockSSMClientWith(map[string]string{"/app/token": "t", "/app/key": "k", "/app/host": "h"}),
+		keys: map[string]string{
+			"/app/token": "arn:aws:kms:us-east-1:123456789012:alias/config",
+			"/app/key":   "alias/aws/ssm",
+		},
+	}
+	var cfg struct {
+		Token string `ssm:"/app/token,decrypt"`
+		Host  string `ssm:"/app/host,decrypt"`
+	}
+	assert.NoError(t, Load(c, &cfg, WithRequiredKMSKey("alias/config")))
+	assert.Equal(t, "t", cfg.Token)
+
+	var all struct {
+		Token string `ssm:"/app/token,decrypt"`
+		Key   string `ssm:"/app/key,decrypt"`
+	}
+	err := Load(c, &all, WithRequiredKMSKey("alias/config"))
+	assert.Equal(t, &KMSKeyError{Key: "/app/key", KeyID: "alias/aws/ssm"}, err)
+	assert.NoError(t, Load(c, &all, WithRequiredKMSKey("alias/config", "alias/aws/ssm")))
+
+	// fields without the decrypt option aren't checked
+	var plain struct {
+		Key string `ssm:"/app/key"`
+	}
+	assert.NoError(t, Load(c, &plain, WithRequiredKMSKey("alias/config")))
+}
+
+func TestRequiredKMSKey(t *testing.T) {
+	o := newOptions([]Option{WithRequiredKMSKey("1234abcd-12ab-34cd-56ef-1234567890ab", "alias/config")})
+	assert.True(t, o.requiredKMSKey("arn:aws:kms:us-east-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab"))
+	assert.True(t, o.requiredKMSKey("alias/config"))
+	assert.True(t, o.requiredKMSKey("arn:aws:kms:us-east-1:123456789012:alias/config"))
+	assert.False(t, o.requiredKMSKey("alias/aws/ssm"))
+	assert.False(t, o.requiredKMSKey("arn:aws:kms:us-east-1:123456789012:alias/other-config"))
+}
@@ -0,0 +1,282 @@
+package figgy
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// KV is a key value store, such as ConsulKV or EtcdKV, that parameters can be loaded
+// from in place of Parameter Store with NewKVClient.  Keys are the keys of the ssm
+// tags, so implementations for stores without a leading slash, such as Consul, trim
+// it.
+type KV interface {
+	// Get returns the value of key and its version, which changes each time the value
+	// does, with ok false when there's no such key
+	Get(ctx context.Context, key string) (value string, version int64, ok bool, err error)
+	// Watch blocks until a key beginning with prefix changes after version, or ctx
+	// is done, returning the changed keys and the current version of the store.  A
+	// nil slice means any key may have changed.  With version 0 it returns the
+	// current version without waiting.  This maps to a blocking query for Consul and
+	// a watch from a revision for etcd.
+	Watch(ctx context.Context, prefix string, version int64) (keys []string, current int64, err error)
+}
+
+// kvSSM serves the requests of a Watcher from a KV
+type kvSSM struct {
+	ssmiface.SSMAPI
+	kv KV
+}
+
+// NewKVClient returns a client that loads parameters from kv rather than from
+// Parameter Store.  It supports loading and watching, but not storing or deleting.
+// Parameters are never decrypted by the client, so the decrypt option has no effect.
+func NewKVClient(kv KV) ssmiface.SSMAPI {
+	return &kvSSM{kv: kv}
+}
+
+func (c *kvSSM) GetParameter(in *ssm.GetParameterInput) (*ssm.GetParameterOutput, error) {
+	return c.GetParameterWithContext(context.Background(), in)
+}
+
+func (c *kvSSM) GetParameterWithContext(ctx aws.Context, in *ssm.GetParameterInput, opts ...request.Option) (*ssm.GetParameterOutput, error) {
+	p, err := c.get(ctx, aws.StringValue(in.Name))
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, awserr.New(ssm.ErrCodeParameterNotFound, "parameter not found", nil)
+	}
+	return &ssm.GetParameterOutput{Parameter: p}, nil
+}
+
+func (c *kvSSM) GetParameters(in *ssm.GetParametersInput) (*ssm.GetParametersOutput, error) {
+	return c.GetParametersWithContext(context.Background(), in)
+}
+
+func (c *kvSSM) GetParametersWithContext(ctx aws.Context, in *ssm.GetParametersInput, opts ...request.Option) (*ssm.GetParametersOutput, error) {
+	out := &ssm.GetParametersOutput{}
+	for _, name := range in.Names {
+		p, err := c.get(ctx, aws.StringValue(name))
+		if err != nil {
+			return nil, err
+		}
+		if p == nil {
+			out.InvalidParameters = append(out.InvalidParameters, name)
+			continue
+		}
+		out.Parameters = append(out.Parameters, p)
+	}
+	return out, nil
+}
+
+// GetParametersByPath fails, since a KV can't list its keys
+func (c *kvSSM) GetParametersByPath(in *ssm.GetParametersByPathInput) (*ssm.GetParametersByPathOutput, error) {
+	return c.GetParametersByPathWithContext(context.Background(), in)
+}
+
+func (c *kvSSM) GetParametersByPathWithContext(ctx aws.Context, in *ssm.GetParametersByPathInput, opts ...request.Option) (*ssm.GetParametersByPathOutput, error) {
+	return nil, awserr.New("UnsupportedOperation", "parameters can't be listed by path from a KV", nil)
+}
+
+// DescribeParameters describes the versions of the keys named by filters with the
+// Equals option.  Other filters aren't supported, so watchers reload their fields.
+func (c *kvSSM) DescribeParameters(in *ssm.DescribeParametersInput) (*ssm.DescribeParametersOutput, error) {
+	return c.DescribeParametersWithContext(context.Background(), in)
+}
+
+func (c *kvSSM) DescribeParametersWithContext(ctx aws.Context, in *ssm.DescribeParametersInput, opts ...request.Option) (*ssm.DescribeParametersOutput, error) {
+	out := &ssm.DescribeParametersOutput{}
+	for _, f := range in.ParameterFilters {
+		if aws.StringValue(f.Key) != ssm.ParametersFilterKeyName || aws.StringValue(f.Option) != "Equals" {
+			return nil, awserr.New("InvalidFilterOption", "only the Equals option is supported for a KV", nil)
+		}
+		for _, name := range f.Values {
+			p, err := c.get(ctx, aws.StringValue(name))
+			if err != nil {
+				return nil, err
+			}
+			if p != nil {
+				out.Parameters = append(out.Parameters, &ssm.ParameterMetadata{Name: p.Name, Version: p.Version})
+			}
+		}
+	}
+	return out, nil
+}
+
+// get returns the parameter for key, or nil if there's none
+func (c *kvSSM) get(ctx context.Context, key string) (*ssm.Parameter, error) {
+	value, version, ok, err := c.kv.Get(ctx, key)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &ssm.Parameter{
+		Name:    aws.String(key),
+		Type:    aws.String(ssm.ParameterTypeString),
+		Value:   aws.String(value),
+		Version: aws.Int64(version),
+	}, nil
+}
+
+// KVNotifier is a Notifier that uses the native watch of a KV, so a Watcher of a
+// client from NewKVClient reloads as soon as keys under Prefix change
+type KVNotifier struct {
+	KV     KV
+	Prefix string
+
+	mu      sync.Mutex
+	version int64
+}
+
+// Notify blocks until keys under the prefix change and returns them
+func (n *KVNotifier) Notify(ctx context.Context) ([]string, error) {
+	version := n.current()
+	if version == 0 {
+		_, v, err := n.KV.Watch(ctx, n.Prefix, 0)
+		if err != nil {
+			return nil, err
+		}
+		version = n.setCurrent(v)
+	}
+	for {
+		keys, v, err := n.KV.Watch(ctx, n.Prefix, version)
+		if err != nil {
+			return nil, err
+		}
+		if v == version {
+			// a blocking query timed out without a change
+			continue
+		}
+		n.setCurrent(v)
+		return keys, nil
+	}
+}
+
+// current returns the version of the store when last watched
+func (n *KVNotifier) current() int64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.version
+}
+
+func (n *KVNotifier) setCurrent(v int64) int64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.version = v
+	return v
+}
+
+// defaultPollInterval is how often a pollingKV checks its keys for changes
+const defaultPollInterval = time.Minute
+
+// fetchFunc returns the value of key and a revision that changes with it, with ok false
+// when there's no such key
+type fetchFunc func(ctx context.Context, key string) (value, revision string, ok bool, err error)
+
+// pollingKV implements KV for stores without a native watch by polling the keys that
+// have been read, numbering each change with a version of its own
+type pollingKV struct {
+	mu        sync.Mutex
+	revisions map[string]string
+	versions  map[string]int64
+	version   int64
+}
+
+// get fetches key, recording a new version when its revision changed
+func (p *pollingKV) get(ctx context.Context, key string, fetch fetchFunc) (string, int64, bool, error) {
+	value, revision, ok, err := fetch(ctx, key)
+	if err != nil {
+		return "", 0, false, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.record(key, revision, ok)
+	return value, p.versions[key], ok, nil
+}
+
+// record notes the revision of key, returning true if it changed
+func (p *pollingKV) record(key, revision string, ok bool) bool {
+	if p.revisions == nil {
+		p.revisions = make(map[string]string)
+		p.versions = make(map[string]int64)
+	}
+	old, seen := p.revisions[key]
+	if !ok {
+		revision = ""
+	}
+	if seen && old == revision {
+		return false
+	}
+	p.version++
+	p.revisions[key] = revision
+	p.versions[key] = p.version
+	return seen
+}
+
+// watch polls the keys beginning with prefix every interval until one changes
+func (p *pollingKV) watch(ctx context.Context, prefix string, version int64, interval time.Duration, fetch fetchFunc) ([]string, int64, error) {
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	for {
+		p.mu.Lock()
+		if p.version == 0 {
+			// 0 asks for the current version, so versions start at 1
+			p.version = 1
+		}
+		current := p.version
+		var keys []string
+		for k := range p.revisions {
+			if strings.HasPrefix(k, prefix) {
+				keys = append(keys, k)
+			}
+		}
+		p.mu.Unlock()
+		if version == 0 {
+			return nil, current, nil
+		}
+		if current > version {
+			// keys were read at newer revisions since the version
+			return nil, current, nil
+		}
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return nil, 0, ctx.Err()
+		}
+		sort.Strings(keys)
+		var changed []string
+		for _, k := range keys {
+			_, revision, ok, err := fetch(ctx, k)
+			if err != nil {
+				return nil, 0, err
+			}
+			p.mu.Lock()
+			if p.record(k, revision, ok) {
+				changed = append(changed, k)
+			}
+			p.mu.Unlock()
+		}
+		if len(changed) > 0 {
+			p.mu.Lock()
+			current = p.version
+			p.mu.Unlock()
+			return changed, current, nil
+		}
+		version = current
+	}
+}
+
+// secretName converts a key to a secret name for stores that don't allow slashes,
+// so "/myapp/db/password" is "myapp-db-password"
+func secretName(key string) string {
+	return strings.Replace(strings.TrimPrefix(key, "/"), "/", "-", -1)
+}
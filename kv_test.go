@@ -0,0 +1,121 @@
+package figgy
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryKV is a KV whose version increments with each change
+type memoryKV struct {
+	mu      sync.Mutex
+	values  map[string]string
+	changes map[int64]string
+	version int64
+	changed chan struct{}
+}
+
+func newMemoryKV(values map[string]string) *memoryKV {
+	return &memoryKV{values: values, changes: make(map[int64]string), version: 1, changed: make(chan struct{})}
+}
+
+func (kv *memoryKV) Get(ctx context.Context, key string) (string, int64, bool, error) {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	v, ok := kv.values[key]
+	var version int64
+	for i, k := range kv.changes {
+		if k == key && i > version {
+			version = i
+		}
+	}
+	return v, version, ok, nil
+}
+
+func (kv *memoryKV) Watch(ctx context.Context, prefix string, version int64) ([]string, int64, error) {
+	for {
+		kv.mu.Lock()
+		var keys []string
+		for i := version + 1; i <= kv.version && version != 0; i++ {
+			if k, ok := kv.changes[i]; ok && strings.HasPrefix(k, prefix) {
+				keys = append(keys, k)
+			}
+		}
+		current, changed := kv.version, kv.changed
+		kv.mu.Unlock()
+		if version == 0 || len(keys) > 0 {
+			return keys, current, nil
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil, 0, ctx.Err()
+		}
+	}
+}
+
+func (kv *memoryKV) set(key, value string) {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	kv.version++
+	kv.values[key] = value
+	kv.changes[kv.version] = key
+	close(kv.changed)
+	kv.changed = make(chan struct{})
+}
+
+func TestKVClient(t *testing.T) {
+	kv := newMemoryKV(map[string]string{"/app/host": "a", "/app/password": "p", "/app/port": "1"})
+	var cfg WatchConfig
+	assert.NoError(t, Load(NewKVClient(kv), &cfg))
+	assert.Equal(t, "a", cfg.Host)
+	assert.Equal(t, "p", cfg.Password)
+	assert.Equal(t, 1, cfg.Nested.Port)
+
+	delete(kv.values, "/app/port")
+	assert.EqualError(t, Load(NewKVClient(kv), &cfg), "invalid parameters: /app/port")
+}
+
+func TestKVNotifier(t *testing.T) {
+	kv := newMemoryKV(map[string]string{"/app/host": "a", "/app/password": "p", "/app/port": "1", "/other": "x"})
+	var cfg WatchConfig
+	n := &KVNotifier{KV: kv, Prefix: "/app/"}
+	w, err := Watch(NewKVClient(kv), &cfg, nil, n)
+	assert.NoError(t, err)
+	defer w.Stop()
+
+	// wait for the notifier to start watching
+	for n.current() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	kv.set("/other", "y")
+	kv.set("/app/host", "b")
+	assert.True(t, waitChange(t, w))
+	w.RLock()
+	assert.Equal(t, "b", cfg.Host)
+	w.RUnlock()
+	assert.NoError(t, w.LastError())
+}
+
+func TestKVVersions(t *testing.T) {
+	kv := newMemoryKV(map[string]string{"/app/host": "a", "/app/password": "p", "/app/port": "1"})
+	var cfg WatchConfig
+	n := make(chanNotifier)
+	w, err := Watch(NewKVClient(kv), &cfg, nil, n)
+	assert.NoError(t, err)
+	defer w.Stop()
+
+	// versions are described, so only a change reloads
+	n <- nil
+	n.settle()
+	kv.set("/app/port", "2")
+	n <- nil
+	assert.True(t, waitChange(t, w))
+	w.RLock()
+	assert.Equal(t, 2, cfg.Nested.Port)
+	w.RUnlock()
+}
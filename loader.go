@@ -0,0 +1,191 @@
+package figgy
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// DefaultLoader is a Loader shared by the packages of a process.  Packages register
+// their structs with it as they're initialized, and main loads or watches them all
+// once it has a client.
+var DefaultLoader = &Loader{}
+
+// Loader loads the structs registered with it together, so parameters are requested
+// in as few batches as possible and a parameter shared by several structs is
+// requested once, and watches them with a single watcher.  The zero value is ready
+// to use, and a Loader is safe for concurrent use.
+//
+//	// in package db
+//	var config struct {
+//		Password string `ssm:"/myapp/{{.env}}/db/password,decrypt"`
+//	}
+//
+//	func init() {
+//		figgy.DefaultLoader.Register(&config)
+//		figgy.DefaultLoader.Subscribe(&config, reconnect)
+//	}
+//
+//	// in package main
+//	err := figgy.DefaultLoader.Watch(ssmClient, figgy.P{"env": "prod"}, figgy.Poll(time.Minute))
+type Loader struct {
+	mu      sync.Mutex
+	structs []*registered
+	w       *Watcher
+}
+
+// registered is a struct registered with a Loader
+type registered struct {
+	v           reflect.Value
+	subscribers []func()
+}
+
+var errLoaderWatching = errors.New("figgy: loader is already watching")
+
+// Register adds v, a pointer to a struct, to the structs loaded by the loader.  Structs
+// can't be registered once the loader is watching.
+func (l *Loader) Register(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return &InvalidTypeError{Type: reflect.TypeOf(v)}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.w != nil {
+		return errLoaderWatching
+	}
+	if l.find(rv) == nil {
+		l.structs = append(l.structs, &registered{v: rv})
+	}
+	return nil
+}
+
+// Subscribe calls fn each time the watcher changes fields of v, a registered struct.
+// fn is called without holding the loader's lock, after the fields have changed.
+func (l *Loader) Subscribe(v interface{}, fn func()) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	r := l.find(reflect.ValueOf(v))
+	if r == nil {
+		return fmt.Errorf("figgy: %T is not registered with the loader", v)
+	}
+	r.subscribers = append(r.subscribers, fn)
+	return nil
+}
+
+// Load loads the registered structs with c as LoadWithParameters does, performing
+// parameter substitution on the tags of every struct with data.  Validators set with
+// WithValidator are given a pointer to a struct with a field for each registered
+// struct.
+func (l *Loader) Load(c ssmiface.SSMAPI, data interface{}, opts ...Option) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.w != nil {
+		return errLoaderWatching
+	}
+	return LoadWithParameters(c, l.composite().Interface(), data, opts...)
+}
+
+// Watch loads the registered structs as Load does, and then keeps them up to date as
+// Watch does, until Stop is called.  Hold the read lock of the loader's Watcher while
+// reading the structs.
+func (l *Loader) Watch(c ssmiface.SSMAPI, data interface{}, n Notifier, opts ...Option) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.w != nil {
+		return errLoaderWatching
+	}
+	v := l.composite()
+	// the fields of each struct follow those of the structs before it in walk order
+	var ends []int
+	for _, r := range l.structs {
+		fields, err := walk(r.v.Elem(), data)
+		if err != nil {
+			return err
+		}
+		ends = append(ends, len(fields))
+		if i := len(ends) - 1; i > 0 {
+			ends[i] += ends[i-1]
+		}
+	}
+	publish := func(o *options) {
+		o.publish = func(changes []change) {
+			l.publish(changes, ends)
+		}
+	}
+	w, err := Watch(c, v.Interface(), data, n, append(opts, publish)...)
+	if err != nil {
+		return err
+	}
+	l.w = w
+	return nil
+}
+
+// Stop watching for changes.  Structs can be registered again once the loader is stopped.
+func (l *Loader) Stop() {
+	l.mu.Lock()
+	w := l.w
+	l.w = nil
+	l.mu.Unlock()
+	if w != nil {
+		w.Stop()
+	}
+}
+
+// Watcher returns the loader's watcher, or nil when it isn't watching.  Hold the
+// watcher's read lock while reading the registered structs.
+func (l *Loader) Watcher() *Watcher {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w
+}
+
+// composite returns a pointer to a new struct with a pointer field to each registered
+// struct.  The caller holds the lock.
+func (l *Loader) composite() reflect.Value {
+	fields := make([]reflect.StructField, len(l.structs))
+	for i, r := range l.structs {
+		fields[i] = reflect.StructField{Name: fmt.Sprintf("S%d", i), Type: r.v.Type()}
+	}
+	v := reflect.New(reflect.StructOf(fields))
+	for i, r := range l.structs {
+		v.Elem().Field(i).Set(r.v)
+	}
+	return v
+}
+
+// find returns the registration of the struct v points to.  The caller holds the lock.
+func (l *Loader) find(v reflect.Value) *registered {
+	for _, r := range l.structs {
+		if v.Kind() == reflect.Ptr && r.v.Pointer() == v.Pointer() && r.v.Type() == v.Type() {
+			return r
+		}
+	}
+	return nil
+}
+
+// publish calls the subscribers of the structs with changed fields, once each.  ends
+// are the indexes, in walk order, after the last field of each struct.
+func (l *Loader) publish(changes []change, ends []int) {
+	l.mu.Lock()
+	var calls []func()
+	for i, end := range ends {
+		start := 0
+		if i > 0 {
+			start = ends[i-1]
+		}
+		for _, c := range changes {
+			if c.index >= start && c.index < end {
+				calls = append(calls, l.structs[i].subscribers...)
+				break
+			}
+		}
+	}
+	l.mu.Unlock()
+	for _, call := range calls {
+		call()
+	}
+}
@@ -0,0 +1,74 @@
+package figgy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoader(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/host": "a", "/app/password": "p", "/app/port": "1"})
+	c := &countingSSMClient{MockSSMClient: m}
+	var db struct {
+		Host     string `ssm:"/app/host"`
+		Password string `ssm:"/app/password,decrypt"`
+	}
+	var web struct {
+		Host string `ssm:"/app/host"`
+		Port int    `ssm:"/app/port"`
+	}
+	l := &Loader{}
+	assert.NoError(t, l.Register(&db))
+	assert.NoError(t, l.Register(&web))
+	assert.NoError(t, l.Register(&web))
+	assert.IsType(t, &InvalidTypeError{}, l.Register(db))
+	assert.NoError(t, l.Load(c, nil))
+	assert.Equal(t, "a", db.Host)
+	assert.Equal(t, "p", db.Password)
+	assert.Equal(t, "a", web.Host)
+	assert.Equal(t, 1, web.Port)
+	// the shared parameter is requested once, in one batch with the other plain parameter
+	assert.Equal(t, []int{2, 1}, c.batches)
+}
+
+func TestLoaderWatch(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/host": "a", "/app/password": "p", "/app/port": "1"})
+	var db struct {
+		Password string `ssm:"/app/password,decrypt"`
+	}
+	var web struct {
+		Host string `ssm:"/app/host"`
+		Port int    `ssm:"/app/port"`
+	}
+	l := &Loader{}
+	assert.NoError(t, l.Register(&db))
+	assert.NoError(t, l.Register(&web))
+	var dbChanges, webChanges int
+	assert.NoError(t, l.Subscribe(&db, func() { dbChanges++ }))
+	assert.NoError(t, l.Subscribe(&web, func() { webChanges++ }))
+	var other struct{}
+	assert.Error(t, l.Subscribe(&other, func() {}))
+
+	n := make(chanNotifier)
+	assert.NoError(t, l.Watch(m, nil, n))
+	defer l.Stop()
+	assert.Equal(t, errLoaderWatching, l.Register(&other))
+	assert.Equal(t, errLoaderWatching, l.Load(m, nil))
+
+	setParameter(m, "/app/host", "b")
+	setParameter(m, "/app/port", "2")
+	n <- nil
+	w := l.Watcher()
+	assert.True(t, waitChange(t, w))
+	n.settle()
+	w.RLock()
+	assert.Equal(t, "b", web.Host)
+	assert.Equal(t, 2, web.Port)
+	w.RUnlock()
+	assert.Equal(t, 0, dbChanges)
+	assert.Equal(t, 1, webChanges)
+
+	l.Stop()
+	assert.Nil(t, l.Watcher())
+	assert.NoError(t, l.Register(&other))
+}
@@ -0,0 +1,27 @@
+package figgy
+
+// Logger receives debug output describing how parameters are loaded.  Arguments
+// after the message are alternating keys and values, so a *slog.Logger can be used
+// directly.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+}
+
+// WithLogger writes debug output to l: the key resolved for each field, the
+// parameters in each request to Parameter Store, and parameters that don't exist.
+func WithLogger(l Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...interface{}) {}
+
+// logFields logs the key resolved for each field
+func (o *options) logFields(f []*field) {
+	for _, x := range f {
+		o.logger.Debug("figgy: resolved key", "field", x.field.Name, "key", x.key, "decrypt", x.decrypt)
+	}
+}
@@ -0,0 +1,42 @@
+package figgy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingLogger struct {
+	msgs []string
+	args [][]interface{}
+}
+
+func (l *recordingLogger) Debug(msg string, args ...interface{}) {
+	l.msgs = append(l.msgs, msg)
+	l.args = append(l.args, args)
+}
+
+func TestWithLogger(t *testing.T) {
+	l := &recordingLogger{}
+	s := struct {
+		S string `ssm:"string"`
+		I int    `ssm:"int,decrypt"`
+		M string `ssm:"/no/such/param"`
+	}{}
+	err := Load(NewMockSSMClient(), &s, WithLogger(l))
+	assert.Error(t, err)
+	assert.Equal(t, []string{
+		"figgy: resolved key",
+		"figgy: resolved key",
+		"figgy: resolved key",
+		"figgy: requesting parameters",
+		"figgy: invalid parameters",
+		"figgy: requesting parameters",
+	}, l.msgs)
+	assert.Equal(t, []interface{}{"field", "I", "key", "int", "decrypt", true}, l.args[1])
+	assert.Equal(t, []interface{}{"keys", []string{"string", "/no/such/param"}, "decrypt", false}, l.args[3])
+	assert.Equal(t, []interface{}{"keys", []string{"/no/such/param"}}, l.args[4])
+	for _, args := range l.args {
+		assert.Equal(t, 0, len(args)%2)
+	}
+}
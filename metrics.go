@@ -0,0 +1,179 @@
+package figgy
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// Metrics receives measurements of loading parameters, for example to report with
+// Prometheus collectors.  Methods may be added as figgy grows, so implementations
+// should embed NopMetrics to keep compiling.
+type Metrics interface {
+	// LoadDone is called when a load finishes, with the time it took, the number
+	// of fields loaded, and the error if it failed
+	LoadDone(d time.Duration, fields int, err error)
+	// BatchDone is called after each request to Parameter Store, with the number of
+	// parameters requested and the error if the request failed
+	BatchDone(parameters int, err error)
+	// ParameterFailed is called with the key of a parameter that doesn't exist or
+	// couldn't be assigned to its field
+	ParameterFailed(key string)
+	// WatchRefreshed is called after a Watcher reloads its parameters, with whether
+	// any fields changed and the error if the reload failed
+	WatchRefreshed(changed bool, err error)
+	// BatchTimed is called after each request to Parameter Store, along with
+	// BatchDone, describing the request and how long it took
+	BatchTimed(b BatchTiming)
+}
+
+// BatchTiming describes a request to Parameter Store, to attribute the time taken
+// by a load to the parameters requested
+type BatchTiming struct {
+	// API is the request made: GetParameter, GetParameters or GetParametersByPath
+	API string
+	// Keys requested, empty for GetParametersByPath
+	Keys []string
+	// Path requested by GetParametersByPath
+	Path string
+	// Duration of the request
+	Duration time.Duration
+	// Parameters returned
+	Parameters int
+	// Bytes is the total length of the values returned
+	Bytes int
+	// Err is the error if the request failed
+	Err error
+}
+
+// NopMetrics is a Metrics that discards all measurements
+type NopMetrics struct{}
+
+// LoadDone does nothing
+func (NopMetrics) LoadDone(time.Duration, int, error) {}
+
+// BatchDone does nothing
+func (NopMetrics) BatchDone(int, error) {}
+
+// ParameterFailed does nothing
+func (NopMetrics) ParameterFailed(string) {}
+
+// WatchRefreshed does nothing
+func (NopMetrics) WatchRefreshed(bool, error) {}
+
+// BatchTimed does nothing
+func (NopMetrics) BatchTimed(BatchTiming) {}
+
+// WithMetrics reports measurements of loading parameters to m
+func WithMetrics(m Metrics) Option {
+	return func(o *options) {
+		o.metrics = m
+	}
+}
+
+// measuredSSM reports the requests made to Parameter Store
+type measuredSSM struct {
+	ssmiface.SSMAPI
+	metrics Metrics
+}
+
+// measure wraps c to report its requests, unless no metrics are configured
+func (o *options) measure(c ssmiface.SSMAPI) ssmiface.SSMAPI {
+	if _, ok := o.metrics.(NopMetrics); ok {
+		return c
+	}
+	return &measuredSSM{SSMAPI: c, metrics: o.metrics}
+}
+
+func (c *measuredSSM) GetParameter(in *ssm.GetParameterInput) (*ssm.GetParameterOutput, error) {
+	start := time.Now()
+	out, err := c.SSMAPI.GetParameter(in)
+	c.metrics.BatchDone(1, err)
+	c.timed("GetParameter", []*string{in.Name}, nil, start, parameterOutput(out), err)
+	return out, err
+}
+
+func (c *measuredSSM) GetParameterWithContext(ctx aws.Context, in *ssm.GetParameterInput, opts ...request.Option) (*ssm.GetParameterOutput, error) {
+	start := time.Now()
+	out, err := c.SSMAPI.GetParameterWithContext(ctx, in, opts...)
+	c.metrics.BatchDone(1, err)
+	c.timed("GetParameter", []*string{in.Name}, nil, start, parameterOutput(out), err)
+	return out, err
+}
+
+func (c *measuredSSM) GetParameters(in *ssm.GetParametersInput) (*ssm.GetParametersOutput, error) {
+	start := time.Now()
+	out, err := c.SSMAPI.GetParameters(in)
+	c.metrics.BatchDone(len(in.Names), err)
+	c.timed("GetParameters", in.Names, nil, start, parametersOutput(out), err)
+	return out, err
+}
+
+func (c *measuredSSM) GetParametersWithContext(ctx aws.Context, in *ssm.GetParametersInput, opts ...request.Option) (*ssm.GetParametersOutput, error) {
+	start := time.Now()
+	out, err := c.SSMAPI.GetParametersWithContext(ctx, in, opts...)
+	c.metrics.BatchDone(len(in.Names), err)
+	c.timed("GetParameters", in.Names, nil, start, parametersOutput(out), err)
+	return out, err
+}
+
+func (c *measuredSSM) GetParametersByPath(in *ssm.GetParametersByPathInput) (*ssm.GetParametersByPathOutput, error) {
+	start := time.Now()
+	out, err := c.SSMAPI.GetParametersByPath(in)
+	c.metrics.BatchDone(pathParameters(out), err)
+	c.timed("GetParametersByPath", nil, in.Path, start, pathOutput(out), err)
+	return out, err
+}
+
+func (c *measuredSSM) GetParametersByPathWithContext(ctx aws.Context, in *ssm.GetParametersByPathInput, opts ...request.Option) (*ssm.GetParametersByPathOutput, error) {
+	start := time.Now()
+	out, err := c.SSMAPI.GetParametersByPathWithContext(ctx, in, opts...)
+	c.metrics.BatchDone(pathParameters(out), err)
+	c.timed("GetParametersByPath", nil, in.Path, start, pathOutput(out), err)
+	return out, err
+}
+
+// timed reports the timing of a request started at start that returned params
+func (c *measuredSSM) timed(api string, keys []*string, path *string, start time.Time, params []*ssm.Parameter, err error) {
+	b := BatchTiming{
+		API:        api,
+		Keys:       aws.StringValueSlice(keys),
+		Path:       aws.StringValue(path),
+		Duration:   time.Since(start),
+		Parameters: len(params),
+		Err:        err,
+	}
+	for _, p := range params {
+		b.Bytes += len(aws.StringValue(p.Value))
+	}
+	c.metrics.BatchTimed(b)
+}
+
+// pathParameters is the number of parameters returned by a GetParametersByPath request
+func pathParameters(out *ssm.GetParametersByPathOutput) int {
+	return len(pathOutput(out))
+}
+
+func parameterOutput(out *ssm.GetParameterOutput) []*ssm.Parameter {
+	if out == nil || out.Parameter == nil {
+		return nil
+	}
+	return []*ssm.Parameter{out.Parameter}
+}
+
+func parametersOutput(out *ssm.GetParametersOutput) []*ssm.Parameter {
+	if out == nil {
+		return nil
+	}
+	return out.Parameters
+}
+
+func pathOutput(out *ssm.GetParametersByPathOutput) []*ssm.Parameter {
+	if out == nil {
+		return nil
+	}
+	return out.Parameters
+}
@@ -0,0 +1,113 @@
+package figgy
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingMetrics struct {
+	NopMetrics
+	loads   int
+	fields  int
+	loadErr error
+	batches []int
+	failed  []string
+}
+
+func (m *recordingMetrics) LoadDone(d time.Duration, fields int, err error) {
+	m.loads++
+	m.fields = fields
+	m.loadErr = err
+}
+
+func (m *recordingMetrics) BatchDone(parameters int, err error) {
+	m.batches = append(m.batches, parameters)
+}
+
+func (m *recordingMetrics) ParameterFailed(key string) {
+	m.failed = append(m.failed, key)
+}
+
+func TestWithMetrics(t *testing.T) {
+	m := &recordingMetrics{}
+	s := struct {
+		S  string `ssm:"string"`
+		I  int    `ssm:"int,decrypt"`
+		B  bool   `ssm:"bool"`
+		B2 bool   `ssm:"bool"`
+	}{}
+	err := Load(NewMockSSMClient(), &s, WithMetrics(m))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, m.loads)
+	assert.Equal(t, 4, m.fields)
+	assert.Nil(t, m.loadErr)
+	assert.Equal(t, []int{2, 1}, m.batches)
+	assert.Empty(t, m.failed)
+}
+
+func TestWithMetricsFailures(t *testing.T) {
+	m := &recordingMetrics{}
+	missing := struct {
+		S string `ssm:"/no/such/param"`
+	}{}
+	err := Load(NewMockSSMClient(), &missing, WithMetrics(m))
+	assert.Error(t, err)
+	assert.Equal(t, err, m.loadErr)
+	assert.Equal(t, []string{"/no/such/param"}, m.failed)
+
+	m = &recordingMetrics{}
+	invalid := struct {
+		I int `ssm:"string"`
+	}{}
+	err = Load(NewMockSSMClient(), &invalid, WithMetrics(m))
+	assert.Error(t, err)
+	assert.Equal(t, []string{"string"}, m.failed)
+
+	m = &recordingMetrics{}
+	failing := WithTransform(func(FieldInfo, string) (string, error) {
+		return "", errors.New("transform failed")
+	})
+	err = Load(NewMockSSMClient(), &invalid, WithMetrics(m), failing)
+	assert.Error(t, err)
+	assert.Equal(t, []string{"string"}, m.failed)
+}
+
+type timingMetrics struct {
+	NopMetrics
+	timings []BatchTiming
+}
+
+func (m *timingMetrics) BatchTimed(b BatchTiming) {
+	m.timings = append(m.timings, b)
+}
+
+func TestBatchTimed(t *testing.T) {
+	m := &timingMetrics{}
+	s := struct {
+		S string `ssm:"string"`
+		I int    `ssm:"int,decrypt"`
+		B bool   `ssm:"bool"`
+	}{}
+	assert.NoError(t, Load(NewMockSSMClient(), &s, WithMetrics(m)))
+	assert.Len(t, m.timings, 2)
+	assert.Equal(t, []string{"string", "bool"}, m.timings[0].Keys)
+	assert.Equal(t, 2, m.timings[0].Parameters)
+	assert.Equal(t, len("this is a string")+len("true"), m.timings[0].Bytes)
+	assert.Equal(t, []string{"int"}, m.timings[1].Keys)
+	assert.NoError(t, m.timings[0].Err)
+	assert.Equal(t, "GetParameters", m.timings[0].API)
+
+	m = &timingMetrics{}
+	it := Params(newBatchClient(), "/app/svc", WithMetrics(m))
+	for it.Next() {
+	}
+	assert.Len(t, m.timings, 2)
+	assert.Equal(t, "/app/svc", m.timings[0].Path)
+	assert.Equal(t, "GetParametersByPath", m.timings[0].API)
+	assert.Empty(t, m.timings[0].Keys)
+	assert.Equal(t, 10, m.timings[0].Parameters)
+	assert.Equal(t, 10, m.timings[0].Bytes)
+}
@@ -0,0 +1,181 @@
+package figgy
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// caseMatch is how keys are matched to parameter names that differ only in case
+type caseMatch int
+
+const (
+	caseSensitive caseMatch = iota
+	caseInsensitive
+	caseStrict
+)
+
+// WithCaseInsensitive loads a parameter whose name differs from a field's key only in
+// case when no parameter has the key, logging the name used with WithLogger.  Only
+// the last element of a key is compared, as the parameters sharing its path are
+// listed to find the name.
+func WithCaseInsensitive() Option {
+	return func(o *options) {
+		o.caseMatch = caseInsensitive
+	}
+}
+
+// WithStrictCase fails a load with a CaseMismatchError, rather than an error naming
+// the missing parameters, when a parameter's name differs from a field's key only in
+// case
+func WithStrictCase() Option {
+	return func(o *options) {
+		o.caseMatch = caseStrict
+	}
+}
+
+// CaseMismatchError describes a key with no parameter, where a parameter's name
+// differs from it only in case
+type CaseMismatchError struct {
+	// Key of the field
+	Key string
+	// Name of the parameter that differs in case
+	Name string
+}
+
+func (e *CaseMismatchError) Error() string {
+	return fmt.Sprintf("parameter '%s' not found, did you mean '%s'?", e.Key, e.Name)
+}
+
+// resolveCase finds parameters whose names differ from the missing keys only in case,
+// adding them to idx under the keys and returning the keys still missing.  Keys are
+// only resolved when WithCaseInsensitive or WithStrictCase is used.
+func (o *options) resolveCase(c ssmiface.SSMAPI, idx map[string]*ssm.Parameter, missing []string, decrypt bool) ([]string, error) {
+	if o.caseMatch == caseSensitive || len(missing) == 0 {
+		return missing, nil
+	}
+	listed := make(map[string]bool)
+	var rest []string
+	for _, k := range missing {
+		p := foldParameter(idx, k)
+		if dir := parentPath(k); p == nil && dir != "" && !listed[dir] {
+			listed[dir] = true
+			o.logger.Debug("figgy: listing parameters to match case", "path", dir, "key", k)
+			params, err := getParametersByPath(c, dir, false, decrypt)
+			if err != nil {
+				return nil, err
+			}
+			for _, x := range params {
+				idx[aws.StringValue(x.Name)] = x
+			}
+			p = foldParameter(idx, k)
+		}
+		if p == nil {
+			rest = append(rest, k)
+			continue
+		}
+		name := aws.StringValue(p.Name)
+		if o.caseMatch == caseStrict {
+			return nil, &CaseMismatchError{Key: k, Name: name}
+		}
+		o.logger.Debug("figgy: parameter name differs in case", "key", k, "name", name)
+		idx[k] = p
+	}
+	return rest, nil
+}
+
+// foldParameter returns the parameter in idx whose name matches key ignoring case,
+// the first name in order when there's more than one
+func foldParameter(idx map[string]*ssm.Parameter, key string) *ssm.Parameter {
+	var match *ssm.Parameter
+	for name, p := range idx {
+		if strings.EqualFold(name, key) && (match == nil || name < aws.StringValue(match.Name)) {
+			match = p
+		}
+	}
+	return match
+}
+
+// suggestNames lists the paths of missing keys for parameters with similar names,
+// returning the closest name for each key that has one.  Suggestions are left out
+// when the paths can't be listed.
+func suggestNames(c ssmiface.SSMAPI, missing []string) map[string]string {
+	listed := make(map[string][]*ssm.Parameter)
+	suggestions := make(map[string]string)
+	for _, k := range missing {
+		dir := parentPath(k)
+		if dir == "" {
+			continue
+		}
+		params, ok := listed[dir]
+		if !ok {
+			params, _ = getParametersByPath(c, dir, false, false)
+			listed[dir] = params
+		}
+		best, min := "", -1
+		for _, p := range params {
+			name := aws.StringValue(p.Name)
+			if name == k || parentPath(name) != dir {
+				continue
+			}
+			d := editDistance(strings.ToLower(path.Base(name)), strings.ToLower(path.Base(k)))
+			if d > 2 || 3*d > len(path.Base(k)) {
+				continue
+			}
+			if min < 0 || d < min || d == min && name < best {
+				best, min = name, d
+			}
+		}
+		if best != "" {
+			suggestions[k] = best
+		}
+	}
+	return suggestions
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func minInt(n ...int) int {
+	m := n[0]
+	for _, x := range n[1:] {
+		if x < m {
+			m = x
+		}
+	}
+	return m
+}
+
+// parentPath returns the path a key is in, or "" for keys outside of any path and ARNs
+func parentPath(key string) string {
+	if isARN(key) || !strings.HasPrefix(key, "/") {
+		return ""
+	}
+	i := strings.LastIndex(key, "/")
+	if i == 0 {
+		return "/"
+	}
+	return key[:i]
+}
@@ -0,0 +1,76 @@
+package figgy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadCaseInsensitive(t *testing.T) {
+	data := map[string]string{"/app/db_host": "h", "/app/DB_Port": "5432", "/app/user": "u"}
+	var c struct {
+		Host string `ssm:"/app/DB_HOST"`
+		Port int    `ssm:"/app/db_port"`
+		User string `ssm:"/app/user"`
+	}
+	err := Load(NewMockSSMClientWith(data), &c)
+	assert.EqualError(t, err, "invalid parameters: /app/DB_HOST (did you mean /app/db_host?), /app/db_port (did you mean /app/DB_Port?)")
+
+	l := &recordingLogger{}
+	assert.NoError(t, Load(NewMockSSMClientWith(data), &c, WithCaseInsensitive(), WithLogger(l)))
+	assert.Equal(t, "h", c.Host)
+	assert.Equal(t, 5432, c.Port)
+	assert.Equal(t, "u", c.User)
+	assert.Contains(t, l.args, []interface{}{"key", "/app/DB_HOST", "name", "/app/db_host"})
+
+	// loading by path resolves names as well
+	c.Host, c.Port = "", 0
+	assert.NoError(t, Load(NewMockSSMClientWith(data), &c, WithCaseInsensitive(), WithPathThreshold(1)))
+	assert.Equal(t, "h", c.Host)
+	assert.Equal(t, 5432, c.Port)
+
+	err = Load(NewMockSSMClientWith(data), &c, WithStrictCase())
+	assert.Equal(t, &CaseMismatchError{Key: "/app/DB_HOST", Name: "/app/db_host"}, err)
+	assert.EqualError(t, err, "parameter '/app/DB_HOST' not found, did you mean '/app/db_host'?")
+}
+
+func TestLoadCaseInsensitiveMissing(t *testing.T) {
+	var c struct {
+		Host string `ssm:"/app/host"`
+		Name string `ssm:"name"`
+	}
+	err := Load(NewMockSSMClientWith(map[string]string{"/app/port": "1"}), &c, WithCaseInsensitive())
+	assert.EqualError(t, err, "invalid parameters: /app/host, name")
+}
+
+func TestParentPath(t *testing.T) {
+	assert.Equal(t, "/app/db", parentPath("/app/db/host"))
+	assert.Equal(t, "/", parentPath("/host"))
+	assert.Equal(t, "", parentPath("host"))
+	assert.Equal(t, "", parentPath("arn:aws:ssm:us-east-1:123456789012:parameter/app/host"))
+}
+
+func TestSuggestNames(t *testing.T) {
+	data := map[string]string{
+		"/app/prod/db_host": "h", "/app/prod/db_port": "1", "/app/prod/host": "h",
+		"/app/prod/cache/db_hots": "h", "/app/stage/db_hst": "h",
+	}
+	var c struct {
+		Host  string `ssm:"/app/prod/db-host"`
+		Port  string `ssm:"/app/prod/db_prot"`
+		Cache string `ssm:"/app/prod/cache/host"`
+		User  string `ssm:"/app/prod/db_user"`
+		Name  string `ssm:"name"`
+	}
+	err := Load(NewMockSSMClientWith(data), &c)
+	assert.EqualError(t, err, "invalid parameters: /app/prod/db-host (did you mean /app/prod/db_host?), "+
+		"/app/prod/db_prot (did you mean /app/prod/db_port?), /app/prod/cache/host, /app/prod/db_user, name")
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("db_host", "db_host"))
+	assert.Equal(t, 1, editDistance("db-host", "db_host"))
+	assert.Equal(t, 2, editDistance("db_hots", "db_host"))
+	assert.Equal(t, 3, editDistance("", "abc"))
+	assert.Equal(t, 3, editDistance("kitten", "sitting"))
+}
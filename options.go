@@ -0,0 +1,117 @@
+package figgy
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/cloudformation/cloudformationiface"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
+)
+
+// Option configures how parameters are loaded
+type Option func(*options)
+
+type options struct {
+	transforms        []TransformFunc
+	references        bool
+	secrets           secretsmanageriface.SecretsManagerAPI
+	exports           cloudformationiface.CloudFormationAPI
+	keyID             string
+	overwrite         bool
+	dryRun            bool
+	metrics           Metrics
+	tracer            Tracer
+	logger            Logger
+	debounce          time.Duration
+	validate          func(v interface{}) error
+	snapshots         int
+	batchSize         int
+	pathLimit         int
+	callTimeout       time.Duration
+	breaker           *Breaker
+	pacer             *Pacer
+	remotes           *remoteClients
+	regionPrefixes    map[string]string
+	rolePrefixes      map[string]string
+	s3                s3iface.S3API
+	envName           func(key string) string
+	envOnly           bool
+	endpoint          string
+	resetMissing      bool
+	renames           func(Rename)
+	group             string
+	caseMatch         caseMatch
+	budget            time.Duration
+	deadline          time.Time
+	publish           func([]change)
+	tier              string
+	policyReport      func(key string, policies []Policy)
+	expirationWarning time.Duration
+	expirationRefresh bool
+	kmsKeys           []string
+	decryptDenied     decryptDenied
+	singleLimit       int
+	durationFallback  bool
+	cleanKeys         bool
+	lowercaseKeys     bool
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		metrics:   NopMetrics{},
+		tracer:    nopTracer{},
+		logger:    nopLogger{},
+		validate:  func(interface{}) error { return nil },
+		batchSize: maxParameters,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// FieldInfo describes the field a parameter value is being loaded into
+type FieldInfo struct {
+	// Key of the parameter after template expansion
+	Key string
+	// Field the value will be assigned to
+	Field reflect.StructField
+	// Decrypt is true when the parameter was loaded with decryption
+	Decrypt bool
+	// JSON is true when the value will be decoded as JSON
+	JSON bool
+}
+
+// TransformFunc transforms a raw parameter value before it is assigned to a field
+type TransformFunc func(field FieldInfo, raw string) (string, error)
+
+// WithTransform adds value transformations that are applied, in order, between
+// fetching a parameter and setting its field.  Transforms from multiple
+// WithTransform options are applied in the order the options are given.
+func WithTransform(t ...TransformFunc) Option {
+	return func(o *options) {
+		o.transforms = append(o.transforms, t...)
+	}
+}
+
+// transform applies the configured transformations to a value
+func (o *options) transform(f *field, s string) (string, error) {
+	var err error
+	for _, t := range o.transforms {
+		s, err = t(f.info(), s)
+		if err != nil {
+			return "", err
+		}
+	}
+	return s, nil
+}
+
+// WithValidator checks a struct after it's loaded, with the error from validate
+// returned by the load.  Watchers keep the values they had when a reload fails
+// validation.
+func WithValidator(validate func(v interface{}) error) Option {
+	return func(o *options) {
+		o.validate = validate
+	}
+}
@@ -0,0 +1,80 @@
+package figgy
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTransform(t *testing.T) {
+	var c struct {
+		Upper   string `ssm:"string"`
+		Decrypt string `ssm:"pstring,decrypt"`
+	}
+	var seen []FieldInfo
+	record := func(f FieldInfo, s string) (string, error) {
+		seen = append(seen, f)
+		return s, nil
+	}
+	upper := func(f FieldInfo, s string) (string, error) {
+		return strings.ToUpper(s), nil
+	}
+	suffix := func(f FieldInfo, s string) (string, error) {
+		return s + "!", nil
+	}
+	err := Load(NewMockSSMClient(), &c, WithTransform(record, upper), WithTransform(suffix))
+	assert.NoError(t, err)
+	assert.Equal(t, "THIS IS A STRING!", c.Upper)
+	assert.Equal(t, "THIS IS A PTR TO A STRING!", c.Decrypt)
+	assert.Len(t, seen, 2)
+	assert.Equal(t, "string", seen[0].Key)
+	assert.Equal(t, "Upper", seen[0].Field.Name)
+	assert.False(t, seen[0].Decrypt)
+	assert.Equal(t, "pstring", seen[1].Key)
+	assert.True(t, seen[1].Decrypt)
+}
+
+func TestWithTransformBeforeConvert(t *testing.T) {
+	var c struct {
+		Int int `ssm:"string"`
+	}
+	encoded := func(f FieldInfo, s string) (string, error) {
+		b, err := base64.StdEncoding.DecodeString("NDI=")
+		return string(b), err
+	}
+	err := Load(NewMockSSMClient(), &c, WithTransform(encoded))
+	assert.NoError(t, err)
+	assert.Equal(t, 42, c.Int)
+}
+
+func TestWithTransformError(t *testing.T) {
+	var c struct {
+		String string `ssm:"string"`
+	}
+	fail := func(f FieldInfo, s string) (string, error) {
+		return "", errors.New("transform failed")
+	}
+	err := Load(NewMockSSMClient(), &c, WithTransform(fail))
+	assert.EqualError(t, err, "transform failed")
+	assert.Equal(t, "", c.String)
+}
+
+func TestWithValidator(t *testing.T) {
+	s := struct {
+		I int `ssm:"int"`
+	}{}
+	invalid := errors.New("invalid")
+	var validated interface{}
+	err := Load(NewMockSSMClient(), &s, WithValidator(func(v interface{}) error {
+		validated = v
+		return invalid
+	}))
+	assert.Equal(t, invalid, err)
+	assert.Equal(t, &s, validated)
+
+	err = Load(NewMockSSMClient(), &s, WithValidator(func(v interface{}) error { return nil }))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,177 @@
+package figgy
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// Pacer paces requests to Parameter Store to a rate that adapts to throttling, so
+// bursts of loads wait their turn rather than retrying against a throttled account.
+// Each throttled request halves the rate, down to a sixteenth of the rate it was
+// created with, and each request that isn't throttled raises it back by a tenth.  A
+// Pacer is shared by the loads and watchers of a process, since the account's quota
+// is.
+type Pacer struct {
+	max   float64
+	burst float64
+
+	mu     sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+// defaultPacerRate is the requests a second of a Pacer created without a positive
+// rate, Parameter Store's default throughput
+const defaultPacerRate = 40
+
+// NewPacer returns a Pacer that allows rate requests a second, and bursts of up to
+// burst requests.  A rate that isn't positive allows the default throughput of
+// Parameter Store, 40 requests a second.
+func NewPacer(rate float64, burst int) *Pacer {
+	if !(rate > 0) {
+		rate = defaultPacerRate
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &Pacer{max: rate, burst: float64(burst), rate: rate, tokens: float64(burst), last: time.Now()}
+}
+
+// Rate returns the requests a second currently allowed
+func (p *Pacer) Rate() float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.rate
+}
+
+// wait until a request can be made, or ctx is done
+func (p *Pacer) wait(ctx context.Context) error {
+	p.mu.Lock()
+	now := time.Now()
+	p.tokens = math.Min(p.burst, p.tokens+now.Sub(p.last).Seconds()*p.rate)
+	p.last = now
+	p.tokens--
+	delay := time.Duration(-p.tokens / p.rate * float64(time.Second))
+	p.mu.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		p.mu.Lock()
+		p.tokens++
+		p.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// done adapts the rate to the result of a request
+func (p *Pacer) done(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if request.IsErrorThrottle(err) {
+		p.rate = math.Max(p.rate/2, p.max/16)
+		return
+	}
+	p.rate = math.Min(p.rate+p.max/10, p.max)
+}
+
+// WithPacer paces requests to Parameter Store with p
+func WithPacer(p *Pacer) Option {
+	return func(o *options) {
+		o.pacer = p
+	}
+}
+
+// pacedSSM makes requests at the rate its pacer allows
+type pacedSSM struct {
+	ssmiface.SSMAPI
+	pacer *Pacer
+}
+
+// pace wraps c to make requests at the rate the pacer allows, unless no pacer is
+// configured
+func (o *options) pace(c ssmiface.SSMAPI) ssmiface.SSMAPI {
+	if o.pacer == nil {
+		return c
+	}
+	return &pacedSSM{SSMAPI: c, pacer: o.pacer}
+}
+
+// do makes a request with f once the pacer allows it
+func (c *pacedSSM) do(ctx context.Context, f func() error) error {
+	if err := c.pacer.wait(ctx); err != nil {
+		return err
+	}
+	err := f()
+	c.pacer.done(err)
+	return err
+}
+
+func (c *pacedSSM) GetParameter(in *ssm.GetParameterInput) (out *ssm.GetParameterOutput, err error) {
+	err = c.do(context.Background(), func() error {
+		out, err = c.SSMAPI.GetParameter(in)
+		return err
+	})
+	return out, err
+}
+
+func (c *pacedSSM) GetParameterWithContext(ctx aws.Context, in *ssm.GetParameterInput, opts ...request.Option) (out *ssm.GetParameterOutput, err error) {
+	err = c.do(ctx, func() error {
+		out, err = c.SSMAPI.GetParameterWithContext(ctx, in, opts...)
+		return err
+	})
+	return out, err
+}
+
+func (c *pacedSSM) GetParameters(in *ssm.GetParametersInput) (out *ssm.GetParametersOutput, err error) {
+	err = c.do(context.Background(), func() error {
+		out, err = c.SSMAPI.GetParameters(in)
+		return err
+	})
+	return out, err
+}
+
+func (c *pacedSSM) GetParametersWithContext(ctx aws.Context, in *ssm.GetParametersInput, opts ...request.Option) (out *ssm.GetParametersOutput, err error) {
+	err = c.do(ctx, func() error {
+		out, err = c.SSMAPI.GetParametersWithContext(ctx, in, opts...)
+		return err
+	})
+	return out, err
+}
+
+func (c *pacedSSM) GetParametersByPath(in *ssm.GetParametersByPathInput) (out *ssm.GetParametersByPathOutput, err error) {
+	err = c.do(context.Background(), func() error {
+		out, err = c.SSMAPI.GetParametersByPath(in)
+		return err
+	})
+	return out, err
+}
+
+func (c *pacedSSM) GetParametersByPathWithContext(ctx aws.Context, in *ssm.GetParametersByPathInput, opts ...request.Option) (out *ssm.GetParametersByPathOutput, err error) {
+	err = c.do(ctx, func() error {
+		out, err = c.SSMAPI.GetParametersByPathWithContext(ctx, in, opts...)
+		return err
+	})
+	return out, err
+}
+
+func (c *pacedSSM) DescribeParameters(in *ssm.DescribeParametersInput) (out *ssm.DescribeParametersOutput, err error) {
+	err = c.do(context.Background(), func() error {
+		out, err = c.SSMAPI.DescribeParameters(in)
+		return err
+	})
+	return out, err
+}
@@ -0,0 +1,62 @@
+package figgy
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPacer(t *testing.T) {
+	p := NewPacer(50, 1)
+	start := time.Now()
+	assert.NoError(t, p.wait(context.Background()))
+	assert.NoError(t, p.wait(context.Background()))
+	assert.True(t, time.Since(start) >= 15*time.Millisecond)
+
+	// a request that can't be made before ctx is done gives its turn back
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, p.wait(ctx))
+
+	throttled := awserr.New("ThrottlingException", "Rate exceeded", nil)
+	p.done(throttled)
+	assert.Equal(t, 25.0, p.Rate())
+	for i := 0; i < 10; i++ {
+		p.done(throttled)
+	}
+	assert.Equal(t, 50.0/16, p.Rate())
+	p.done(nil)
+	assert.Equal(t, 50.0/16+5, p.Rate())
+	for i := 0; i < 20; i++ {
+		p.done(nil)
+	}
+	assert.Equal(t, 50.0, p.Rate())
+}
+
+func TestNewPacerRate(t *testing.T) {
+	for _, rate := range []float64{0, -1, math.NaN()} {
+		p := NewPacer(rate, 1)
+		assert.Equal(t, float64(defaultPacerRate), p.Rate())
+		assert.NoError(t, p.wait(context.Background()))
+	}
+}
+
+func TestWithPacer(t *testing.T) {
+	throttled := awserr.New("ThrottlingException", "Rate exceeded", nil)
+	c := &failingSSMClient{MockSSMClient: NewMockSSMClient(), err: throttled}
+	p := NewPacer(100, 10)
+	s := struct {
+		S string `ssm:"string"`
+	}{}
+	assert.Error(t, Load(c, &s, WithPacer(p)))
+	assert.Equal(t, 50.0, p.Rate())
+
+	c.err = nil
+	assert.NoError(t, Load(c, &s, WithPacer(p)))
+	assert.Equal(t, 60.0, p.Rate())
+	assert.Equal(t, "this is a string", s.S)
+}
@@ -0,0 +1,73 @@
+package figgy
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// ParamIterator iterates over the parameters under a path, requesting a page of them
+// at a time
+//
+//	it := figgy.Params(c, "/myapp/prod")
+//	for it.Next() {
+//		p := it.Param()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type ParamIterator struct {
+	c    ssmiface.SSMAPI
+	in   *ssm.GetParametersByPathInput
+	page []*ssm.Parameter
+	p    *ssm.Parameter
+	last bool
+	err  error
+}
+
+// Params returns an iterator over the parameters under path and the paths below it,
+// with SecureString values decrypted.  Requests are made as Load makes them, with
+// the metrics, tracing, breaker, rate limit and timeout set by the options.
+func Params(c ssmiface.SSMAPI, path string, opts ...Option) *ParamIterator {
+	o := newOptions(opts)
+	return &ParamIterator{
+		c: o.client(c, nil),
+		in: &ssm.GetParametersByPathInput{
+			Path:           aws.String(path),
+			Recursive:      aws.Bool(true),
+			WithDecryption: aws.Bool(true),
+		},
+	}
+}
+
+// Next advances to the next parameter, requesting the next page when needed.  It
+// returns false once there are no more parameters or a request fails.
+func (it *ParamIterator) Next() bool {
+	for len(it.page) == 0 {
+		if it.last || it.err != nil {
+			it.p = nil
+			return false
+		}
+		out, err := it.c.GetParametersByPath(it.in)
+		if err != nil {
+			it.err, it.p = err, nil
+			return false
+		}
+		it.page = out.Parameters
+		it.in.NextToken = out.NextToken
+		it.last = aws.StringValue(out.NextToken) == ""
+	}
+	it.p, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+// Param returns the current parameter
+func (it *ParamIterator) Param() *ssm.Parameter {
+	return it.p
+}
+
+// Err returns the error of the request that ended the iteration, if any
+func (it *ParamIterator) Err() error {
+	return it.err
+}
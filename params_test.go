@@ -0,0 +1,53 @@
+package figgy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingPathClient struct {
+	*MockSSMClient
+	pages int
+}
+
+func (c *failingPathClient) GetParametersByPath(i *ssm.GetParametersByPathInput) (*ssm.GetParametersByPathOutput, error) {
+	if c.pages == 0 {
+		return nil, errors.New("throttled")
+	}
+	c.pages--
+	return c.MockSSMClient.GetParametersByPath(i)
+}
+
+func TestParams(t *testing.T) {
+	c := newBatchClient()
+	m := &recordingMetrics{}
+	it := Params(c, "/app/svc", WithMetrics(m))
+	var names []string
+	for it.Next() {
+		names = append(names, aws.StringValue(it.Param().Name))
+	}
+	assert.NoError(t, it.Err())
+	assert.Len(t, names, 12)
+	assert.Equal(t, "/app/svc/a", names[0])
+	assert.Equal(t, []int{10, 2}, m.batches)
+	assert.False(t, it.Next())
+	assert.Nil(t, it.Param())
+
+	it = Params(NewMockSSMClient(), "/none")
+	assert.False(t, it.Next())
+	assert.NoError(t, it.Err())
+}
+
+func TestParamsError(t *testing.T) {
+	it := Params(&failingPathClient{MockSSMClient: newBatchClient().MockSSMClient, pages: 1}, "/app/svc")
+	n := 0
+	for it.Next() {
+		n++
+	}
+	assert.Equal(t, 10, n)
+	assert.EqualError(t, it.Err(), "throttled")
+}
@@ -0,0 +1,252 @@
+package figgy
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// maxAdvancedValueSize is the largest value, in bytes, of an advanced tier parameter
+const maxAdvancedValueSize = 8192
+
+// Types of parameter policies, which advanced tier parameters can have
+const (
+	PolicyExpiration             = "Expiration"
+	PolicyExpirationNotification = "ExpirationNotification"
+	PolicyNoChangeNotification   = "NoChangeNotification"
+)
+
+// Policy describes a policy of an advanced tier parameter
+type Policy struct {
+	// Type of the policy, such as PolicyExpiration
+	Type string
+	// Status of the policy, such as Pending or Finished
+	Status string
+	// Text of the policy as JSON
+	Text string
+	// Expires is when a parameter with an Expiration policy is deleted
+	Expires time.Time
+	// Before is how long before the parameter expires an ExpirationNotification
+	// policy sends its notification
+	Before time.Duration
+}
+
+// WithTier sets the tier, ssm.ParameterTierStandard or ssm.ParameterTierAdvanced, of
+// the parameters written by Store.  Fields with the chunks option are split into
+// parts of up to 8KB for the advanced tier, rather than 4KB.
+func WithTier(tier string) Option {
+	return func(o *options) {
+		o.tier = tier
+	}
+}
+
+// WithPolicyReport calls report after a load with the policies of each loaded
+// parameter that has any.  Policies are described with DescribeParameters, which
+// requires the ssm:DescribeParameters permission, and aren't reported for parameters
+// from other regions or accounts.
+func WithPolicyReport(report func(key string, policies []Policy)) Option {
+	return func(o *options) {
+		o.policyReport = report
+	}
+}
+
+// WithExpirationWarning logs, with WithLogger, the loaded parameters whose Expiration
+// policy deletes them within d, described as for WithPolicyReport
+func WithExpirationWarning(d time.Duration) Option {
+	return func(o *options) {
+		o.expirationWarning = d
+	}
+}
+
+// checkPolicies describes the policies of the fields' parameters, reporting them and
+// warning of expiring parameters when the options ask for it
+func (o *options) checkPolicies(c ssmiface.SSMAPI, f []*field) error {
+	if o.policyReport == nil && o.expirationWarning <= 0 {
+		return nil
+	}
+	keys := o.policyKeys(f)
+	policies, err := describePolicies(c, keys)
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		p, ok := policies[k]
+		if !ok {
+			continue
+		}
+		if o.policyReport != nil {
+			o.policyReport(k, p)
+		}
+		if o.expirationWarning <= 0 {
+			continue
+		}
+		for _, x := range p {
+			if x.Type == PolicyExpiration && time.Until(x.Expires) < o.expirationWarning {
+				o.logger.Debug("figgy: parameter expires soon", "key", k, "expires", x.Expires)
+			}
+		}
+	}
+	return nil
+}
+
+// policyKeys returns the distinct keys of the fields whose parameters can be described,
+// leaving out chunks, objects, fields loaded by path or wildcards, ARNs and parameters from other regions or accounts
+func (o *options) policyKeys(f []*field) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, x := range f {
+		if x.object || x.chunks || x.tree || x.glob || isARN(x.key) || o.remote(x) != (remote{}) || seen[x.key] {
+			continue
+		}
+		seen[x.key] = true
+		keys = append(keys, x.key)
+	}
+	return keys
+}
+
+// describePolicies returns the policies of the parameters named by keys that have any
+func describePolicies(c ssmiface.SSMAPI, keys []string) (map[string][]Policy, error) {
+	metadata, err := describeMetadata(c, keys)
+	if err != nil {
+		return nil, err
+	}
+	policies := make(map[string][]Policy)
+	for name, p := range metadata {
+		for _, x := range p.Policies {
+			policies[name] = append(policies[name], parsePolicy(x))
+		}
+	}
+	return policies, nil
+}
+
+// describeMetadata describes the parameters named by keys, by name.  Parameters that
+// don't exist are left out.
+func describeMetadata(c ssmiface.SSMAPI, keys []string) (map[string]*ssm.ParameterMetadata, error) {
+	metadata := make(map[string]*ssm.ParameterMetadata)
+	for i := 0; i < len(keys); i += maxDescribeParameters {
+		j := i + maxDescribeParameters
+		if j > len(keys) {
+			j = len(keys)
+		}
+		in := &ssm.DescribeParametersInput{
+			MaxResults: aws.Int64(maxDescribeParameters),
+			ParameterFilters: []*ssm.ParameterStringFilter{{
+				Key:    aws.String(ssm.ParametersFilterKeyName),
+				Option: aws.String("Equals"),
+				Values: aws.StringSlice(keys[i:j]),
+			}},
+		}
+		for {
+			res, err := c.DescribeParameters(in)
+			if err != nil {
+				return nil, err
+			}
+			for _, p := range res.Parameters {
+				metadata[aws.StringValue(p.Name)] = p
+			}
+			if aws.StringValue(res.NextToken) == "" {
+				break
+			}
+			in.NextToken = res.NextToken
+		}
+	}
+	return metadata, nil
+}
+
+// parsePolicy describes an inline policy, reading the time of an Expiration policy
+// and the notice given by an ExpirationNotification policy from its text
+func parsePolicy(p *ssm.ParameterInlinePolicy) Policy {
+	x := Policy{
+		Type:   aws.StringValue(p.PolicyType),
+		Status: aws.StringValue(p.PolicyStatus),
+		Text:   aws.StringValue(p.PolicyText),
+	}
+	var text struct {
+		Attributes struct {
+			Timestamp time.Time
+			Before    string
+			Unit      string
+		}
+	}
+	if json.Unmarshal([]byte(x.Text), &text) != nil {
+		return x
+	}
+	switch x.Type {
+	case PolicyExpiration:
+		x.Expires = text.Attributes.Timestamp
+	case PolicyExpirationNotification:
+		n, _ := strconv.Atoi(text.Attributes.Before)
+		x.Before = time.Duration(n) * time.Hour
+		if text.Attributes.Unit == "Days" {
+			x.Before *= 24
+		}
+	}
+	return x
+}
+
+// WithExpirationRefresh has a watcher reload parameters with an Expiration policy
+// shortly after the notification of an ExpirationNotification policy is sent, and
+// after they expire, rather than waiting for its notifier, so rotated parameters are
+// picked up promptly.  The policies are described when the watcher starts and after
+// each reload.
+func WithExpirationRefresh() Option {
+	return func(o *options) {
+		o.expirationRefresh = true
+	}
+}
+
+// expirationRefreshDelay is how long after a parameter expires, or its expiration
+// notification is sent, that a watcher reloads it, giving it time to be rotated
+var expirationRefreshDelay = time.Minute
+
+// nextExpiration describes the policies of the watched parameters, returning the
+// keys of those to reload at the next time one of them expires or is notified of
+// its expiration, and a channel that receives at that time.  The channel is nil
+// when no parameter has an expiration.
+func (w *Watcher) nextExpiration() (map[string]bool, <-chan time.Time) {
+	if len(w.policyKeys) == 0 {
+		return nil, nil
+	}
+	policies, err := describePolicies(w.o.guard(w.o.pace(w.o.limit(w.c))), w.policyKeys)
+	if err != nil {
+		w.o.logger.Debug("figgy: failed to describe parameter policies", "error", err)
+		return nil, nil
+	}
+	now := time.Now()
+	var next time.Time
+	keys := make(map[string]bool)
+	for key, p := range policies {
+		var expires, notified time.Time
+		for _, x := range p {
+			if !x.Expires.IsZero() {
+				expires = x.Expires
+			}
+		}
+		for _, x := range p {
+			if x.Before > 0 && !expires.IsZero() {
+				notified = expires.Add(-x.Before)
+			}
+		}
+		for _, t := range []time.Time{notified, expires} {
+			if t.IsZero() || !t.After(now) {
+				continue
+			}
+			if next.IsZero() || t.Before(next) {
+				next, keys = t, make(map[string]bool)
+			}
+			if t.Equal(next) {
+				keys[key] = false
+			}
+			break
+		}
+	}
+	if next.IsZero() {
+		return nil, nil
+	}
+	w.o.logger.Debug("figgy: scheduled reload for expiring parameters", "keys", sortedKeys(keys), "at", next)
+	return keys, time.After(next.Sub(now) + expirationRefreshDelay)
+}
@@ -0,0 +1,149 @@
+package figgy
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/stretchr/testify/assert"
+)
+
+// policySSMClient describes parameters with the policies in its map
+type policySSMClient struct {
+	*MockSSMClient
+	policies map[string][]*ssm.ParameterInlinePolicy
+}
+
+func (c *policySSMClient) DescribeParameters(i *ssm.DescribeParametersInput) (*ssm.DescribeParametersOutput, error) {
+	out, err := c.MockSSMClient.DescribeParameters(i)
+	if err == nil {
+		for _, p := range out.Parameters {
+			p.Policies = c.policies[aws.StringValue(p.Name)]
+		}
+	}
+	return out, err
+}
+
+func expirationPolicy(t time.Time) *ssm.ParameterInlinePolicy {
+	return &ssm.ParameterInlinePolicy{
+		PolicyType:   aws.String(PolicyExpiration),
+		PolicyStatus: aws.String("Pending"),
+		PolicyText:   aws.String(`{"Type":"Expiration","Version":"1.0","Attributes":{"Timestamp":"` + t.UTC().Format(time.RFC3339Nano) + `"}}`),
+	}
+}
+
+func TestWithPolicyReport(t *testing.T) {
+	soon := time.Now().Add(time.Hour).Truncate(time.Second)
+	later := time.Now().Add(30 * 24 * time.Hour).Truncate(time.Second)
+	notify := &ssm.ParameterInlinePolicy{
+		PolicyType:   aws.String(PolicyNoChangeNotification),
+		PolicyStatus: aws.String("Pending"),
+		PolicyText:   aws.String(`{"Type":"NoChangeNotification","Version":"1.0","Attributes":{"After":"20","Unit":"Days"}}`),
+	}
+	c := &policySSMClient{
+		MockSSMClient: NewMockSSMClientWith(map[string]string{"/app/token": "t", "/app/key": "k", "/app/host": "h"}),
+		policies: map[string][]*ssm.ParameterInlinePolicy{
+			"/app/token": {expirationPolicy(soon)},
+			"/app/key":   {expirationPolicy(later), notify},
+		},
+	}
+	var cfg struct {
+		Token string `ssm:"/app/token,decrypt"`
+		Key   string `ssm:"/app/key,decrypt"`
+		Host  string `ssm:"/app/host"`
+	}
+	reported := make(map[string][]Policy)
+	l := &recordingLogger{}
+	err := Load(c, &cfg, WithLogger(l), WithExpirationWarning(24*time.Hour), WithPolicyReport(func(key string, p []Policy) {
+		reported[key] = p
+	}))
+	assert.NoError(t, err)
+	assert.Len(t, reported, 2)
+	assert.Equal(t, PolicyExpiration, reported["/app/token"][0].Type)
+	assert.True(t, soon.Equal(reported["/app/token"][0].Expires))
+	assert.Equal(t, "Pending", reported["/app/key"][0].Status)
+	assert.Equal(t, PolicyNoChangeNotification, reported["/app/key"][1].Type)
+	assert.True(t, reported["/app/key"][1].Expires.IsZero())
+	assert.Contains(t, l.msgs, "figgy: parameter expires soon")
+	assert.Contains(t, l.args, []interface{}{"key", "/app/token", "expires", reported["/app/token"][0].Expires})
+	assert.NotContains(t, l.args, []interface{}{"key", "/app/key", "expires", reported["/app/key"][0].Expires})
+}
+
+func TestStoreWithTier(t *testing.T) {
+	in := struct {
+		Chunked string `ssm:"/chunked,chunks"`
+		Big     string `ssm:"/big"`
+	}{Chunked: strings.Repeat("x", maxAdvancedValueSize+1), Big: strings.Repeat("y", maxValueSize+1)}
+	m := &recordingSSMClient{MockSSMClient: NewMockSSMClient()}
+	assert.NoError(t, Store(m, &in, WithTier(ssm.ParameterTierAdvanced)))
+	var names []string
+	for _, i := range m.puts {
+		names = append(names, aws.StringValue(i.Name))
+		assert.Equal(t, ssm.ParameterTierAdvanced, aws.StringValue(i.Tier))
+	}
+	assert.Equal(t, []string{"/chunked/part-000", "/chunked/part-001", "/big"}, names)
+}
+
+// lockedPolicyClient is a policySSMClient that can be changed while it's watched
+type lockedPolicyClient struct {
+	mu sync.Mutex
+	policySSMClient
+}
+
+func (c *lockedPolicyClient) GetParameters(i *ssm.GetParametersInput) (*ssm.GetParametersOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.policySSMClient.GetParameters(i)
+}
+
+func (c *lockedPolicyClient) DescribeParameters(i *ssm.DescribeParametersInput) (*ssm.DescribeParametersOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.policySSMClient.DescribeParameters(i)
+}
+
+func TestWithExpirationRefresh(t *testing.T) {
+	defer func(d time.Duration) { expirationRefreshDelay = d }(expirationRefreshDelay)
+	expirationRefreshDelay = 0
+	c := &lockedPolicyClient{policySSMClient: policySSMClient{
+		MockSSMClient: NewMockSSMClientWith(map[string]string{"/app/token": "old", "/app/host": "h"}),
+		policies: map[string][]*ssm.ParameterInlinePolicy{
+			"/app/token": {expirationPolicy(time.Now().Add(300 * time.Millisecond))},
+		},
+	}}
+	var cfg struct {
+		Token string `ssm:"/app/token,decrypt"`
+		Host  string `ssm:"/app/host"`
+	}
+	w, err := Watch(c, &cfg, nil, make(chanNotifier), WithExpirationRefresh())
+	assert.NoError(t, err)
+	defer w.Stop()
+
+	// the parameter is rotated before it expires
+	c.mu.Lock()
+	setParameter(c.MockSSMClient, "/app/token", "new")
+	c.policies["/app/token"] = []*ssm.ParameterInlinePolicy{expirationPolicy(time.Now().Add(time.Hour))}
+	c.mu.Unlock()
+	assert.True(t, waitChange(t, w))
+	w.RLock()
+	assert.Equal(t, "new", cfg.Token)
+	w.RUnlock()
+}
+
+func TestParsePolicy(t *testing.T) {
+	p := parsePolicy(&ssm.ParameterInlinePolicy{
+		PolicyType: aws.String(PolicyExpirationNotification),
+		PolicyText: aws.String(`{"Type":"ExpirationNotification","Version":"1.0","Attributes":{"Before":"15","Unit":"Days"}}`),
+	})
+	assert.Equal(t, 15*24*time.Hour, p.Before)
+	p = parsePolicy(&ssm.ParameterInlinePolicy{
+		PolicyType: aws.String(PolicyExpirationNotification),
+		PolicyText: aws.String(`{"Type":"ExpirationNotification","Version":"1.0","Attributes":{"Before":"6","Unit":"Hours"}}`),
+	})
+	assert.Equal(t, 6*time.Hour, p.Before)
+	p = parsePolicy(&ssm.ParameterInlinePolicy{PolicyType: aws.String(PolicyExpiration), PolicyText: aws.String("not json")})
+	assert.True(t, p.Expires.IsZero())
+}
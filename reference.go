@@ -0,0 +1,219 @@
+package figgy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/cloudformation/cloudformationiface"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+const (
+	ssmReferencePrefix            = "ssm:"
+	secretsManagerReferencePrefix = "secretsmanager:"
+	exportReferencePrefix         = "cloudformation:"
+)
+
+// dynamicReference matches the CloudFormation dynamic references that can appear
+// within a value, such as "{{resolve:ssm:/other/param:2}}"
+var dynamicReference = regexp.MustCompile(`\{\{resolve:(ssm|ssm-secure|secretsmanager):([^}]+)\}\}`)
+
+// maxReferenceDepth is the maximum number of references followed for a single parameter
+const maxReferenceDepth = 10
+
+// WithReferences resolves parameter values that refer to another value.  A value of
+// "ssm:/other/param" is replaced by the value of the referenced parameter, loaded with
+// decryption, and a value of "secretsmanager:<secret id or arn>" is replaced by the
+// referenced secret.  References are followed until a plain value is found, and a
+// reference that loops back on itself is reported as an error.
+//
+// CloudFormation dynamic references within a value, such as
+// "jdbc:{{resolve:ssm:/db/host}}:5432" or
+// "{{resolve:secretsmanager:db:SecretString:password}}", are replaced by the values
+// they refer to once any references have been followed.
+//
+// Secrets Manager references fail to resolve when sm is nil.
+func WithReferences(sm secretsmanageriface.SecretsManagerAPI) Option {
+	return func(o *options) {
+		o.references = true
+		o.secrets = sm
+	}
+}
+
+// WithExports resolves references to CloudFormation exports using cf.  A value of
+// "cloudformation:<export name>" is replaced by the value of the named export, as
+// Fn::ImportValue would in a template.  It enables references like WithReferences.
+func WithExports(cf cloudformationiface.CloudFormationAPI) Option {
+	return func(o *options) {
+		o.references = true
+		o.exports = cf
+	}
+}
+
+// dereference follows any references in the value loaded for a field
+func (o *options) dereference(c ssmiface.SSMAPI, f *field, s string) (string, error) {
+	if !o.references {
+		return s, nil
+	}
+	seen := map[string]bool{ssmReferencePrefix + f.key: true}
+	for depth := 0; isReference(s); depth++ {
+		if seen[s] {
+			return "", fmt.Errorf("reference loop detected at '%s' for key '%s'", s, f.key)
+		}
+		if depth == maxReferenceDepth {
+			return "", fmt.Errorf("too many references for key '%s'", f.key)
+		}
+		seen[s] = true
+		v, err := o.resolveReference(c, s)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve reference '%s' for key '%s': %v", s, f.key, err)
+		}
+		s = v
+	}
+	return o.resolveDynamic(c, f, s)
+}
+
+func isReference(s string) bool {
+	return strings.HasPrefix(s, ssmReferencePrefix) ||
+		strings.HasPrefix(s, secretsManagerReferencePrefix) ||
+		strings.HasPrefix(s, exportReferencePrefix)
+}
+
+func (o *options) resolveReference(c ssmiface.SSMAPI, ref string) (string, error) {
+	switch {
+	case strings.HasPrefix(ref, secretsManagerReferencePrefix):
+		return o.getSecret(&secretsmanager.GetSecretValueInput{
+			SecretId: aws.String(strings.TrimPrefix(ref, secretsManagerReferencePrefix)),
+		})
+	case strings.HasPrefix(ref, exportReferencePrefix):
+		return o.getExport(strings.TrimPrefix(ref, exportReferencePrefix))
+	}
+	return getReferencedParameter(c, strings.TrimPrefix(ref, ssmReferencePrefix))
+}
+
+// resolveDynamic replaces the CloudFormation dynamic references within s
+func (o *options) resolveDynamic(c ssmiface.SSMAPI, f *field, s string) (string, error) {
+	var err error
+	s = dynamicReference.ReplaceAllStringFunc(s, func(ref string) string {
+		if err != nil {
+			return ""
+		}
+		m := dynamicReference.FindStringSubmatch(ref)
+		var v string
+		if m[1] == "secretsmanager" {
+			v, err = o.resolveDynamicSecret(m[2])
+		} else {
+			// SSM accepts name:version as a parameter name
+			v, err = getReferencedParameter(c, m[2])
+		}
+		if err != nil {
+			err = fmt.Errorf("failed to resolve reference '%s' for key '%s': %v", ref, f.key, err)
+		}
+		return v
+	})
+	if err != nil {
+		return "", err
+	}
+	return s, nil
+}
+
+// resolveDynamicSecret resolves the part of a secretsmanager dynamic reference after
+// its service, secret-id:SecretString:json-key:version-stage:version-id, where
+// everything but the secret id is optional and the secret id may be an ARN
+func (o *options) resolveDynamicSecret(ref string) (string, error) {
+	parts := strings.Split(ref, ":")
+	n := 1
+	if parts[0] == "arn" {
+		n = 7
+	}
+	if len(parts) < n {
+		return "", fmt.Errorf("invalid secret id in '%s'", ref)
+	}
+	in := &secretsmanager.GetSecretValueInput{SecretId: aws.String(strings.Join(parts[:n], ":"))}
+	parts = append(parts[n:], "", "", "", "")
+	if parts[0] != "" && parts[0] != "SecretString" {
+		return "", fmt.Errorf("unsupported secret value '%s'", parts[0])
+	}
+	if parts[2] != "" {
+		in.VersionStage = aws.String(parts[2])
+	}
+	if parts[3] != "" {
+		in.VersionId = aws.String(parts[3])
+	}
+	s, err := o.getSecret(in)
+	if err != nil || parts[1] == "" {
+		return s, err
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(s), &doc); err != nil {
+		return "", fmt.Errorf("secret is not a JSON object: %v", err)
+	}
+	v, ok := doc[parts[1]]
+	if !ok {
+		return "", fmt.Errorf("secret has no key '%s'", parts[1])
+	}
+	if str, ok := v.(string); ok {
+		return str, nil
+	}
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+func (o *options) getSecret(in *secretsmanager.GetSecretValueInput) (string, error) {
+	if o.secrets == nil {
+		return "", errors.New("no secrets manager client configured")
+	}
+	res, err := o.secrets.GetSecretValue(in)
+	if err != nil {
+		return "", err
+	}
+	if res.SecretString == nil {
+		return string(res.SecretBinary), nil
+	}
+	return aws.StringValue(res.SecretString), nil
+}
+
+// getExport returns the value of the CloudFormation export called name
+func (o *options) getExport(name string) (string, error) {
+	if o.exports == nil {
+		return "", errors.New("no CloudFormation client configured")
+	}
+	in := &cloudformation.ListExportsInput{}
+	for {
+		res, err := o.exports.ListExports(in)
+		if err != nil {
+			return "", err
+		}
+		for _, e := range res.Exports {
+			if aws.StringValue(e.Name) == name {
+				return aws.StringValue(e.Value), nil
+			}
+		}
+		if res.NextToken == nil {
+			return "", errors.New("export not found")
+		}
+		in.NextToken = res.NextToken
+	}
+}
+
+func getReferencedParameter(c ssmiface.SSMAPI, name string) (string, error) {
+	res, err := c.GetParameter(&ssm.GetParameterInput{
+		Name:           aws.String(name),
+		WithDecryption: aws.Bool(true),
+	})
+	if err != nil {
+		return "", err
+	}
+	if res.Parameter == nil {
+		return "", errors.New("parameter not found")
+	}
+	return aws.StringValue(res.Parameter.Value), nil
+}
@@ -0,0 +1,186 @@
+package figgy
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// remote is where parameters are loaded from other than with the client passed to
+// figgy: another region, an assumed role, or both
+type remote struct {
+	region string
+	role   string
+}
+
+func (r remote) String() string {
+	switch {
+	case r.role == "":
+		return "region " + r.region
+	case r.region == "":
+		return "role " + r.role
+	}
+	return "role " + r.role + " in region " + r.region
+}
+
+// remoteClients creates a client for each remote the first time it's needed
+type remoteClients struct {
+	mu      sync.Mutex
+	new     func(region, roleARN string) ssmiface.SSMAPI
+	clients map[remote]ssmiface.SSMAPI
+}
+
+// WithClients loads parameters from other regions, named by the region= tag option
+// or by WithRegionPrefix, and with assumed roles, set by WithRolePrefix, with clients
+// created by f.  Either argument of f may be empty, for the region or credentials of
+// the client passed to figgy.  A client is created once for each region and role and
+// reused.
+func WithClients(f func(region, roleARN string) ssmiface.SSMAPI) Option {
+	return func(o *options) {
+		o.remotes = &remoteClients{new: f, clients: make(map[remote]ssmiface.SSMAPI)}
+	}
+}
+
+// WithRegionalClients loads parameters from other regions, named by the region= tag
+// option or by WithRegionPrefix, with clients created by f.  Roles can't be assumed
+// with these clients, use WithClients or WithSession instead.
+func WithRegionalClients(f func(region string) ssmiface.SSMAPI) Option {
+	return WithClients(func(region, roleARN string) ssmiface.SSMAPI {
+		if roleARN != "" {
+			return nil
+		}
+		return f(region)
+	})
+}
+
+// WithSession loads parameters from other regions and with assumed roles with clients
+// created from p, such as a *session.Session.  Roles are assumed with STS using the
+// credentials of p.
+func WithSession(p client.ConfigProvider) Option {
+	return func(o *options) {
+		WithClients(func(region, roleARN string) ssmiface.SSMAPI {
+			return ssm.New(p, o.sessionConfig(p, region, roleARN))
+		})(o)
+	}
+}
+
+// sessionConfig is the config of a client created by WithSession
+func (o *options) sessionConfig(p client.ConfigProvider, region, roleARN string) *aws.Config {
+	cfg := aws.NewConfig()
+	if region != "" {
+		cfg = cfg.WithRegion(region)
+	}
+	if roleARN != "" {
+		cfg = cfg.WithCredentials(stscreds.NewCredentials(p, roleARN))
+	}
+	if o.endpoint != "" {
+		cfg = cfg.WithEndpoint(o.endpoint)
+	}
+	return cfg
+}
+
+// WithRegionPrefix loads the parameters with keys beginning with prefix from region,
+// unless their field names a region with the region= tag option.  The longest
+// matching prefix is used.
+func WithRegionPrefix(prefix, region string) Option {
+	return func(o *options) {
+		if o.regionPrefixes == nil {
+			o.regionPrefixes = make(map[string]string)
+		}
+		o.regionPrefixes[prefix] = region
+	}
+}
+
+// WithRolePrefix loads the parameters with keys beginning with prefix after assuming
+// the IAM role roleARN, for example to read parameters shared from another account.
+// The longest matching prefix is used.
+func WithRolePrefix(prefix, roleARN string) Option {
+	return func(o *options) {
+		if o.rolePrefixes == nil {
+			o.rolePrefixes = make(map[string]string)
+		}
+		o.rolePrefixes[prefix] = roleARN
+	}
+}
+
+// remote returns where to load the field from, the zero remote for the client's own
+// region and credentials
+func (o *options) remote(x *field) remote {
+	if x.object {
+		return remote{}
+	}
+	r := remote{region: x.region, role: longestPrefix(o.rolePrefixes, x.key)}
+	if r.region == "" {
+		r.region = longestPrefix(o.regionPrefixes, x.key)
+	}
+	return r
+}
+
+// longestPrefix returns the value of the longest prefix of key in m
+func longestPrefix(m map[string]string, key string) string {
+	var prefix, value string
+	for p, v := range m {
+		if strings.HasPrefix(key, p) && len(p) > len(prefix) {
+			prefix, value = p, v
+		}
+	}
+	return value
+}
+
+// remoteClient returns the client for r
+func (o *options) remoteClient(r remote) (ssmiface.SSMAPI, error) {
+	if o.remotes == nil {
+		return nil, fmt.Errorf("no client for %s, use WithSession or WithClients", r)
+	}
+	o.remotes.mu.Lock()
+	defer o.remotes.mu.Unlock()
+	c, ok := o.remotes.clients[r]
+	if !ok {
+		c = o.remotes.new(r.region, r.role)
+		if c == nil {
+			return nil, fmt.Errorf("no client for %s, use WithSession or WithClients", r)
+		}
+		o.remotes.clients[r] = c
+	}
+	return c, nil
+}
+
+// loadRemotes loads the fields with c, except those from other regions or with
+// assumed roles which are loaded with the client for their remote.  Requests are
+// traced under span.
+func loadRemotes(c ssmiface.SSMAPI, span Span, f []*field, o *options) error {
+	var local []*field
+	var remotes []remote
+	byRemote := make(map[remote][]*field)
+	for _, x := range f {
+		r := o.remote(x)
+		if r == (remote{}) {
+			local = append(local, x)
+			continue
+		}
+		if _, ok := byRemote[r]; !ok {
+			remotes = append(remotes, r)
+		}
+		byRemote[r] = append(byRemote[r], x)
+	}
+	if err := load(o.client(c, span), local, o); err != nil {
+		return err
+	}
+	for _, r := range remotes {
+		rc, err := o.remoteClient(r)
+		if err != nil {
+			return err
+		}
+		o.logger.Debug("figgy: loading parameters from "+r.String(), "keys", aws.StringValueSlice(parameterNames(byRemote[r])))
+		if err := load(o.client(rc, span), byRemote[r], o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package figgy
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+	"github.com/stretchr/testify/assert"
+)
+
+type RegionConfig struct {
+	Host    string `ssm:"/app/host"`
+	Standby string `ssm:"/app/host,region=us-west-2"`
+	Shared  string `ssm:"/shared/name"`
+}
+
+func TestRegion(t *testing.T) {
+	local := NewMockSSMClientWith(map[string]string{"/app/host": "east", "/shared/name": "local"})
+	west := NewMockSSMClientWith(map[string]string{"/app/host": "west"})
+	central := NewMockSSMClientWith(map[string]string{"/shared/name": "central"})
+	var created []string
+	clients := WithRegionalClients(func(region string) ssmiface.SSMAPI {
+		created = append(created, region)
+		if region == "us-west-2" {
+			return west
+		}
+		return central
+	})
+
+	var cfg RegionConfig
+	assert.NoError(t, Load(local, &cfg, clients, WithRegionPrefix("/shared/", "eu-west-1")))
+	assert.Equal(t, RegionConfig{Host: "east", Standby: "west", Shared: "central"}, cfg)
+	assert.Equal(t, []string{"us-west-2", "eu-west-1"}, created)
+
+	err := Load(local, &cfg)
+	assert.EqualError(t, err, "no client for region us-west-2, use WithSession or WithClients")
+}
+
+func TestWithSession(t *testing.T) {
+	o := newOptions([]Option{WithSession(session.Must(session.NewSession()))})
+	r := remote{region: "us-west-2", role: "arn:aws:iam::123456789012:role/config"}
+	c, err := o.remoteClient(r)
+	assert.NoError(t, err)
+	again, err := o.remoteClient(r)
+	assert.NoError(t, err)
+	assert.True(t, c == again)
+}
+
+func TestRolePrefix(t *testing.T) {
+	const role = "arn:aws:iam::123456789012:role/config"
+	local := NewMockSSMClientWith(map[string]string{"/app/host": "east"})
+	shared := NewMockSSMClientWith(map[string]string{"/app/host": "west", "/shared/name": "central"})
+	type remote struct{ region, role string }
+	var created []remote
+	clients := WithClients(func(region, roleARN string) ssmiface.SSMAPI {
+		created = append(created, remote{region, roleARN})
+		return shared
+	})
+
+	var cfg RegionConfig
+	assert.NoError(t, Load(local, &cfg, clients, WithRolePrefix("/shared/", role)))
+	assert.Equal(t, RegionConfig{Host: "east", Standby: "west", Shared: "central"}, cfg)
+	assert.Equal(t, []remote{{"us-west-2", ""}, {"", role}}, created)
+
+	// regional clients can't assume roles
+	err := Load(local, &cfg, WithRegionalClients(func(string) ssmiface.SSMAPI { return shared }), WithRolePrefix("/shared/", role))
+	assert.EqualError(t, err, "no client for role "+role+", use WithSession or WithClients")
+}
+
+func TestWatchRegion(t *testing.T) {
+	local := NewMockSSMClientWith(map[string]string{"/app/host": "east", "/shared/name": "local"})
+	west := NewMockSSMClientWith(map[string]string{"/app/host": "west"})
+	n := make(chanNotifier)
+	var cfg RegionConfig
+	w, err := Watch(local, &cfg, nil, n, WithRegionalClients(func(string) ssmiface.SSMAPI { return west }))
+	assert.NoError(t, err)
+	defer w.Stop()
+
+	// versions of parameters in other regions aren't described, so they're reloaded
+	setParameter(west, "/app/host", "west2")
+	n <- nil
+	assert.True(t, waitChange(t, w))
+	w.RLock()
+	assert.Equal(t, "west2", cfg.Standby)
+	assert.Equal(t, "east", cfg.Host)
+	w.RUnlock()
+}
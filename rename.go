@@ -0,0 +1,114 @@
+package figgy
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// Rename policies for fields with the old= tag option, chosen with the rename=
+// option when both the new and old parameters exist
+const (
+	// RenamePreferNew uses the new key's value, and is the default
+	RenamePreferNew = "prefer-new"
+	// RenamePreferOld uses the old key's value until the old parameter is deleted
+	RenamePreferOld = "prefer-old"
+	// RenameErrorIfDifferent fails the load when the values differ
+	RenameErrorIfDifferent = "error-if-different"
+)
+
+// Rename reports which key a renamed field was loaded from
+type Rename struct {
+	// Key is the field's new key
+	Key string
+	// Old is the key the parameter is being renamed from
+	Old string
+	// Used is the key whose value was assigned, either Key or Old
+	Used string
+	// Field the value was assigned to
+	Field string
+}
+
+// WithRenameReport calls report for each field with the old= tag option once it's
+// loaded, to track when the old parameters are no longer used and can be deleted
+func WithRenameReport(report func(Rename)) Option {
+	return func(o *options) {
+		o.renames = report
+	}
+}
+
+// isRenamePolicy is true for the values of the rename= tag option
+func isRenamePolicy(s string) bool {
+	switch s {
+	case RenamePreferNew, RenamePreferOld, RenameErrorIfDifferent:
+		return true
+	}
+	return false
+}
+
+// loadRenamed loads fields with an old key, requesting both keys and choosing one
+// by the field's rename policy
+func loadRenamed(c ssmiface.SSMAPI, f []*field, o *options) error {
+	var missing *InvalidParametersError
+	for _, x := range f {
+		o.logger.Debug("figgy: requesting renamed parameter", "key", x.key, "old", x.old, "decrypt", x.decrypt)
+		res, err := c.GetParameters(&ssm.GetParametersInput{
+			Names:          aws.StringSlice([]string{x.key, x.old}),
+			WithDecryption: aws.Bool(x.decrypt),
+		})
+		if err != nil {
+			return err
+		}
+		p, err := chooseRenamed(x, indexParameters(res.Parameters))
+		if e, ok := err.(*InvalidParametersError); ok && o.resetMissing {
+			o.logger.Debug("figgy: resetting fields of missing parameters", "keys", e.Names)
+			resetFields([]*field{x}, []string{x.key})
+			continue
+		}
+		if err != nil {
+			o.metrics.ParameterFailed(x.key)
+			if missing, err = collectMissing(missing, err); err != nil {
+				return err
+			}
+			continue
+		}
+		used := aws.StringValue(p.Name)
+		o.logger.Debug("figgy: loading renamed parameter", "key", x.key, "old", x.old, "used", used)
+		if o.renames != nil {
+			o.renames(Rename{Key: x.key, Old: x.old, Used: used, Field: x.field.Name})
+		}
+		if err := assign(c, x, aws.StringValue(p.Value), o); err != nil {
+			return err
+		}
+	}
+	if missing != nil {
+		return missing
+	}
+	return nil
+}
+
+// chooseRenamed picks the parameter for a renamed field from those found for its
+// new and old keys
+func chooseRenamed(x *field, idx map[string]*ssm.Parameter) (*ssm.Parameter, error) {
+	n, hasNew := idx[x.key]
+	old, hasOld := idx[x.old]
+	switch {
+	case !hasNew && !hasOld:
+		return nil, &InvalidParametersError{Names: []string{x.key, x.old}}
+	case !hasOld:
+		return n, nil
+	case !hasNew:
+		return old, nil
+	}
+	switch x.rename {
+	case RenamePreferOld:
+		return old, nil
+	case RenameErrorIfDifferent:
+		if aws.StringValue(n.Value) != aws.StringValue(old.Value) {
+			return nil, fmt.Errorf("parameters '%s' and '%s' for field %s have different values", x.key, x.old, x.field.Name)
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,101 @@
+package figgy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadRenamed(t *testing.T) {
+	type config struct {
+		Host string `ssm:"/app/db/host,old=/app/db-host"`
+		Port int    `ssm:"/app/db/port,old=/app/db-port,rename=prefer-old"`
+		User string `ssm:"/app/db/user,old=/app/db-user,rename=error-if-different"`
+	}
+	tests := []struct {
+		name string
+		data map[string]string
+		want config
+		used map[string]string
+		err  string
+	}{
+		{
+			name: "old keys only",
+			data: map[string]string{"/app/db-host": "old", "/app/db-port": "1", "/app/db-user": "u"},
+			want: config{Host: "old", Port: 1, User: "u"},
+			used: map[string]string{"Host": "/app/db-host", "Port": "/app/db-port", "User": "/app/db-user"},
+		},
+		{
+			name: "new keys only",
+			data: map[string]string{"/app/db/host": "new", "/app/db/port": "2", "/app/db/user": "u"},
+			want: config{Host: "new", Port: 2, User: "u"},
+			used: map[string]string{"Host": "/app/db/host", "Port": "/app/db/port", "User": "/app/db/user"},
+		},
+		{
+			name: "both keys",
+			data: map[string]string{
+				"/app/db-host": "old", "/app/db/host": "new",
+				"/app/db-port": "1", "/app/db/port": "2",
+				"/app/db-user": "u", "/app/db/user": "u",
+			},
+			want: config{Host: "new", Port: 1, User: "u"},
+			used: map[string]string{"Host": "/app/db/host", "Port": "/app/db-port", "User": "/app/db/user"},
+		},
+		{
+			name: "different values",
+			data: map[string]string{"/app/db/host": "new", "/app/db/port": "2", "/app/db-user": "a", "/app/db/user": "b"},
+			err:  "parameters '/app/db/user' and '/app/db-user' for field User have different values",
+		},
+		{
+			name: "neither key",
+			data: map[string]string{"/app/db/host": "new", "/app/db/port": "2"},
+			err:  "invalid parameters: /app/db/user, /app/db-user",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			used := make(map[string]string)
+			var c config
+			err := Load(NewMockSSMClientWith(tt.data), &c, WithRenameReport(func(r Rename) {
+				used[r.Field] = r.Used
+			}))
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, c)
+			assert.Equal(t, tt.used, used)
+		})
+	}
+}
+
+func TestReloadRenamed(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/db-host": "old"})
+	var c struct {
+		Host string `ssm:"/app/db/host,old=/app/db-host"`
+	}
+	assert.NoError(t, Load(m, &c))
+	assert.Equal(t, "old", c.Host)
+	delete(m.Data, "/app/db-host")
+	assert.NoError(t, Reload(m, &c))
+	assert.Equal(t, "", c.Host)
+}
+
+func TestRenameTagErrors(t *testing.T) {
+	tags := []interface{}{
+		&struct {
+			A string `ssm:"/a,rename=prefer-old"`
+		}{},
+		&struct {
+			A string `ssm:"/a,old=/b,rename=newest"`
+		}{},
+		&struct {
+			A string `ssm:"/a,chunks,old=/b"`
+		}{},
+	}
+	for _, v := range tags {
+		err := Load(NewMockSSMClient(), v)
+		assert.IsType(t, &TagParseError{}, err)
+	}
+}
@@ -0,0 +1,131 @@
+package figgy
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// InvalidValueError is returned when a loaded value breaks a rule set by a field's tag
+// options, such as oneof.  The values of fields with the decrypt option are redacted.
+type InvalidValueError struct {
+	// Field the value was loaded into
+	Field string
+	// Key of the parameter
+	Key string
+	// Value loaded
+	Value string
+	// Rule broken, as the tag option, such as oneof=debug|info
+	Rule string
+	// Allowed values of a oneof rule
+	Allowed []string
+}
+
+func (e *InvalidValueError) Error() string {
+	if len(e.Allowed) != 0 {
+		return fmt.Sprintf("invalid value '%s' for field %s from '%s': must be one of %s", e.Value, e.Field, e.Key, strings.Join(e.Allowed, ", "))
+	}
+	return fmt.Sprintf("invalid value '%s' for field %s from '%s': must satisfy %s", e.Value, e.Field, e.Key, e.Rule)
+}
+
+// checkRules checks the value assigned to a field against the rules of its tag options
+func checkRules(x *field) error {
+	v := x.value
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if len(x.oneof) != 0 && !oneOf(x, v) {
+		return x.invalid(v, "oneof="+strings.Join(x.oneof, "|"), x.oneof)
+	}
+	for _, b := range []struct {
+		rule  string
+		bound string
+		sign  int
+	}{{"min", x.min, -1}, {"max", x.max, 1}} {
+		if b.bound == "" {
+			continue
+		}
+		n, err := compare(x, v, b.bound)
+		if err != nil {
+			return err
+		}
+		if n == b.sign {
+			return x.invalid(v, b.rule+"="+b.bound, nil)
+		}
+	}
+	if x.match != nil && !x.match.MatchString(v.String()) {
+		return x.invalid(v, "match="+x.match.String(), nil)
+	}
+	return nil
+}
+
+// compare returns -1, 0 or 1 as v, the numeric value of x, is less than, equal to or
+// greater than bound, converted to the field's type
+func compare(x *field, v reflect.Value, bound string) (int, error) {
+	b := reflect.New(v.Type()).Elem()
+	if err := set(&field{value: b, durfmt: x.durfmt}, bound); err != nil {
+		return 0, fmt.Errorf("invalid bound '%s' for field %s: %v", bound, x.field.Name, err)
+	}
+	var less, greater bool
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		less, greater = v.Int() < b.Int(), v.Int() > b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		less, greater = v.Uint() < b.Uint(), v.Uint() > b.Uint()
+	case reflect.Float32, reflect.Float64:
+		less, greater = v.Float() < b.Float(), v.Float() > b.Float()
+	}
+	switch {
+	case less:
+		return -1, nil
+	case greater:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// isNumeric reports whether fields of type t can have the min and max options
+func isNumeric(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// isString reports whether fields of type t can have the match option
+func isString(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.String
+}
+
+// oneOf reports whether v, the value of x, equals one of the values allowed by its
+// oneof option, converted to the field's type
+func oneOf(x *field, v reflect.Value) bool {
+	for _, s := range x.oneof {
+		a := reflect.New(v.Type()).Elem()
+		if set(&field{value: a, durfmt: x.durfmt}, s) == nil && reflect.DeepEqual(a.Interface(), v.Interface()) {
+			return true
+		}
+	}
+	return false
+}
+
+// invalid returns an InvalidValueError for the value v of x breaking rule
+func (x *field) invalid(v reflect.Value, rule string, allowed []string) error {
+	s := fmt.Sprintf("%v", v.Interface())
+	if x.decrypt {
+		s = redacted
+	}
+	return &InvalidValueError{Field: x.field.Name, Key: x.key, Value: s, Rule: rule, Allowed: allowed}
+}
@@ -0,0 +1,118 @@
+package figgy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOneOf(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/level": "info", "/app/bad": "verbose", "/app/port": "8080", "/app/secret": "s"})
+	var cfg struct {
+		Level string  `ssm:"/app/level,oneof=debug|info|warn|error"`
+		Port  *int    `ssm:"/app/port,oneof=80|8080"`
+		Other float64 `ssm:"/app/port,oneof=8080.0"`
+	}
+	assert.NoError(t, Load(m, &cfg))
+	assert.Equal(t, "info", cfg.Level)
+	assert.Equal(t, 8080, *cfg.Port)
+
+	var bad struct {
+		Level string `ssm:"/app/bad,oneof=debug|info|warn|error"`
+	}
+	err := Load(m, &bad)
+	assert.Equal(t, &InvalidValueError{
+		Field:   "Level",
+		Key:     "/app/bad",
+		Value:   "verbose",
+		Rule:    "oneof=debug|info|warn|error",
+		Allowed: []string{"debug", "info", "warn", "error"},
+	}, err)
+	assert.EqualError(t, err, "invalid value 'verbose' for field Level from '/app/bad': must be one of debug, info, warn, error")
+
+	var port struct {
+		Port int `ssm:"/app/port,oneof=80|443"`
+	}
+	assert.IsType(t, &InvalidValueError{}, Load(m, &port))
+
+	var secret struct {
+		Secret string `ssm:"/app/secret,decrypt,oneof=a|b"`
+	}
+	err = Load(m, &secret)
+	if assert.IsType(t, &InvalidValueError{}, err) {
+		assert.Equal(t, redacted, err.(*InvalidValueError).Value)
+	}
+}
+
+func TestMinMaxMatch(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/port": "8080", "/app/ratio": "0.5", "/app/url": "https://example.com", "/app/timeout": "90s"})
+	var cfg struct {
+		Port    int           `ssm:"/app/port,min=1,max=65535"`
+		Ratio   *float64      `ssm:"/app/ratio,min=0,max=1"`
+		URL     string        `ssm:"/app/url,match=^https://"`
+		Timeout time.Duration `ssm:"/app/timeout,max=2m"`
+	}
+	assert.NoError(t, Load(m, &cfg))
+	assert.Equal(t, 8080, cfg.Port)
+
+	var low struct {
+		Port uint16 `ssm:"/app/port,min=9000"`
+	}
+	err := Load(m, &low)
+	assert.Equal(t, &InvalidValueError{Field: "Port", Key: "/app/port", Value: "8080", Rule: "min=9000"}, err)
+	assert.EqualError(t, err, "invalid value '8080' for field Port from '/app/port': must satisfy min=9000")
+
+	var high struct {
+		Ratio float32 `ssm:"/app/ratio,max=0.25"`
+	}
+	assert.IsType(t, &InvalidValueError{}, Load(m, &high))
+
+	var insecure struct {
+		URL string `ssm:"/app/url,match=^http://"`
+	}
+	err = Load(m, &insecure)
+	if assert.IsType(t, &InvalidValueError{}, err) {
+		assert.Equal(t, "match=^http://", err.(*InvalidValueError).Rule)
+	}
+
+	var tags = []interface{}{
+		&struct {
+			URL string `ssm:"/app/url,min=1"`
+		}{},
+		&struct {
+			Port int `ssm:"/app/port,match=^8"`
+		}{},
+		&struct {
+			URL string `ssm:"/app/url,match=("`
+		}{},
+		&struct {
+			Limits map[string]int `ssm:"/app/limits,path,max=10"`
+		}{},
+		&struct {
+			Ports map[string]int `ssm:"/app/*/port,min=1"`
+		}{},
+		&struct {
+			Levels map[string]string `ssm:"/app/levels,json,oneof=debug|info"`
+		}{},
+	}
+	for _, v := range tags {
+		assert.IsType(t, &TagParseError{}, Load(m, v))
+	}
+}
+
+func TestRuleLeavesField(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/port": "8080", "/app/level": "verbose"})
+	port := struct {
+		Port int `ssm:"/app/port,max=1024"`
+	}{Port: 80}
+	assert.IsType(t, &InvalidValueError{}, Load(m, &port))
+	assert.Equal(t, 80, port.Port)
+
+	level := struct {
+		Level String `ssm:"/app/level,oneof=debug|info"`
+	}{}
+	level.Level.Store("info")
+	assert.IsType(t, &InvalidValueError{}, Load(m, &level))
+	assert.Equal(t, "info", level.Level.Load())
+}
@@ -0,0 +1,122 @@
+package figgy
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// WithS3 loads fields tagged with s3:"bucket/key" from S3 objects using c, for
+// documents larger than Parameter Store allows.  The tag takes the options of an ssm
+// tag that apply to a whole value, such as json.
+//
+//	type Config struct {
+//		Routes []Route `s3:"myapp-config/prod/routes.json,json"`
+//	}
+//
+// Watchers check the ETags of the objects for changes.
+func WithS3(c s3iface.S3API) Option {
+	return func(o *options) {
+		o.s3 = c
+	}
+}
+
+// splitObjectKey splits the key of an s3 tag into its bucket and object key
+func splitObjectKey(key string) (bucket, object string) {
+	i := strings.Index(key, "/")
+	return key[:i], key[i+1:]
+}
+
+// parameterFields returns the fields that aren't loaded from S3
+func parameterFields(f []*field) []*field {
+	p := make([]*field, 0, len(f))
+	for _, x := range f {
+		if !x.object {
+			p = append(p, x)
+		}
+	}
+	return p
+}
+
+// loadObjects loads fields with an s3 tag from their objects.  Fields sharing an
+// object fetch it only once.
+func loadObjects(c ssmiface.SSMAPI, f []*field, o *options) error {
+	if len(f) == 0 {
+		return nil
+	}
+	if o.s3 == nil {
+		return fmt.Errorf("no S3 client to load field %s, use WithS3", f[0].field.Name)
+	}
+	bodies := make(map[string]string)
+	for _, x := range f {
+		s, ok := bodies[x.key]
+		if !ok {
+			o.logger.Debug("figgy: requesting object", "key", x.key)
+			var err error
+			s, err = getObject(o.s3, x.key)
+			if err != nil {
+				o.metrics.ParameterFailed(x.key)
+				return err
+			}
+			bodies[x.key] = s
+		}
+		if err := assign(c, x, s, o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getObject returns the contents of the object for key
+func getObject(c s3iface.S3API, key string) (string, error) {
+	bucket, object := splitObjectKey(key)
+	out, err := c.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(object),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to load object for key '%s': %v", key, err)
+	}
+	defer out.Body.Close()
+	b, err := ioutil.ReadAll(out.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to load object for key '%s': %v", key, err)
+	}
+	return string(b), nil
+}
+
+// objectsChanged reports whether the ETags of the objects for keys changed since they
+// were last checked.  A change is reported when an ETag can't be checked.
+func (w *Watcher) objectsChanged(keys []string) bool {
+	changed := false
+	for _, key := range keys {
+		bucket, object := splitObjectKey(key)
+		var etag string
+		out, err := w.o.s3.HeadObject(&s3.HeadObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(object),
+		})
+		if err != nil {
+			w.o.logger.Debug("figgy: failed to check object", "key", key, "error", err)
+		} else {
+			etag = aws.StringValue(out.ETag)
+		}
+		w.mu.Lock()
+		old, ok := w.etags[key]
+		if etag == "" {
+			delete(w.etags, key)
+		} else {
+			w.etags[key] = etag
+		}
+		w.mu.Unlock()
+		if etag == "" || !ok || etag != old {
+			changed = true
+		}
+	}
+	return changed
+}
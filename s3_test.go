@@ -0,0 +1,131 @@
+package figgy
+
+import (
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockS3Client struct {
+	s3iface.S3API
+	mu      sync.Mutex
+	objects map[string]string
+	etags   map[string]string
+	gets    int
+}
+
+func newMockS3Client(objects map[string]string) *mockS3Client {
+	c := &mockS3Client{objects: make(map[string]string), etags: make(map[string]string)}
+	for k, v := range objects {
+		c.put(k, v)
+	}
+	return c
+}
+
+func (c *mockS3Client) put(key, body string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.objects[key] = body
+	c.etags[key] = `"` + body + `"`
+}
+
+func (c *mockS3Client) GetObject(in *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := aws.StringValue(in.Bucket) + "/" + aws.StringValue(in.Key)
+	body, ok := c.objects[key]
+	if !ok {
+		return nil, awserr.New(s3.ErrCodeNoSuchKey, "no such key", nil)
+	}
+	c.gets++
+	return &s3.GetObjectOutput{
+		Body: ioutil.NopCloser(strings.NewReader(body)),
+		ETag: aws.String(c.etags[key]),
+	}, nil
+}
+
+func (c *mockS3Client) GetObjectWithContext(ctx aws.Context, in *s3.GetObjectInput, opts ...request.Option) (*s3.GetObjectOutput, error) {
+	return c.GetObject(in)
+}
+
+func (c *mockS3Client) HeadObject(in *s3.HeadObjectInput) (*s3.HeadObjectOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := aws.StringValue(in.Bucket) + "/" + aws.StringValue(in.Key)
+	etag, ok := c.etags[key]
+	if !ok {
+		return nil, awserr.New("NotFound", "not found", nil)
+	}
+	return &s3.HeadObjectOutput{ETag: aws.String(etag)}, nil
+}
+
+func (c *mockS3Client) HeadObjectWithContext(ctx aws.Context, in *s3.HeadObjectInput, opts ...request.Option) (*s3.HeadObjectOutput, error) {
+	return c.HeadObject(in)
+}
+
+type ObjectConfig struct {
+	Host   string   `ssm:"/app/host"`
+	Routes []string `s3:"config/app/routes.json,json"`
+	First  string   `s3:"config/app/routes.json,json,path=$[0]"`
+}
+
+func TestLoadObject(t *testing.T) {
+	c := NewMockSSMClientWith(map[string]string{"/app/host": "localhost"})
+	s := newMockS3Client(map[string]string{"config/app/routes.json": `["/a","/b"]`})
+
+	var cfg ObjectConfig
+	assert.NoError(t, Load(c, &cfg, WithS3(s)))
+	assert.Equal(t, ObjectConfig{Host: "localhost", Routes: []string{"/a", "/b"}, First: "/a"}, cfg)
+	assert.Equal(t, 1, s.gets)
+
+	err := Load(c, &cfg)
+	assert.EqualError(t, err, "no S3 client to load field Routes, use WithS3")
+}
+
+func TestObjectTagParseError(t *testing.T) {
+	c := NewMockSSMClient()
+	s := newMockS3Client(nil)
+	for _, v := range []interface{}{
+		&struct {
+			F string `s3:"config"`
+		}{},
+		&struct {
+			F string `s3:"config/app,decrypt"`
+		}{},
+		&struct {
+			F string `s3:"config/app,chunks"`
+		}{},
+		&struct {
+			F string `s3:"config/app,region=us-west-2"`
+		}{},
+	} {
+		_, ok := Load(c, v, WithS3(s)).(*TagParseError)
+		assert.True(t, ok)
+	}
+}
+
+func TestWatchObject(t *testing.T) {
+	c := NewMockSSMClientWith(map[string]string{"/app/host": "localhost"})
+	s := newMockS3Client(map[string]string{"config/app/routes.json": `["/a"]`})
+	n := make(chanNotifier)
+	var cfg ObjectConfig
+	w, err := Watch(c, &cfg, nil, n, WithS3(s))
+	assert.NoError(t, err)
+	defer w.Stop()
+
+	s.put("config/app/routes.json", `["/b","/c"]`)
+	n <- nil
+	assert.True(t, waitChange(t, w))
+	w.RLock()
+	assert.Equal(t, []string{"/b", "/c"}, cfg.Routes)
+	assert.Equal(t, "/b", cfg.First)
+	w.RUnlock()
+}
@@ -0,0 +1,73 @@
+package figgy
+
+import (
+	"reflect"
+)
+
+// Scrub clears the fields of v loaded with the decrypt option once the secrets are no
+// longer needed, so they don't stay in memory until they're collected.  Byte slices,
+// including those within decoded documents, are overwritten with zeros before the
+// fields are set to their zero value.  Strings can't be overwritten in Go, so string
+// fields are only cleared, and their values remain until they're collected.
+//
+// v must be a pointer to a struct, and shouldn't be read while it's scrubbed, so a
+// watched struct should be scrubbed after its watcher is stopped.
+func Scrub(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &InvalidTypeError{Type: reflect.TypeOf(v)}
+	}
+	f, err := walk(rv.Elem(), nil)
+	if err != nil {
+		return err
+	}
+	for _, x := range f {
+		if x.decrypt {
+			scrubValue(x.value)
+		}
+	}
+	return nil
+}
+
+// scrubValue overwrites the byte slices within v with zeros and sets v to its zero value
+func scrubValue(v reflect.Value) {
+	zeroBytes(v)
+	if v.CanSet() {
+		v.Set(reflect.Zero(v.Type()))
+	}
+}
+
+// zeroBytes overwrites the byte slices reachable from v with zeros
+func zeroBytes(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			for i := 0; i < v.Len(); i++ {
+				v.Index(i).SetUint(0)
+			}
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			zeroBytes(v.Index(i))
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			zeroBytes(v.Index(i))
+		}
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			zeroBytes(v.Elem())
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath == "" {
+				zeroBytes(v.Field(i))
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			zeroBytes(iter.Value())
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package figgy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScrub(t *testing.T) {
+	type creds struct {
+		User string `json:"user"`
+		Key  []byte `json:"key"`
+	}
+	m := NewMockSSMClientWith(map[string]string{
+		"/app/password": "p",
+		"/app/token":    "dG9rZW4=",
+		"/app/creds":    `{"user":"u","key":"a2V5"}`,
+		"/app/host":     "h",
+	})
+	var c struct {
+		Password string `ssm:"/app/password,decrypt"`
+		Token    []byte `ssm:"/app/token,decrypt"`
+		Creds    *creds `ssm:"/app/creds,decrypt,json"`
+		Host     string `ssm:"/app/host"`
+	}
+	assert.NoError(t, Load(m, &c))
+	token, key := c.Token, c.Creds.Key
+	assert.True(t, len(token) > 0)
+	assert.Equal(t, []byte("key"), key)
+
+	assert.NoError(t, Scrub(&c))
+	assert.Equal(t, "", c.Password)
+	assert.Nil(t, c.Token)
+	assert.Equal(t, &creds{}, c.Creds)
+	assert.Equal(t, "h", c.Host)
+	assert.Equal(t, make([]byte, len(token)), token)
+	assert.Equal(t, make([]byte, len(key)), key)
+
+	var s string
+	assert.IsType(t, &InvalidTypeError{}, Scrub(s))
+}
+
+func TestWatchReleasesSnapshots(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/host": "a", "/app/password": "p", "/app/port": "1"})
+	n := make(chanNotifier)
+	var cfg WatchConfig
+	w, err := Watch(m, &cfg, nil, n, WithSnapshots(2))
+	assert.NoError(t, err)
+	for _, p := range []string{"q", "r"} {
+		setParameter(m, "/app/password", p)
+		n <- nil
+		assert.True(t, waitChange(t, w))
+	}
+	assert.Len(t, w.History(), 2)
+	w.Stop()
+	assert.Empty(t, w.History())
+}
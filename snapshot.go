@@ -0,0 +1,107 @@
+package figgy
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Snapshot describes values of a watched struct that were loaded successfully
+type Snapshot struct {
+	// Loaded is when the values were loaded, or rolled back to
+	Loaded time.Time
+	// Changed are the names of the fields that changed from the previous snapshot
+	Changed []string
+}
+
+// snapshot is a Snapshot with a copy of the value of each field, in walk order
+type snapshot struct {
+	Snapshot
+	values []reflect.Value
+}
+
+// WithSnapshots has a watcher keep the last n versions of its struct's values, which
+// can be listed with History and restored with RollbackTo
+func WithSnapshots(n int) Option {
+	return func(o *options) {
+		o.snapshots = n
+	}
+}
+
+// History returns the snapshots kept with WithSnapshots, newest first.  The first
+// snapshot has the current values.
+func (w *Watcher) History() []Snapshot {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	h := make([]Snapshot, len(w.snapshots))
+	for i, s := range w.snapshots {
+		h[len(h)-1-i] = s.Snapshot
+	}
+	return h
+}
+
+// RollbackTo restores the values of snapshot n, as numbered by History, to the
+// struct.  The restored values are kept until a parameter changes again, and become
+// the newest snapshot.
+func (w *Watcher) RollbackTo(n int) error {
+	changes, err := w.rollback(n)
+	if err != nil {
+		return err
+	}
+	if len(changes) > 0 {
+		w.notifyChange()
+		w.publish(changes)
+	}
+	return nil
+}
+
+// rollback restores the values of snapshot n while holding the write lock
+func (w *Watcher) rollback(n int) ([]change, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if n < 1 || n >= len(w.snapshots) {
+		return nil, fmt.Errorf("figgy: no snapshot %d to roll back to", n)
+	}
+	s := w.snapshots[len(w.snapshots)-1-n]
+	live, err := walk(w.v.Elem(), w.current)
+	if err != nil {
+		return nil, err
+	}
+	var changes []change
+	var names []string
+	for i, x := range live {
+		if equalFields(x.value, s.values[i]) {
+			continue
+		}
+		ov := copyField(x.value)
+		setField(x.value, s.values[i])
+		changes = append(changes, change{index: i, field: x, old: ov, new: s.values[i]})
+		names = append(names, x.field.Name)
+	}
+	w.o.logger.Debug("figgy: rolled back", "snapshot", n, "fields", names)
+	w.snapshot(live, names)
+	return changes, nil
+}
+
+// snapshot keeps a copy of the values of the fields, discarding the oldest snapshot
+// when there are too many.  The caller holds the write lock.
+func (w *Watcher) snapshot(f []*field, changed []string) {
+	if w.o.snapshots <= 0 {
+		return
+	}
+	s := snapshot{
+		Snapshot: Snapshot{Loaded: time.Now(), Changed: changed},
+		values:   make([]reflect.Value, len(f)),
+	}
+	for i, x := range f {
+		s.values[i] = copyField(x.value)
+	}
+	w.snapshots = append(w.snapshots, s)
+	if n := len(w.snapshots) - w.o.snapshots; n > 0 {
+		// release the values of the discarded snapshots, which may hold secrets
+		for i := range w.snapshots[:n] {
+			w.snapshots[i] = snapshot{}
+		}
+		w.snapshots = w.snapshots[n:]
+	}
+}
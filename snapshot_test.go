@@ -0,0 +1,58 @@
+package figgy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshots(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/host": "a", "/app/password": "p", "/app/port": "1"})
+	n := make(chanNotifier)
+	var cfg WatchConfig
+	w, err := Watch(m, &cfg, nil, n, WithSnapshots(3))
+	assert.NoError(t, err)
+	defer w.Stop()
+	assert.Len(t, w.History(), 1)
+	assert.Error(t, w.RollbackTo(1))
+
+	for _, host := range []string{"b", "c", "d"} {
+		setParameter(m, "/app/host", host)
+		n <- []string{"/app/host"}
+		assert.True(t, waitChange(t, w))
+	}
+	h := w.History()
+	assert.Len(t, h, 3)
+	assert.Equal(t, []string{"Host"}, h[0].Changed)
+	assert.False(t, h[0].Loaded.Before(h[1].Loaded))
+
+	assert.NoError(t, w.RollbackTo(2))
+	assert.True(t, waitChange(t, w))
+	w.RLock()
+	assert.Equal(t, "b", cfg.Host)
+	assert.Equal(t, 1, cfg.Nested.Port)
+	w.RUnlock()
+	h = w.History()
+	assert.Len(t, h, 3)
+	assert.Equal(t, []string{"Host"}, h[0].Changed)
+
+	// the rolled back values are kept until a parameter changes
+	n <- []string{"/app/port"}
+	n.settle()
+	w.RLock()
+	assert.Equal(t, "b", cfg.Host)
+	w.RUnlock()
+
+	assert.Error(t, w.RollbackTo(3))
+	assert.Error(t, w.RollbackTo(0))
+}
+
+func TestNoSnapshots(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/host": "a", "/app/password": "p", "/app/port": "1"})
+	var cfg WatchConfig
+	w, err := Watch(m, &cfg, nil, make(chanNotifier))
+	assert.NoError(t, err)
+	defer w.Stop()
+	assert.Empty(t, w.History())
+	assert.Error(t, w.RollbackTo(1))
+}
@@ -0,0 +1,84 @@
+package figgy
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+)
+
+// SQSNotifier is a Notifier that receives Parameter Store change events from an SQS
+// queue.  The queue is the target of an EventBridge rule matching events with the
+// source "aws.ssm" and detail type "Parameter Store Change", either directly or
+// through an SNS topic.  Received messages are deleted, so each watcher needs its
+// own queue.
+type SQSNotifier struct {
+	Client   sqsiface.SQSAPI
+	QueueURL string
+}
+
+// parameterChangeEvent is the part of a Parameter Store change event that's needed
+type parameterChangeEvent struct {
+	Source     string `json:"source"`
+	DetailType string `json:"detail-type"`
+	Detail     struct {
+		Name string `json:"name"`
+	} `json:"detail"`
+}
+
+// snsNotification is the envelope of a message delivered to SQS by SNS
+type snsNotification struct {
+	Type    string
+	Message string
+}
+
+// Notify long polls the queue until it receives messages, returning the names of the
+// parameters changed by any Parameter Store change events among them
+func (n *SQSNotifier) Notify(ctx context.Context) ([]string, error) {
+	for {
+		res, err := n.Client.ReceiveMessageWithContext(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:            aws.String(n.QueueURL),
+			MaxNumberOfMessages: aws.Int64(10),
+			WaitTimeSeconds:     aws.Int64(20),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(res.Messages) == 0 {
+			continue
+		}
+		names := []string{}
+		for _, m := range res.Messages {
+			if name, ok := changedParameter(aws.StringValue(m.Body)); ok {
+				names = append(names, name)
+			}
+			_, err := n.Client.DeleteMessageWithContext(ctx, &sqs.DeleteMessageInput{
+				QueueUrl:      aws.String(n.QueueURL),
+				ReceiptHandle: m.ReceiptHandle,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		return names, nil
+	}
+}
+
+// changedParameter returns the name of the parameter changed by a Parameter Store
+// change event, unwrapping it from an SNS notification if needed
+func changedParameter(body string) (string, bool) {
+	var sn snsNotification
+	if json.Unmarshal([]byte(body), &sn) == nil && sn.Type == "Notification" {
+		body = sn.Message
+	}
+	var e parameterChangeEvent
+	if err := json.Unmarshal([]byte(body), &e); err != nil {
+		return "", false
+	}
+	if e.Source != "aws.ssm" || e.DetailType != "Parameter Store Change" || e.Detail.Name == "" {
+		return "", false
+	}
+	return e.Detail.Name, true
+}
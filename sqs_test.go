@@ -0,0 +1,74 @@
+package figgy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockSQSClient returns one batch of messages per receive
+type mockSQSClient struct {
+	sqsiface.SQSAPI
+	batches [][]string
+	deleted []string
+	err     error
+}
+
+func (c *mockSQSClient) ReceiveMessageWithContext(ctx aws.Context, in *sqs.ReceiveMessageInput, opts ...request.Option) (*sqs.ReceiveMessageOutput, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	out := &sqs.ReceiveMessageOutput{}
+	if len(c.batches) == 0 {
+		return out, nil
+	}
+	for i, body := range c.batches[0] {
+		out.Messages = append(out.Messages, &sqs.Message{
+			Body:          aws.String(body),
+			ReceiptHandle: aws.String(string(rune('a' + i))),
+		})
+	}
+	c.batches = c.batches[1:]
+	return out, nil
+}
+
+func (c *mockSQSClient) DeleteMessageWithContext(ctx aws.Context, in *sqs.DeleteMessageInput, opts ...request.Option) (*sqs.DeleteMessageOutput, error) {
+	c.deleted = append(c.deleted, aws.StringValue(in.ReceiptHandle))
+	return &sqs.DeleteMessageOutput{}, nil
+}
+
+func changeEvent(name string) string {
+	return `{"version":"0","detail-type":"Parameter Store Change","source":"aws.ssm",` +
+		`"detail":{"operation":"Update","name":"` + name + `","type":"String"}}`
+}
+
+func TestSQSNotifier(t *testing.T) {
+	sns, err := json.Marshal(snsNotification{Type: "Notification", Message: changeEvent("/app/port")})
+	assert.NoError(t, err)
+	c := &mockSQSClient{batches: [][]string{
+		{},
+		{changeEvent("/app/host"), string(sns), `{"source":"aws.ec2"}`, "not json"},
+	}}
+	n := &SQSNotifier{Client: c, QueueURL: "https://sqs/queue"}
+	names, err := n.Notify(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/app/host", "/app/port"}, names)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, c.deleted)
+
+	c = &mockSQSClient{batches: [][]string{{"not json"}}}
+	names, err = (&SQSNotifier{Client: c}).Notify(context.Background())
+	assert.NoError(t, err)
+	assert.NotNil(t, names)
+	assert.Empty(t, names)
+
+	c = &mockSQSClient{err: errors.New("receive failed")}
+	_, err = (&SQSNotifier{Client: c}).Notify(context.Background())
+	assert.EqualError(t, err, "receive failed")
+}
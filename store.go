@@ -0,0 +1,302 @@
+package figgy
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// maxValueSize is the largest value, in bytes, of a standard tier parameter
+const maxValueSize = 4096
+
+// WithKMSKey sets the KMS key ID, ARN, or alias used to encrypt SecureString parameters
+// written by Store.  The account's default key is used otherwise.
+func WithKMSKey(id string) Option {
+	return func(o *options) {
+		o.keyID = id
+	}
+}
+
+// WithOverwrite allows Store to replace parameters that already exist
+func WithOverwrite() Option {
+	return func(o *options) {
+		o.overwrite = true
+	}
+}
+
+// Store writes the values of a struct's fields to AWS Parameter Store using the same
+// tags as Load.  Fields with the decrypt option are written as SecureString parameters
+// and fields with the chunks option are split into parts.  Fields behind a nil pointer
+// are skipped.
+//
+// Existing parameters are only replaced when the WithOverwrite option is given.
+func Store(c ssmiface.SSMAPI, v interface{}, opts ...Option) error {
+	return StoreWithParameters(c, v, nil, opts...)
+}
+
+// StoreWithParameters writes the values of a struct's fields to AWS Parameter Store,
+// performing parameter substitution on field tags the same way as LoadWithParameters.
+func StoreWithParameters(c ssmiface.SSMAPI, v interface{}, data interface{}, opts ...Option) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &InvalidTypeError{Type: reflect.TypeOf(v)}
+	}
+	f, err := inspect(rv.Elem(), rv.Elem().Type(), data, "")
+	f = parameterFields(f)
+	if err != nil {
+		return err
+	}
+	o := newOptions(opts)
+	o.normalizeKeys(f)
+	in, parts, err := putParameterInputs(f, o)
+	if err != nil {
+		return err
+	}
+	for _, i := range in {
+		if _, err := c.PutParameter(i); err != nil {
+			return fmt.Errorf("failed to store parameter for key '%s': %v", aws.StringValue(i.Name), err)
+		}
+	}
+	if !o.overwrite {
+		return nil
+	}
+	for _, x := range f {
+		if n, ok := parts[x.key]; ok {
+			if err := deleteSurplusChunks(c, x.key, n); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// putParameterInputs builds the requests to store the fields, so every value can be
+// encoded before anything is written, along with the number of parts of each chunked
+// key
+func putParameterInputs(f []*field, o *options) ([]*ssm.PutParameterInput, map[string]int, error) {
+	var in []*ssm.PutParameterInput
+	parts := make(map[string]int)
+	seen := make(map[string]bool, len(f))
+	for _, x := range f {
+		if !x.value.IsValid() || seen[x.key] {
+			continue
+		}
+		seen[x.key] = true
+		s, err := encode(x)
+		if err != nil {
+			return nil, nil, err
+		}
+		// Parameter Store can't hold empty values
+		if s == "" {
+			return nil, nil, fmt.Errorf("cannot store an empty value for field %s", x.field.Name)
+		}
+		t := ssm.ParameterTypeString
+		if x.decrypt {
+			t = ssm.ParameterTypeSecureString
+		}
+		values := map[string]string{x.key: s}
+		if x.chunks {
+			size := maxValueSize
+			if o.tier == ssm.ParameterTierAdvanced {
+				size = maxAdvancedValueSize
+			}
+			values = make(map[string]string)
+			for i, part := range splitChunks(s, size) {
+				values[chunkKey(x.key, i)] = part
+			}
+			parts[x.key] = len(values)
+		}
+		keys := make([]string, 0, len(values))
+		for k := range values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			i := &ssm.PutParameterInput{
+				Name:      aws.String(k),
+				Value:     aws.String(values[k]),
+				Type:      aws.String(t),
+				Overwrite: aws.Bool(o.overwrite),
+			}
+			if x.decrypt && o.keyID != "" {
+				i.KeyId = aws.String(o.keyID)
+			}
+			if o.tier != "" {
+				i.Tier = aws.String(o.tier)
+			}
+			in = append(in, i)
+		}
+	}
+	return in, parts, nil
+}
+
+// deleteSurplusChunks deletes the parts of a chunked parameter after its first n,
+// left from a longer value it overwrote, which would otherwise be loaded as part of
+// the new value
+func deleteSurplusChunks(c ssmiface.SSMAPI, key string, n int) error {
+	names, err := chunkNames(c, key)
+	if err != nil {
+		return err
+	}
+	for i := n; i < len(names); i += maxParameters {
+		j := i + maxParameters
+		if j > len(names) {
+			j = len(names)
+		}
+		_, err := c.DeleteParameters(&ssm.DeleteParametersInput{
+			Names: aws.StringSlice(names[i:j]),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete surplus parts of key '%s': %v", key, err)
+		}
+	}
+	return nil
+}
+
+// splitChunks splits a value into parts of at most n bytes without splitting a character
+func splitChunks(s string, n int) []string {
+	var parts []string
+	for len(s) > n {
+		i := n
+		for i > 0 && !utf8.RuneStart(s[i]) {
+			i--
+		}
+		parts = append(parts, s[:i])
+		s = s[i:]
+	}
+	return append(parts, s)
+}
+
+// encode a field's value the way set would decode it
+func encode(f *field) (string, error) {
+	v := f.value
+	switch {
+	case f.nilas != "" && v.IsNil():
+		return f.nilas, nil
+	case f.emptyas != "" && v.IsZero():
+		return f.emptyas, nil
+	case f.path != "" || f.tree:
+		return "", fmt.Errorf("cannot store field %s using the 'path' option", f.field.Name)
+	case f.glob:
+		return "", fmt.Errorf("cannot store field %s with a wildcard key", f.field.Name)
+	case f.format != "":
+		return "", fmt.Errorf("cannot store field %s using the '%s' option", f.field.Name, f.format)
+	case f.dotenv:
+		return encodeDotenv(f)
+	case f.json:
+		if v.Type() == rawType {
+			return string(v.Bytes()), nil
+		}
+		b, err := json.Marshal(v.Interface())
+		if err != nil {
+			return "", fmt.Errorf("json marshal error for field '%s'", f.field.Name)
+		}
+		return string(b), nil
+	}
+	s, err := encodeValue(v, f.durfmt)
+	if err != nil {
+		return "", fmt.Errorf("%v for field %s", err, f.field.Name)
+	}
+	return s, nil
+}
+
+// encodeValue encodes v, with durations in the format durfmt
+func encodeValue(v reflect.Value, durfmt string) (string, error) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil
+		}
+		return encodeValue(v.Elem(), durfmt)
+	}
+	if m := marshaler(v); m != nil {
+		return m.MarshalParameter()
+	}
+	if v.Type() == rawType {
+		return string(v.Bytes()), nil
+	}
+	if v.Type().AssignableTo(durationType) {
+		return formatDuration(time.Duration(v.Int()), durfmt), nil
+	}
+	if m := textMarshaler(v); m != nil {
+		b, err := m.MarshalText()
+		return string(b), err
+	}
+	switch v.Kind() {
+	case reflect.Slice:
+		l := make([]string, v.Len())
+		for i := range l {
+			s, err := encodeValue(v.Index(i), durfmt)
+			if err != nil {
+				return "", err
+			}
+			l[i] = s
+		}
+		return strings.Join(l, ","), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	case reflect.Complex64, reflect.Complex128:
+		return strconv.FormatComplex(v.Complex(), 'g', -1, v.Type().Bits()), nil
+	}
+	return "", fmt.Errorf("cannot encode type %s", v.Type().String())
+}
+
+// encodeDotenv encodes a map field as sorted KEY=VALUE lines
+func encodeDotenv(f *field) (string, error) {
+	v := f.value
+	if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+		return "", fmt.Errorf("cannot use 'dotenv' option on a non map[string] type: %s %s", f.field.Name, f.field.Type.String())
+	}
+	l := make([]string, 0, v.Len())
+	for _, k := range v.MapKeys() {
+		s, err := encodeValue(v.MapIndex(k), f.durfmt)
+		if err != nil {
+			return "", fmt.Errorf("%v for field %s", err, f.field.Name)
+		}
+		l = append(l, k.String()+"="+strconv.Quote(s))
+	}
+	sort.Strings(l)
+	return strings.Join(l, "\n"), nil
+}
+
+func marshaler(v reflect.Value) Marshaler {
+	if m, ok := addressable(v).Interface().(Marshaler); ok {
+		return m
+	}
+	return nil
+}
+
+func textMarshaler(v reflect.Value) encoding.TextMarshaler {
+	if m, ok := addressable(v).Interface().(encoding.TextMarshaler); ok {
+		return m
+	}
+	return nil
+}
+
+// addressable returns a pointer to v, or to a copy of v, so methods with a pointer
+// receiver are found
+func addressable(v reflect.Value) reflect.Value {
+	if v.CanAddr() {
+		return v.Addr()
+	}
+	p := reflect.New(v.Type())
+	p.Elem().Set(v)
+	return p
+}
@@ -0,0 +1,196 @@
+package figgy
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingSSMClient struct {
+	*MockSSMClient
+	puts []*ssm.PutParameterInput
+}
+
+func (c *recordingSSMClient) PutParameter(i *ssm.PutParameterInput) (*ssm.PutParameterOutput, error) {
+	c.puts = append(c.puts, i)
+	return c.MockSSMClient.PutParameter(i)
+}
+
+type StoreConfig struct {
+	String   string            `ssm:"/{{.env}}/string"`
+	Password string            `ssm:"/{{.env}}/password,decrypt"`
+	Int      int               `ssm:"/{{.env}}/int"`
+	Float    float64           `ssm:"/{{.env}}/float"`
+	Complex  complex128        `ssm:"/{{.env}}/complex"`
+	Duration time.Duration     `ssm:"/{{.env}}/duration"`
+	Slice    []int             `ssm:"/{{.env}}/slice"`
+	BigInt   big.Int           `ssm:"/{{.env}}/bigint"`
+	JSON     SimpleJSON        `ssm:"/{{.env}}/json,json"`
+	Env      map[string]string `ssm:"/{{.env}}/env,dotenv"`
+	Chunked  string            `ssm:"/{{.env}}/chunked,chunks"`
+	Custom   str               `ssm:"/{{.env}}/custom"`
+	PNested  *Nested
+	Ignored  string `ssm:"-"`
+}
+
+func (c *str) MarshalParameter() (string, error) {
+	return strings.TrimPrefix(string(*c), "cs-"), nil
+}
+
+func TestStore(t *testing.T) {
+	in := StoreConfig{
+		String:   "this is a string",
+		Password: "hunter2",
+		Int:      -42,
+		Float:    1.25,
+		Complex:  1 + 2i,
+		Duration: 90 * time.Second,
+		Slice:    []int{1, 2, 3},
+		JSON:     SimpleJSON{F1: 1, F2: "2"},
+		Env:      map[string]string{"B": "2", "A": "one two"},
+		Chunked:  strings.Repeat("x", maxValueSize+1),
+		Custom:   "cs-custom",
+	}
+	in.BigInt.SetString("123456789012345678901234567890", 10)
+	m := &recordingSSMClient{MockSSMClient: NewMockSSMClient()}
+	err := StoreWithParameters(m, &in, P{"env": "dev"}, WithKMSKey("alias/config"))
+	assert.NoError(t, err)
+
+	var out StoreConfig
+	err = LoadWithParameters(m, &out, P{"env": "dev"})
+	assert.NoError(t, err)
+	assert.Equal(t, in.String, out.String)
+	assert.Equal(t, in.Password, out.Password)
+	assert.Equal(t, in.Int, out.Int)
+	assert.Equal(t, in.Float, out.Float)
+	assert.Equal(t, in.Complex, out.Complex)
+	assert.Equal(t, in.Duration, out.Duration)
+	assert.Equal(t, in.Slice, out.Slice)
+	assert.Equal(t, in.BigInt.String(), out.BigInt.String())
+	assert.Equal(t, in.JSON, out.JSON)
+	assert.Equal(t, in.Env, out.Env)
+	assert.Equal(t, in.Chunked, out.Chunked)
+	assert.Equal(t, in.Custom, out.Custom)
+
+	_, ok := m.Data["/dev/chunked/part-001"]
+	assert.True(t, ok)
+	for _, i := range m.puts {
+		switch aws.StringValue(i.Name) {
+		case "/dev/password":
+			assert.Equal(t, ssm.ParameterTypeSecureString, aws.StringValue(i.Type))
+			assert.Equal(t, "alias/config", aws.StringValue(i.KeyId))
+		default:
+			assert.Equal(t, ssm.ParameterTypeString, aws.StringValue(i.Type))
+			assert.Nil(t, i.KeyId)
+		}
+		// fields behind a nil pointer are not stored
+		assert.NotEqual(t, "/dev/pstring", aws.StringValue(i.Name))
+	}
+	assert.Nil(t, in.PNested)
+}
+
+func TestStoreOverwrite(t *testing.T) {
+	c := struct {
+		String string `ssm:"string"`
+	}{String: "updated"}
+	m := NewMockSSMClient()
+	err := Store(m, &c)
+	assert.Error(t, err)
+	err = Store(m, &c, WithOverwrite())
+	assert.NoError(t, err)
+	assert.Equal(t, "updated", aws.StringValue(m.Data["string"].Parameter.Value))
+}
+
+func TestStoreChunksOverwrite(t *testing.T) {
+	c := struct {
+		Chunked string `ssm:"/app/chunked,chunks"`
+	}{Chunked: strings.Repeat("x", 3*maxValueSize)}
+	m := NewMockSSMClient()
+	assert.NoError(t, Store(m, &c))
+	_, ok := m.Data["/app/chunked/part-002"]
+	assert.True(t, ok)
+
+	// the parts of the longer value aren't loaded with the shorter one
+	c.Chunked = "short"
+	assert.NoError(t, Store(m, &c, WithOverwrite()))
+	_, ok = m.Data["/app/chunked/part-001"]
+	assert.False(t, ok)
+	c.Chunked = ""
+	assert.NoError(t, Load(m, &c))
+	assert.Equal(t, "short", c.Chunked)
+}
+
+func TestStoreEmptyAs(t *testing.T) {
+	type config struct {
+		Host string  `ssm:"/app/host,emptyas=-"`
+		Port *int    `ssm:"/app/port,emptyas=-"`
+		Name *string `ssm:"/app/name,emptyas=-"`
+	}
+	name := "n"
+	in := config{Name: &name}
+	m := NewMockSSMClient()
+	assert.NoError(t, Store(m, &in))
+	assert.Equal(t, "-", aws.StringValue(m.Data["/app/host"].Parameter.Value))
+	assert.Equal(t, "-", aws.StringValue(m.Data["/app/port"].Parameter.Value))
+	assert.Equal(t, "n", aws.StringValue(m.Data["/app/name"].Parameter.Value))
+
+	out := config{Host: "h"}
+	assert.NoError(t, Load(m, &out))
+	assert.Equal(t, in, out)
+	diffs, err := Diff(m, &in)
+	assert.NoError(t, err)
+	assert.Empty(t, diffs)
+}
+
+func TestStoreNilAs(t *testing.T) {
+	type config struct {
+		Limit   *int     `ssm:"/app/limit,nilas=unset"`
+		Retries *int     `ssm:"/app/retries,nilas=unset"`
+		Hosts   []string `ssm:"/app/hosts,nilas=unset"`
+	}
+	retries := 0
+	in := config{Retries: &retries}
+	m := NewMockSSMClient()
+	assert.NoError(t, Store(m, &in))
+	assert.Equal(t, "unset", aws.StringValue(m.Data["/app/limit"].Parameter.Value))
+	assert.Equal(t, "0", aws.StringValue(m.Data["/app/retries"].Parameter.Value))
+	assert.Equal(t, "unset", aws.StringValue(m.Data["/app/hosts"].Parameter.Value))
+
+	out := config{Hosts: []string{"a"}}
+	assert.NoError(t, Load(m, &out))
+	assert.Equal(t, in, out)
+	diffs, err := Diff(m, &in)
+	assert.NoError(t, err)
+	assert.Empty(t, diffs)
+}
+
+func TestStoreErrors(t *testing.T) {
+	tests := map[string]interface{}{
+		"non ptr": struct{}{},
+		"path": &struct {
+			Host string `ssm:"/app/config,json,path=$.host"`
+		}{},
+		"unsupported type": &struct {
+			Func func() `ssm:"/app/func"`
+		}{},
+		"empty value": &struct {
+			Host string `ssm:"/app/host"`
+		}{},
+	}
+	for n, in := range tests {
+		err := Store(NewMockSSMClient(), in)
+		assert.Error(t, err, "test '%s' failed", n)
+	}
+}
+
+func TestSplitChunks(t *testing.T) {
+	assert.Equal(t, []string{""}, splitChunks("", 4))
+	assert.Equal(t, []string{"abcd", "ef"}, splitChunks("abcdef", 4))
+	// never split a multibyte character
+	assert.Equal(t, []string{"ab", "éf"}, splitChunks("abéf", 3))
+}
@@ -0,0 +1,90 @@
+package figgy
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// OnChange calls fn each time the watched field addressed by fieldPtr changes.  fn
+// is a func(old, new T), where T is the type of the field, and is called by the
+// watcher without holding its lock, after Changes is notified.
+//
+//	w.OnChange(&cfg.LogLevel, func(old, new string) {
+//		log.Printf("log level changed from %s to %s", old, new)
+//	})
+func (w *Watcher) OnChange(fieldPtr interface{}, fn interface{}) error {
+	ptr := reflect.ValueOf(fieldPtr)
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() {
+		return &InvalidTypeError{Type: reflect.TypeOf(fieldPtr)}
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	live, err := walk(w.v.Elem(), w.current)
+	if err != nil {
+		return err
+	}
+	i := -1
+	for j, x := range live {
+		if x.value.Addr().Pointer() == ptr.Pointer() && x.value.Type() == ptr.Type().Elem() {
+			i = j
+			break
+		}
+	}
+	if i < 0 {
+		return fmt.Errorf("figgy: %s does not address a watched field", ptr.Type())
+	}
+	t := live[i].value.Type()
+	f := reflect.ValueOf(fn)
+	if f.Kind() != reflect.Func || f.Type().NumIn() != 2 || f.Type().NumOut() != 0 ||
+		f.Type().In(0) != t || f.Type().In(1) != t {
+		return fmt.Errorf("figgy: OnChange for field %s requires a func(old, new %s)", live[i].field.Name, t)
+	}
+	if w.subscriptions == nil {
+		w.subscriptions = make(map[int][]reflect.Value)
+	}
+	w.subscriptions[i] = append(w.subscriptions[i], f)
+	return nil
+}
+
+// OnRotate calls fn each time a watched field with the decrypt option changes, with
+// the key of its parameter and its old and new values, so connections can be
+// re-established or tokens re-signed when a secret is rotated.  The values are given
+// only to fn, and are never logged.  fn is called as for OnChange.
+//
+//	w.OnRotate(func(key string, old, new interface{}) {
+//		if key == "/myapp/prod/db/password" {
+//			db.Reconnect(new.(string))
+//		}
+//	})
+func (w *Watcher) OnRotate(fn func(key string, old, new interface{})) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.rotations = append(w.rotations, fn)
+}
+
+// publish calls the OnChange functions of the changed fields, and the OnRotate
+// functions of those with the decrypt option
+func (w *Watcher) publish(changes []change) {
+	w.mu.RLock()
+	var calls []func()
+	for _, c := range changes {
+		for _, f := range w.subscriptions[c.index] {
+			f, args := f, []reflect.Value{c.old, c.new}
+			calls = append(calls, func() { f.Call(args) })
+		}
+		if !c.field.decrypt {
+			continue
+		}
+		for _, f := range w.rotations {
+			f, key, old, new := f, c.field.key, c.old.Interface(), c.new.Interface()
+			calls = append(calls, func() { f(key, old, new) })
+		}
+	}
+	w.mu.RUnlock()
+	for _, call := range calls {
+		call()
+	}
+	if w.o.publish != nil {
+		w.o.publish(changes)
+	}
+}
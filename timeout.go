@@ -0,0 +1,95 @@
+package figgy
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// WithCallTimeout gives each request to Parameter Store at most d, within any
+// deadline of the context it's made with, so one slow request fails without using
+// up the time left for the others
+func WithCallTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.callTimeout = d
+	}
+}
+
+// timedSSM makes each request with a context that times out, and that ends by the
+// deadline of the load when it has one
+type timedSSM struct {
+	ssmiface.SSMAPI
+	timeout  time.Duration
+	deadline time.Time
+}
+
+// limit wraps c to time out each of its requests, unless no call timeout or
+// startup budget is configured
+func (o *options) limit(c ssmiface.SSMAPI) ssmiface.SSMAPI {
+	if o.callTimeout <= 0 && o.deadline.IsZero() {
+		return c
+	}
+	return &timedSSM{SSMAPI: c, timeout: o.callTimeout, deadline: o.deadline}
+}
+
+// context limits ctx by the timeout and deadline
+func (c *timedSSM) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	cancel := func() {}
+	if !c.deadline.IsZero() {
+		ctx, cancel = context.WithDeadline(ctx, c.deadline)
+	}
+	if c.timeout > 0 {
+		parent := cancel
+		var timeout context.CancelFunc
+		ctx, timeout = context.WithTimeout(ctx, c.timeout)
+		cancel = func() {
+			timeout()
+			parent()
+		}
+	}
+	return ctx, cancel
+}
+
+func (c *timedSSM) GetParameter(in *ssm.GetParameterInput) (*ssm.GetParameterOutput, error) {
+	return c.GetParameterWithContext(context.Background(), in)
+}
+
+func (c *timedSSM) GetParameterWithContext(ctx aws.Context, in *ssm.GetParameterInput, opts ...request.Option) (*ssm.GetParameterOutput, error) {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	return c.SSMAPI.GetParameterWithContext(ctx, in, opts...)
+}
+
+func (c *timedSSM) GetParameters(in *ssm.GetParametersInput) (*ssm.GetParametersOutput, error) {
+	return c.GetParametersWithContext(context.Background(), in)
+}
+
+func (c *timedSSM) GetParametersWithContext(ctx aws.Context, in *ssm.GetParametersInput, opts ...request.Option) (*ssm.GetParametersOutput, error) {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	return c.SSMAPI.GetParametersWithContext(ctx, in, opts...)
+}
+
+func (c *timedSSM) GetParametersByPath(in *ssm.GetParametersByPathInput) (*ssm.GetParametersByPathOutput, error) {
+	return c.GetParametersByPathWithContext(context.Background(), in)
+}
+
+func (c *timedSSM) GetParametersByPathWithContext(ctx aws.Context, in *ssm.GetParametersByPathInput, opts ...request.Option) (*ssm.GetParametersByPathOutput, error) {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	return c.SSMAPI.GetParametersByPathWithContext(ctx, in, opts...)
+}
+
+func (c *timedSSM) DescribeParameters(in *ssm.DescribeParametersInput) (*ssm.DescribeParametersOutput, error) {
+	return c.DescribeParametersWithContext(context.Background(), in)
+}
+
+func (c *timedSSM) DescribeParametersWithContext(ctx aws.Context, in *ssm.DescribeParametersInput, opts ...request.Option) (*ssm.DescribeParametersOutput, error) {
+	ctx, cancel := c.context(ctx)
+	defer cancel()
+	return c.SSMAPI.DescribeParametersWithContext(ctx, in, opts...)
+}
@@ -0,0 +1,77 @@
+package figgy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/stretchr/testify/assert"
+)
+
+// slowSSMClient takes delay to answer requests, unless their context is done first
+type slowSSMClient struct {
+	*MockSSMClient
+	delay     time.Duration
+	deadlines int
+}
+
+func (c *slowSSMClient) GetParametersWithContext(ctx aws.Context, in *ssm.GetParametersInput, opts ...request.Option) (*ssm.GetParametersOutput, error) {
+	if _, ok := ctx.Deadline(); ok {
+		c.deadlines++
+	}
+	select {
+	case <-time.After(c.delay):
+		return c.MockSSMClient.GetParameters(in)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *slowSSMClient) DescribeParametersWithContext(ctx aws.Context, in *ssm.DescribeParametersInput, opts ...request.Option) (*ssm.DescribeParametersOutput, error) {
+	select {
+	case <-time.After(c.delay):
+		return c.MockSSMClient.DescribeParameters(in)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestWithCallTimeout(t *testing.T) {
+	s := struct {
+		S string `ssm:"string"`
+		I int    `ssm:"int,decrypt"`
+	}{}
+	c := &slowSSMClient{MockSSMClient: NewMockSSMClient(), delay: time.Millisecond}
+	assert.NoError(t, Load(c, &s, WithCallTimeout(time.Second)))
+	assert.Equal(t, 2, c.deadlines)
+	assert.Equal(t, "this is a string", s.S)
+
+	c = &slowSSMClient{MockSSMClient: NewMockSSMClient(), delay: time.Second}
+	start := time.Now()
+	err := Load(c, &s, WithCallTimeout(10*time.Millisecond))
+	assert.Equal(t, &BatchError{Keys: []string{"string"}, Err: context.DeadlineExceeded}, err)
+	assert.True(t, time.Since(start) < c.delay)
+}
+
+func TestWithCallTimeoutContextSpan(t *testing.T) {
+	// the timeout applies within the context of the span
+	c := &contextSSMClient{MockSSMClient: NewMockSSMClient()}
+	s := struct {
+		S string `ssm:"string"`
+	}{}
+	assert.NoError(t, Load(c, &s, WithTracer(contextTracer{}), WithCallTimeout(time.Second)))
+	assert.Equal(t, []interface{}{"figgy.GetParameters"}, c.spans)
+}
+
+func TestWithCallTimeoutWatcher(t *testing.T) {
+	// version polls time out rather than stalling the watcher
+	c := &slowSSMClient{MockSSMClient: NewMockSSMClient(), delay: time.Second}
+	w := &Watcher{c: c, o: newOptions([]Option{WithCallTimeout(10 * time.Millisecond)})}
+	start := time.Now()
+	_, err := w.describeVersions(map[string]bool{"string": false})
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.True(t, time.Since(start) < c.delay)
+}
@@ -0,0 +1,141 @@
+package figgy
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// Tracer starts spans around loading parameters.  It can be implemented with a few
+// lines on top of an OpenTelemetry trace.Tracer, keeping the context of each span to
+// start its children.
+type Tracer interface {
+	// Start a span with the given name, as a child of parent unless it's nil
+	Start(parent Span, name string) Span
+}
+
+// Span is an operation being traced
+type Span interface {
+	// SetAttribute describes the operation
+	SetAttribute(key string, value interface{})
+	// End the span, recording err as its status
+	End(err error)
+}
+
+// ContextSpan is implemented by spans that carry a context, such as an AWS X-Ray
+// subsegment.  Requests to Parameter Store traced by the span are made with its
+// context, so that a client instrumented with xray.AWS records them as its children
+// rather than as calls without a parent.
+type ContextSpan interface {
+	Span
+	Context() context.Context
+}
+
+// WithTracer traces loads with t.  A "figgy.Load" span has the number of fields
+// loaded as its "figgy.fields" attribute, and a "figgy.GetParameters" child span for
+// each request to Parameter Store has the number of parameters requested and whether
+// they were decrypted as its "figgy.parameters" and "figgy.decrypt" attributes.
+func WithTracer(t Tracer) Option {
+	return func(o *options) {
+		o.tracer = t
+	}
+}
+
+type nopTracer struct{}
+
+func (nopTracer) Start(Span, string) Span { return nopSpan{} }
+
+type nopSpan struct{}
+
+func (nopSpan) SetAttribute(string, interface{}) {}
+
+func (nopSpan) End(error) {}
+
+// tracedSSM starts a span for each request to Parameter Store
+type tracedSSM struct {
+	ssmiface.SSMAPI
+	tracer Tracer
+	parent Span
+}
+
+// trace wraps c to trace its requests as children of parent, unless no tracer is configured
+func (o *options) trace(c ssmiface.SSMAPI, parent Span) ssmiface.SSMAPI {
+	if _, ok := o.tracer.(nopTracer); ok {
+		return c
+	}
+	return &tracedSSM{SSMAPI: c, tracer: o.tracer, parent: parent}
+}
+
+func (c *tracedSSM) start(name string, parameters int, decrypt *bool) Span {
+	s := c.tracer.Start(c.parent, name)
+	s.SetAttribute("figgy.parameters", parameters)
+	s.SetAttribute("figgy.decrypt", aws.BoolValue(decrypt))
+	return s
+}
+
+func (c *tracedSSM) GetParameter(in *ssm.GetParameterInput) (*ssm.GetParameterOutput, error) {
+	s := c.start("figgy.GetParameter", 1, in.WithDecryption)
+	var out *ssm.GetParameterOutput
+	var err error
+	if cs, ok := s.(ContextSpan); ok {
+		out, err = c.SSMAPI.GetParameterWithContext(cs.Context(), in)
+	} else {
+		out, err = c.SSMAPI.GetParameter(in)
+	}
+	s.End(err)
+	return out, err
+}
+
+func (c *tracedSSM) GetParameterWithContext(ctx aws.Context, in *ssm.GetParameterInput, opts ...request.Option) (*ssm.GetParameterOutput, error) {
+	s := c.start("figgy.GetParameter", 1, in.WithDecryption)
+	out, err := c.SSMAPI.GetParameterWithContext(ctx, in, opts...)
+	s.End(err)
+	return out, err
+}
+
+func (c *tracedSSM) GetParameters(in *ssm.GetParametersInput) (*ssm.GetParametersOutput, error) {
+	s := c.start("figgy.GetParameters", len(in.Names), in.WithDecryption)
+	var out *ssm.GetParametersOutput
+	var err error
+	if cs, ok := s.(ContextSpan); ok {
+		out, err = c.SSMAPI.GetParametersWithContext(cs.Context(), in)
+	} else {
+		out, err = c.SSMAPI.GetParameters(in)
+	}
+	s.End(err)
+	return out, err
+}
+
+func (c *tracedSSM) GetParametersWithContext(ctx aws.Context, in *ssm.GetParametersInput, opts ...request.Option) (*ssm.GetParametersOutput, error) {
+	s := c.start("figgy.GetParameters", len(in.Names), in.WithDecryption)
+	out, err := c.SSMAPI.GetParametersWithContext(ctx, in, opts...)
+	s.End(err)
+	return out, err
+}
+
+func (c *tracedSSM) GetParametersByPath(in *ssm.GetParametersByPathInput) (*ssm.GetParametersByPathOutput, error) {
+	s := c.start("figgy.GetParametersByPath", 0, in.WithDecryption)
+	s.SetAttribute("figgy.path", aws.StringValue(in.Path))
+	var out *ssm.GetParametersByPathOutput
+	var err error
+	if cs, ok := s.(ContextSpan); ok {
+		out, err = c.SSMAPI.GetParametersByPathWithContext(cs.Context(), in)
+	} else {
+		out, err = c.SSMAPI.GetParametersByPath(in)
+	}
+	s.SetAttribute("figgy.parameters", pathParameters(out))
+	s.End(err)
+	return out, err
+}
+
+func (c *tracedSSM) GetParametersByPathWithContext(ctx aws.Context, in *ssm.GetParametersByPathInput, opts ...request.Option) (*ssm.GetParametersByPathOutput, error) {
+	s := c.start("figgy.GetParametersByPath", 0, in.WithDecryption)
+	s.SetAttribute("figgy.path", aws.StringValue(in.Path))
+	out, err := c.SSMAPI.GetParametersByPathWithContext(ctx, in, opts...)
+	s.SetAttribute("figgy.parameters", pathParameters(out))
+	s.End(err)
+	return out, err
+}
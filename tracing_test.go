@@ -0,0 +1,133 @@
+package figgy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingTracer struct {
+	spans []*recordingSpan
+}
+
+type recordingSpan struct {
+	name   string
+	parent *recordingSpan
+	attrs  map[string]interface{}
+	ended  bool
+	err    error
+}
+
+func (t *recordingTracer) Start(parent Span, name string) Span {
+	s := &recordingSpan{name: name, attrs: make(map[string]interface{})}
+	if parent != nil {
+		s.parent = parent.(*recordingSpan)
+	}
+	t.spans = append(t.spans, s)
+	return s
+}
+
+func (s *recordingSpan) SetAttribute(key string, value interface{}) {
+	s.attrs[key] = value
+}
+
+func (s *recordingSpan) End(err error) {
+	s.ended = true
+	s.err = err
+}
+
+func TestWithTracer(t *testing.T) {
+	tr := &recordingTracer{}
+	s := struct {
+		S string `ssm:"string"`
+		I int    `ssm:"int,decrypt"`
+		B bool   `ssm:"bool"`
+	}{}
+	err := Load(NewMockSSMClient(), &s, WithTracer(tr))
+	assert.NoError(t, err)
+	assert.Len(t, tr.spans, 3)
+	root := tr.spans[0]
+	assert.Equal(t, "figgy.Load", root.name)
+	assert.Equal(t, 3, root.attrs["figgy.fields"])
+	for i, want := range []map[string]interface{}{
+		{"figgy.parameters": 2, "figgy.decrypt": false},
+		{"figgy.parameters": 1, "figgy.decrypt": true},
+	} {
+		span := tr.spans[i+1]
+		assert.Equal(t, "figgy.GetParameters", span.name)
+		assert.Equal(t, root, span.parent)
+		assert.Equal(t, want, span.attrs)
+		assert.True(t, span.ended)
+	}
+	assert.True(t, root.ended)
+	assert.Nil(t, root.err)
+
+	tr = &recordingTracer{}
+	missing := struct {
+		S string `ssm:"/no/such/param"`
+	}{}
+	err = Load(NewMockSSMClient(), &missing, WithTracer(tr))
+	assert.Error(t, err)
+	assert.Equal(t, err, tr.spans[0].err)
+}
+
+func TestWithTracerJSONParameter(t *testing.T) {
+	tr := &recordingTracer{}
+	var c struct {
+		F1 int
+	}
+	assert.NoError(t, LoadJSONParameter(NewMockSSMClient(), "simplejson", &c, WithTracer(tr)))
+	assert.Equal(t, 1, c.F1)
+	if assert.Len(t, tr.spans, 3) {
+		assert.Equal(t, "figgy.LoadJSONParameter", tr.spans[0].name)
+		assert.Equal(t, "figgy.GetParameter", tr.spans[1].name)
+		assert.Equal(t, tr.spans[0], tr.spans[1].parent)
+		assert.True(t, tr.spans[0].ended)
+	}
+}
+
+type spanKey struct{}
+
+// contextTracer starts spans that carry a context naming the span, like X-Ray subsegments
+type contextTracer struct{}
+
+type contextSpan struct {
+	nopSpan
+	ctx context.Context
+}
+
+func (contextTracer) Start(parent Span, name string) Span {
+	return contextSpan{ctx: context.WithValue(context.Background(), spanKey{}, name)}
+}
+
+func (s contextSpan) Context() context.Context {
+	return s.ctx
+}
+
+// contextSSMClient records the span named by the context of each request
+type contextSSMClient struct {
+	*MockSSMClient
+	spans []interface{}
+}
+
+func (c *contextSSMClient) GetParametersWithContext(ctx aws.Context, in *ssm.GetParametersInput, opts ...request.Option) (*ssm.GetParametersOutput, error) {
+	c.spans = append(c.spans, ctx.Value(spanKey{}))
+	return c.MockSSMClient.GetParameters(in)
+}
+
+func TestWithTracerContextSpan(t *testing.T) {
+	c := &contextSSMClient{MockSSMClient: NewMockSSMClient()}
+	s := struct {
+		S string `ssm:"string"`
+		I int    `ssm:"int,decrypt"`
+	}{}
+	err := Load(c, &s, WithTracer(contextTracer{}))
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"figgy.GetParameters", "figgy.GetParameters"}, c.spans)
+	assert.Equal(t, "this is a string", s.S)
+	assert.Equal(t, 2, s.I)
+}
@@ -0,0 +1,72 @@
+package figgy
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// isTreeType reports whether fields of type t can have the bare path option, which
+// loads a map keyed by the names of the parameters under the field's key
+func isTreeType(t reflect.Type) bool {
+	return t.Kind() == reflect.Map && t.Key().Kind() == reflect.String
+}
+
+// loadTrees loads the map fields with the bare path option from the parameters under
+// their keys, requested with GetParametersByPath
+func loadTrees(c ssmiface.SSMAPI, f []*field, o *options) error {
+	for _, x := range f {
+		path := strings.TrimSuffix(x.key, "/")
+		o.logger.Debug("figgy: requesting parameters for map", "field", x.field.Name, "path", path, "decrypt", x.decrypt)
+		params, err := getParametersByPath(c, path, true, x.decrypt)
+		if err != nil {
+			return err
+		}
+		if err := setTree(x, path, params); err != nil {
+			o.metrics.ParameterFailed(x.key)
+			return err
+		}
+		x.loaded = true
+	}
+	return nil
+}
+
+// setTree sets a map field to the values of params, keyed by their names relative to
+// path, such as "beta" or "checkout/v2" for parameters under /app/prod/features
+func setTree(x *field, path string, params []*ssm.Parameter) error {
+	return setCollection(x, params, func(name string) string {
+		return strings.TrimPrefix(name, path+"/")
+	})
+}
+
+// setCollection sets a map field to the values of params keyed by the key of their
+// names, or a slice field to the values in the order of params
+func setCollection(x *field, params []*ssm.Parameter, key func(name string) string) error {
+	t := x.value.Type()
+	var c reflect.Value
+	if t.Kind() == reflect.Map {
+		c = reflect.MakeMapWithSize(t, len(params))
+	} else {
+		c = reflect.MakeSlice(t, 0, len(params))
+	}
+	for _, p := range params {
+		e := reflect.New(t.Elem()).Elem()
+		y := &field{field: x.field, value: e, json: x.json, durfmt: x.durfmt}
+		if err := set(y, aws.StringValue(p.Value)); err != nil {
+			if ce, ok := err.(*ConvertTypeError); ok {
+				ce.Field = x.field.Name
+			}
+			return err
+		}
+		if t.Kind() == reflect.Map {
+			c.SetMapIndex(reflect.ValueOf(key(aws.StringValue(p.Name))).Convert(t.Key()), e)
+		} else {
+			c = reflect.Append(c, e)
+		}
+	}
+	setField(x.value, c)
+	return nil
+}
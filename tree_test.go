@@ -0,0 +1,46 @@
+package figgy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newFeatureClient() *countingSSMClient {
+	return &countingSSMClient{MockSSMClient: NewMockSSMClientWith(map[string]string{
+		"/app/prod/features/beta":        "true",
+		"/app/prod/features/checkout/v2": "false",
+		"/app/dev/features/beta":         "false",
+		"/app/prod/limits/rps":           "100",
+	})}
+}
+
+func TestLoadTree(t *testing.T) {
+	type config struct {
+		Features map[string]bool `ssm:"/app/{{.env}}/features/,path"`
+		Limits   map[string]int  `ssm:"/app/{{.env}}/limits,path"`
+		Empty    map[string]int  `ssm:"/app/{{.env}}/none,path"`
+	}
+	c := newFeatureClient()
+	var cfg config
+	assert.NoError(t, LoadWithParameters(c, &cfg, P{"env": "prod"}))
+	assert.Equal(t, map[string]bool{"beta": true, "checkout/v2": false}, cfg.Features)
+	assert.Equal(t, map[string]int{"rps": 100}, cfg.Limits)
+	assert.Equal(t, map[string]int{}, cfg.Empty)
+	assert.Equal(t, []string{"/app/prod/features", "/app/prod/limits", "/app/prod/none"}, c.paths)
+	assert.Empty(t, c.batches)
+
+	var dev config
+	assert.NoError(t, LoadWithParameters(newFeatureClient(), &dev, P{"env": "dev"}))
+	assert.Equal(t, map[string]bool{"beta": false}, dev.Features)
+
+	var bad struct {
+		Limits map[string]bool `ssm:"/app/prod/limits,path"`
+	}
+	assert.Equal(t, &ConvertTypeError{Field: "Limits", Type: "bool", Value: "100"}, Load(newFeatureClient(), &bad))
+
+	var notMap struct {
+		Features []string `ssm:"/app/prod/features,path"`
+	}
+	assert.IsType(t, &TagParseError{}, Load(newFeatureClient(), &notMap))
+}
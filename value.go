@@ -0,0 +1,245 @@
+package figgy
+
+import (
+	"reflect"
+	"sync/atomic"
+	"time"
+)
+
+// atomicValue is implemented by the value types below so a Watcher replaces their
+// values atomically rather than copying over them while they're being read
+type atomicValue interface {
+	load() interface{}
+	store(interface{})
+}
+
+// value holds the value of one of the value types
+type value struct {
+	v atomic.Value
+}
+
+func (x *value) load() interface{} {
+	return x.v.Load()
+}
+
+func (x *value) store(v interface{}) {
+	x.v.Store(v)
+}
+
+// unmarshal decodes s as a parameter value of type t and stores it
+func (x *value) unmarshal(t reflect.Type, s string) error {
+	v := reflect.New(t).Elem()
+	if err := set(&field{value: v}, s); err != nil {
+		return err
+	}
+	x.store(v.Interface())
+	return nil
+}
+
+// marshal encodes the stored value, or the zero value of t if there's none
+func (x *value) marshal(t reflect.Type) (string, error) {
+	v := reflect.New(t).Elem()
+	if s := x.load(); s != nil {
+		v.Set(reflect.ValueOf(s))
+	}
+	return encodeValue(v, "")
+}
+
+// setField sets dst to src, storing the value of an atomic value type rather than
+// copying it
+func setField(dst, src reflect.Value) {
+	if a, ok := dst.Addr().Interface().(atomicValue); ok {
+		if v := addressable(src).Interface().(atomicValue).load(); v != nil {
+			a.store(v)
+		}
+		return
+	}
+	dst.Set(src)
+}
+
+// fieldInterface returns the value of v, loading the value of an atomic value type
+// rather than copying it, which would race with its Store method
+func fieldInterface(v reflect.Value) interface{} {
+	if v.CanAddr() {
+		if a, ok := v.Addr().Interface().(atomicValue); ok {
+			return a.load()
+		}
+	}
+	return v.Interface()
+}
+
+// equalFields reports whether the values of two fields are deeply equal, comparing
+// the loaded values of atomic value types
+func equalFields(a, b reflect.Value) bool {
+	return reflect.DeepEqual(fieldInterface(a), fieldInterface(b))
+}
+
+// copyField returns a copy of the value of a field, loading the value of an atomic
+// value type rather than copying it
+func copyField(v reflect.Value) reflect.Value {
+	c := reflect.New(v.Type()).Elem()
+	setField(c, v)
+	return c
+}
+
+// String is a string field of a watched struct that can be read without holding
+// the watcher's lock
+type String struct {
+	value
+}
+
+// Load returns the current value
+func (x *String) Load() string {
+	s, _ := x.load().(string)
+	return s
+}
+
+// Store replaces the current value
+func (x *String) Store(s string) {
+	x.store(s)
+}
+
+// UnmarshalParameter implements Unmarshaler
+func (x *String) UnmarshalParameter(s string) error {
+	return x.unmarshal(reflect.TypeOf(""), s)
+}
+
+// MarshalParameter implements Marshaler
+func (x *String) MarshalParameter() (string, error) {
+	return x.marshal(reflect.TypeOf(""))
+}
+
+// Int is an int field of a watched struct that can be read without holding the
+// watcher's lock
+type Int struct {
+	value
+}
+
+// Load returns the current value
+func (x *Int) Load() int {
+	i, _ := x.load().(int)
+	return i
+}
+
+// Store replaces the current value
+func (x *Int) Store(i int) {
+	x.store(i)
+}
+
+// UnmarshalParameter implements Unmarshaler
+func (x *Int) UnmarshalParameter(s string) error {
+	return x.unmarshal(reflect.TypeOf(0), s)
+}
+
+// MarshalParameter implements Marshaler
+func (x *Int) MarshalParameter() (string, error) {
+	return x.marshal(reflect.TypeOf(0))
+}
+
+// Int64 is an int64 field of a watched struct that can be read without holding the
+// watcher's lock
+type Int64 struct {
+	value
+}
+
+// Load returns the current value
+func (x *Int64) Load() int64 {
+	i, _ := x.load().(int64)
+	return i
+}
+
+// Store replaces the current value
+func (x *Int64) Store(i int64) {
+	x.store(i)
+}
+
+// UnmarshalParameter implements Unmarshaler
+func (x *Int64) UnmarshalParameter(s string) error {
+	return x.unmarshal(reflect.TypeOf(int64(0)), s)
+}
+
+// MarshalParameter implements Marshaler
+func (x *Int64) MarshalParameter() (string, error) {
+	return x.marshal(reflect.TypeOf(int64(0)))
+}
+
+// Float64 is a float64 field of a watched struct that can be read without holding
+// the watcher's lock
+type Float64 struct {
+	value
+}
+
+// Load returns the current value
+func (x *Float64) Load() float64 {
+	f, _ := x.load().(float64)
+	return f
+}
+
+// Store replaces the current value
+func (x *Float64) Store(f float64) {
+	x.store(f)
+}
+
+// UnmarshalParameter implements Unmarshaler
+func (x *Float64) UnmarshalParameter(s string) error {
+	return x.unmarshal(reflect.TypeOf(float64(0)), s)
+}
+
+// MarshalParameter implements Marshaler
+func (x *Float64) MarshalParameter() (string, error) {
+	return x.marshal(reflect.TypeOf(float64(0)))
+}
+
+// Bool is a bool field of a watched struct that can be read without holding the
+// watcher's lock
+type Bool struct {
+	value
+}
+
+// Load returns the current value
+func (x *Bool) Load() bool {
+	b, _ := x.load().(bool)
+	return b
+}
+
+// Store replaces the current value
+func (x *Bool) Store(b bool) {
+	x.store(b)
+}
+
+// UnmarshalParameter implements Unmarshaler
+func (x *Bool) UnmarshalParameter(s string) error {
+	return x.unmarshal(reflect.TypeOf(false), s)
+}
+
+// MarshalParameter implements Marshaler
+func (x *Bool) MarshalParameter() (string, error) {
+	return x.marshal(reflect.TypeOf(false))
+}
+
+// Duration is a time.Duration field of a watched struct that can be read without
+// holding the watcher's lock
+type Duration struct {
+	value
+}
+
+// Load returns the current value
+func (x *Duration) Load() time.Duration {
+	d, _ := x.load().(time.Duration)
+	return d
+}
+
+// Store replaces the current value
+func (x *Duration) Store(d time.Duration) {
+	x.store(d)
+}
+
+// UnmarshalParameter implements Unmarshaler
+func (x *Duration) UnmarshalParameter(s string) error {
+	return x.unmarshal(durationType, s)
+}
+
+// MarshalParameter implements Marshaler
+func (x *Duration) MarshalParameter() (string, error) {
+	return x.marshal(durationType)
+}
@@ -0,0 +1,141 @@
+package figgy
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ValueConfig struct {
+	Host    String   `ssm:"/app/host"`
+	Port    Int      `ssm:"/app/port"`
+	Size    Int64    `ssm:"/app/size"`
+	Ratio   Float64  `ssm:"/app/ratio"`
+	Debug   Bool     `ssm:"/app/debug"`
+	Timeout Duration `ssm:"/app/timeout"`
+}
+
+func TestValue(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{
+		"/app/host":    "a",
+		"/app/port":    "1",
+		"/app/size":    "2",
+		"/app/ratio":   "0.5",
+		"/app/debug":   "true",
+		"/app/timeout": "1s",
+	})
+	var cfg ValueConfig
+	assert.NoError(t, Load(m, &cfg))
+	assert.Equal(t, "a", cfg.Host.Load())
+	assert.Equal(t, 1, cfg.Port.Load())
+	assert.Equal(t, int64(2), cfg.Size.Load())
+	assert.Equal(t, 0.5, cfg.Ratio.Load())
+	assert.True(t, cfg.Debug.Load())
+	assert.Equal(t, time.Second, cfg.Timeout.Load())
+
+	setParameter(m, "/app/port", "x")
+	assert.Error(t, Load(m, &cfg))
+
+	s := NewMockSSMClient()
+	cfg.Timeout.Store(time.Minute)
+	assert.NoError(t, Store(s, &cfg))
+	assert.Equal(t, "a", aws.StringValue(s.Data["/app/host"].Parameter.Value))
+	assert.Equal(t, "1m0s", aws.StringValue(s.Data["/app/timeout"].Parameter.Value))
+
+	var zero ValueConfig
+	assert.Equal(t, "", zero.Host.Load())
+	p, err := zero.Port.MarshalParameter()
+	assert.NoError(t, err)
+	assert.Equal(t, "0", p)
+}
+
+func TestWatchValue(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{
+		"/app/host":    "a",
+		"/app/port":    "1",
+		"/app/size":    "2",
+		"/app/ratio":   "0.5",
+		"/app/debug":   "true",
+		"/app/timeout": "1s",
+	})
+	var cfg ValueConfig
+	w, err := Watch(m, &cfg, nil, Poll(time.Hour), WithSnapshots(2))
+	assert.NoError(t, err)
+	defer w.Stop()
+
+	// values are read without the watcher's lock while it reloads
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				cfg.Host.Load()
+				cfg.Port.Load()
+			}
+		}
+	}()
+	for _, host := range []string{"b", "c", "d"} {
+		setParameter(m, "/app/host", host)
+		assert.NoError(t, w.Reload(context.Background()))
+	}
+	close(done)
+	wg.Wait()
+	assert.Equal(t, "d", cfg.Host.Load())
+	assert.Equal(t, 1, cfg.Port.Load())
+
+	assert.NoError(t, w.RollbackTo(1))
+	assert.Equal(t, "c", cfg.Host.Load())
+}
+
+func TestWatchValueStore(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{
+		"/app/host":    "a",
+		"/app/port":    "1",
+		"/app/size":    "2",
+		"/app/ratio":   "0.5",
+		"/app/debug":   "true",
+		"/app/timeout": "1s",
+	})
+	var cfg ValueConfig
+	w, err := Watch(m, &cfg, nil, Poll(time.Hour), WithSnapshots(2))
+	assert.NoError(t, err)
+	defer w.Stop()
+
+	// values stored without the watcher's lock are compared and copied atomically
+	// while it reloads, which the race detector checks
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+				cfg.Host.Store("local")
+				cfg.Port.Store(i)
+			}
+		}
+	}()
+	for _, host := range []string{"b", "c", "d"} {
+		setParameter(m, "/app/host", host)
+		setParameter(m, "/app/port", host)
+		assert.Error(t, w.Reload(context.Background()))
+		setParameter(m, "/app/port", "2")
+		assert.NoError(t, w.Reload(context.Background()))
+	}
+	assert.NoError(t, w.RollbackTo(1))
+	close(done)
+	wg.Wait()
+}
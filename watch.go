@@ -0,0 +1,821 @@
+package figgy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// notifyRetryDelay is how long a watcher waits after its notifier fails before trying again
+const notifyRetryDelay = 5 * time.Second
+
+// errWatcherStopped is returned when reloading a watcher that was stopped
+var errWatcherStopped = errors.New("figgy: watcher stopped")
+
+// maxDescribeParameters is the maximum number of values in a DescribeParameters filter,
+// and of parameters in its results
+const maxDescribeParameters = 50
+
+// Notifier tells a Watcher when parameters may have changed
+type Notifier interface {
+	// Notify blocks until parameters change, or ctx is done, and returns the names of
+	// the parameters that changed.  A nil slice means any parameter may have changed.
+	Notify(ctx context.Context) ([]string, error)
+}
+
+// pollNotifier reports a possible change of every parameter at an interval, or of
+// the parameters of each refresh class at the interval of the class
+type pollNotifier struct {
+	freq    time.Duration
+	jitter  float64
+	mu      sync.Mutex
+	rnd     *rand.Rand
+	classes map[string]time.Duration
+	// keys of the watched parameters by refresh class, and when each class is next
+	// polled, guarded by watchMu as a watcher may replace them while it's notified
+	watchMu sync.Mutex
+	keys    map[string][]string
+	next    map[string]time.Time
+}
+
+// Poll returns a Notifier that has a watcher reload its parameters every freq
+func Poll(freq time.Duration) Notifier {
+	return &pollNotifier{freq: freq}
+}
+
+// PollWithJitter returns a Notifier like Poll, but with each interval chosen at random
+// within jitter, a fraction between 0 and 1, of freq.  Jitter keeps the many processes
+// watching the same parameters from polling together and being throttled.
+func PollWithJitter(freq time.Duration, jitter float64) Notifier {
+	if jitter < 0 {
+		jitter = 0
+	}
+	if jitter > 1 {
+		jitter = 1
+	}
+	return &pollNotifier{
+		freq:   freq,
+		jitter: jitter,
+		rnd:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// PollClasses returns a Notifier that polls the parameters of fields tagged with a
+// refresh class, as in `ssm:"/myapp/flags,refresh=fast"`, at the interval of the class
+// in classes.  Other fields are polled every freq.  The notifier must not be shared
+// between watchers.
+func PollClasses(freq time.Duration, classes map[string]time.Duration) Notifier {
+	return &pollNotifier{freq: freq, classes: classes}
+}
+
+// watch sets the keys of the watched parameters by refresh class
+func (n *pollNotifier) watch(keys map[string][]string) {
+	if n.classes == nil {
+		return
+	}
+	next := make(map[string]time.Time, len(keys))
+	now := time.Now()
+	for class := range keys {
+		next[class] = now.Add(n.interval(class))
+	}
+	n.watchMu.Lock()
+	n.keys, n.next = keys, next
+	n.watchMu.Unlock()
+}
+
+// interval returns the time to wait before the next poll of a refresh class
+func (n *pollNotifier) interval(class string) time.Duration {
+	freq := n.freq
+	if d, ok := n.classes[class]; ok {
+		freq = d
+	}
+	if n.jitter == 0 {
+		return freq
+	}
+	n.mu.Lock()
+	f := n.rnd.Float64()
+	n.mu.Unlock()
+	return freq + time.Duration(float64(freq)*n.jitter*(2*f-1))
+}
+
+func (n *pollNotifier) Notify(ctx context.Context) ([]string, error) {
+	n.watchMu.Lock()
+	if n.next == nil {
+		n.watchMu.Unlock()
+		return nil, n.wait(ctx, time.Now().Add(n.interval("")))
+	}
+	var first time.Time
+	for _, t := range n.next {
+		if first.IsZero() || t.Before(first) {
+			first = t
+		}
+	}
+	n.watchMu.Unlock()
+	if err := n.wait(ctx, first); err != nil {
+		return nil, err
+	}
+	n.watchMu.Lock()
+	defer n.watchMu.Unlock()
+	names := []string{}
+	now := time.Now()
+	for class, t := range n.next {
+		if t.After(now) {
+			continue
+		}
+		names = append(names, n.keys[class]...)
+		n.next[class] = now.Add(n.interval(class))
+	}
+	return names, nil
+}
+
+// wait until t or ctx is done
+func (n *pollNotifier) wait(ctx context.Context, t time.Time) error {
+	timer := time.NewTimer(time.Until(t))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Watcher keeps a struct loaded with the current values of its parameters.  Values
+// are replaced while holding the watcher's lock, so readers of a watched struct
+// should hold RLock.
+type Watcher struct {
+	mu   sync.RWMutex
+	c    ssmiface.SSMAPI
+	v    reflect.Value
+	data interface{}
+	// current is the template data the keys were last expanded with, which differs
+	// from data when data is a DataFunc
+	current interface{}
+	o       *options
+	n       Notifier
+	// keys maps the keys of the watched parameters to whether they're chunked
+	keys map[string]bool
+	// polls is true when n reports parameters that may have changed, rather than
+	// parameters that did
+	polls bool
+	// remote is true when parameters are loaded from other regions, with assumed
+	// roles, by ARN or from old keys, whose versions aren't described
+	remote bool
+	// policyKeys are the keys of the watched parameters whose policies are described
+	// to reload them when they expire
+	policyKeys []string
+	// objects are the keys of the watched fields with an s3 tag
+	objects map[string]bool
+	// versions of the watched parameters when they were last described, and ETags
+	// of the watched objects when they were last checked
+	versions    map[string]int64
+	etags       map[string]string
+	err         error
+	lastRefresh time.Time
+	changes     chan struct{}
+	reloads     chan chan error
+	snapshots   []snapshot
+	// subscriptions are the OnChange functions by the index of their field in walk order
+	subscriptions map[int][]reflect.Value
+	// rotations are the OnRotate functions
+	rotations []func(key string, old, new interface{})
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// Watch loads v as LoadWithParameters does and then reloads it each time n reports
+// that one of its parameters changed, until Stop is called.  Only fields whose
+// values changed are assigned, and fields without an ssm tag or with the static tag
+// option are left alone, with changes to static fields logged by WithLogger.  A
+// reload that fails, including failing the validation of WithValidator, leaves v as
+// it was and is reported by LastError.
+//
+// When n can't tell which parameters changed, as with Poll, the versions of the
+// parameters are described first and v is only reloaded if a version changed since
+// it was loaded.
+func Watch(c ssmiface.SSMAPI, v interface{}, data interface{}, n Notifier, opts ...Option) (*Watcher, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	current := resolveData(ctx, data)
+	loaded := &versionRecorder{SSMAPI: c, versions: make(map[string]int64)}
+	if err := LoadWithParameters(loaded, v, current, opts...); err != nil {
+		cancel()
+		return nil, err
+	}
+	rv := reflect.ValueOf(v)
+	f, err := inspect(rv.Elem(), rv.Elem().Type(), current, "")
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	w := &Watcher{
+		c:           c,
+		v:           rv,
+		data:        data,
+		current:     current,
+		o:           newOptions(opts),
+		n:           n,
+		versions:    make(map[string]int64),
+		etags:       make(map[string]string),
+		lastRefresh: time.Now(),
+		changes:     make(chan struct{}, 1),
+		reloads:     make(chan chan error),
+		cancel:      cancel,
+		done:        make(chan struct{}),
+	}
+	_, w.polls = n.(*pollNotifier)
+	w.setKeys(f)
+	w.setLoadedVersions(loaded.versions)
+	if w.o.snapshots > 0 {
+		live, err := walk(rv.Elem(), current)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		w.snapshot(live, nil)
+	}
+	expiring, expired := w.nextExpiration()
+	go w.run(ctx, expiring, expired)
+	return w, nil
+}
+
+// setKeys sets the keys of the watched parameters from the fields of the watched struct
+func (w *Watcher) setKeys(f []*field) {
+	w.o.normalizeKeys(f)
+	w.keys = make(map[string]bool, len(f))
+	w.objects = make(map[string]bool)
+	w.remote = false
+	classes := make(map[string][]string)
+	for _, x := range f {
+		w.keys[x.key] = x.chunks
+		if x.object {
+			w.objects[x.key] = true
+		}
+		classes[x.refresh] = append(classes[x.refresh], x.key)
+		if w.o.remote(x) != (remote{}) || isARN(x.key) || x.old != "" || x.tree || x.glob {
+			w.remote = true
+		}
+	}
+	if w.o.expirationRefresh {
+		w.policyKeys = w.o.policyKeys(f)
+	}
+	if p, ok := w.n.(*pollNotifier); ok {
+		p.watch(classes)
+	}
+}
+
+// setLoadedVersions starts the versions of the watched parameters at those the struct
+// was loaded with, so the first check for changes doesn't reload unchanged parameters
+func (w *Watcher) setLoadedVersions(loaded map[string]int64) {
+	for name, version := range loaded {
+		for key, chunks := range w.keys {
+			if !w.objects[key] && isParameterOf(name, key, chunks) {
+				w.versions[name] = version
+				break
+			}
+		}
+	}
+}
+
+// versionRecorder records the versions of the parameters returned by its client
+type versionRecorder struct {
+	ssmiface.SSMAPI
+	mu       sync.Mutex
+	versions map[string]int64
+}
+
+func (c *versionRecorder) record(params []*ssm.Parameter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, p := range params {
+		c.versions[aws.StringValue(p.Name)] = aws.Int64Value(p.Version)
+	}
+}
+
+func (c *versionRecorder) GetParameters(in *ssm.GetParametersInput) (*ssm.GetParametersOutput, error) {
+	out, err := c.SSMAPI.GetParameters(in)
+	if out != nil {
+		c.record(out.Parameters)
+	}
+	return out, err
+}
+
+func (c *versionRecorder) GetParametersWithContext(ctx aws.Context, in *ssm.GetParametersInput, opts ...request.Option) (*ssm.GetParametersOutput, error) {
+	out, err := c.SSMAPI.GetParametersWithContext(ctx, in, opts...)
+	if out != nil {
+		c.record(out.Parameters)
+	}
+	return out, err
+}
+
+func (c *versionRecorder) GetParametersByPath(in *ssm.GetParametersByPathInput) (*ssm.GetParametersByPathOutput, error) {
+	out, err := c.SSMAPI.GetParametersByPath(in)
+	if out != nil {
+		c.record(out.Parameters)
+	}
+	return out, err
+}
+
+func (c *versionRecorder) GetParametersByPathWithContext(ctx aws.Context, in *ssm.GetParametersByPathInput, opts ...request.Option) (*ssm.GetParametersByPathOutput, error) {
+	out, err := c.SSMAPI.GetParametersByPathWithContext(ctx, in, opts...)
+	if out != nil {
+		c.record(out.Parameters)
+	}
+	return out, err
+}
+
+// rekey expands the keys of the watched struct again when its template data was set
+// with SetData or is a DataFunc, reporting whether they changed.  The new keys are
+// watched from then on.
+func (w *Watcher) rekey(ctx context.Context) bool {
+	w.mu.RLock()
+	data := w.data
+	w.mu.RUnlock()
+	current := resolveData(ctx, data)
+	if reflect.DeepEqual(current, w.current) {
+		return false
+	}
+	f, err := inspect(w.v.Elem(), w.v.Elem().Type(), current, "")
+	if err != nil {
+		w.o.logger.Debug("figgy: failed to expand keys", "error", err)
+		return false
+	}
+	w.mu.Lock()
+	w.current = current
+	w.mu.Unlock()
+	w.o.normalizeKeys(f)
+	keys := make(map[string]bool, len(f))
+	for _, x := range f {
+		keys[x.key] = x.chunks
+	}
+	if reflect.DeepEqual(keys, w.keys) {
+		return false
+	}
+	w.o.logger.Debug("figgy: keys changed", "keys", sortedKeys(keys))
+	w.setKeys(f)
+	// the versions described were of the parameters for the old keys
+	w.mu.Lock()
+	w.versions = make(map[string]int64)
+	w.mu.Unlock()
+	w.etags = make(map[string]string)
+	return true
+}
+
+// WithDebounce has a watcher wait for d after being notified of a change, combining
+// the changes notified in that time into a single reload.  Updates of many parameters
+// in quick succession, such as by a Terraform apply, then cause one reload and one
+// notification from Changes.
+func WithDebounce(d time.Duration) Option {
+	return func(o *options) {
+		o.debounce = d
+	}
+}
+
+// RLock locks the watched struct for reading
+func (w *Watcher) RLock() {
+	w.mu.RLock()
+}
+
+// RUnlock undoes a single RLock call
+func (w *Watcher) RUnlock() {
+	w.mu.RUnlock()
+}
+
+// Changes returns a channel that receives a value after a reload changes the struct.
+// Changes made while the previous one hasn't been received are coalesced.
+func (w *Watcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+// LastError returns the error from the last check for changes, or from the notifier
+// if it failed since
+func (w *Watcher) LastError() error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.err
+}
+
+// LastRefresh returns when the struct was last known to be current: after it was
+// loaded, reloaded, or found to have no changed parameter versions
+func (w *Watcher) LastRefresh() time.Time {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.lastRefresh
+}
+
+// CurrentVersions returns the versions of the watched parameters, by name, as loaded
+// by Watch or last described when polling.  Notifiers that name the changed parameters, such as
+// SQSNotifier, don't have versions described.
+func (w *Watcher) CurrentVersions() map[string]int64 {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	v := make(map[string]int64, len(w.versions))
+	for name, version := range w.versions {
+		v[name] = version
+	}
+	return v
+}
+
+// Reload the struct immediately, without waiting to be notified of a change, for
+// example when handling SIGHUP.  It returns the error from reloading, or ctx's error
+// if ctx is done first.
+func (w *Watcher) Reload(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	reloaded := make(chan error, 1)
+	select {
+	case w.reloads <- reloaded:
+	case <-w.done:
+		return errWatcherStopped
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-reloaded:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SetData replaces the template data the keys of the watched struct are expanded
+// with, such as when a service switches environments at runtime.  The next refresh
+// reloads every field from the new keys, and the fields of SetData's keys are watched
+// from then on.  Call Reload to repoint the struct immediately.
+func (w *Watcher) SetData(data interface{}) {
+	w.mu.Lock()
+	w.data = data
+	w.mu.Unlock()
+}
+
+// Stop watching for changes, waiting for a reload in progress to finish.  The
+// snapshots kept with WithSnapshots are released.
+func (w *Watcher) Stop() {
+	w.cancel()
+	<-w.done
+	w.mu.Lock()
+	w.snapshots = nil
+	w.mu.Unlock()
+}
+
+func (w *Watcher) run(ctx context.Context, expiring map[string]bool, expired <-chan time.Time) {
+	defer close(w.done)
+	notifications := make(chan []string)
+	notifying := make(chan struct{})
+	defer func() { <-notifying }()
+	go w.notify(ctx, notifications, notifying)
+	for {
+		var names []string
+		select {
+		case names = <-notifications:
+		case <-expired:
+			w.o.logger.Debug("figgy: reloading expiring parameters", "keys", sortedKeys(expiring))
+			w.reload(expiring)
+			expiring, expired = w.nextExpiration()
+			continue
+		case reloaded := <-w.reloads:
+			w.rekey(ctx)
+			reloaded <- w.reload(w.keys)
+			expiring, expired = w.nextExpiration()
+			continue
+		case <-ctx.Done():
+			return
+		}
+		if w.o.debounce > 0 {
+			names = w.debounce(ctx, names, notifications)
+			if ctx.Err() != nil {
+				return
+			}
+		}
+		if w.rekey(ctx) {
+			// every field is loaded from its new key
+			w.reload(w.keys)
+			expiring, expired = w.nextExpiration()
+			continue
+		}
+		keys := w.watched(names)
+		if len(keys) == 0 {
+			continue
+		}
+		if (names == nil || w.polls) && !w.versionsChanged(keys) {
+			w.setStatus(nil)
+			continue
+		}
+		w.reload(keys)
+		// a rotated parameter may expire at another time
+		expiring, expired = w.nextExpiration()
+	}
+}
+
+// reload the fields for keys
+func (w *Watcher) reload(keys map[string]bool) error {
+	err := w.refresh(keys)
+	if err != nil {
+		// reload these parameters on the next poll, whatever their versions
+		w.forgetVersions(keys)
+	}
+	return err
+}
+
+// notify sends the names reported by the notifier until ctx is done, then closes done
+func (w *Watcher) notify(ctx context.Context, notifications chan<- []string, done chan<- struct{}) {
+	defer close(done)
+	for {
+		names, err := w.n.Notify(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			w.setStatus(err)
+			select {
+			case <-time.After(notifyRetryDelay):
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+		select {
+		case notifications <- names:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// debounce combines names with the names of further notifications received within
+// the debounce period
+func (w *Watcher) debounce(ctx context.Context, names []string, notifications <-chan []string) []string {
+	t := time.NewTimer(w.o.debounce)
+	defer t.Stop()
+	all := names == nil
+	for {
+		select {
+		case more := <-notifications:
+			all = all || more == nil
+			names = append(names, more...)
+		case <-t.C:
+			if all {
+				return nil
+			}
+			return names
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// watched returns the keys of the watched parameters with any of the given names, or
+// all of them when names is nil, mapped to whether they're chunked
+func (w *Watcher) watched(names []string) map[string]bool {
+	if names == nil {
+		return w.keys
+	}
+	keys := make(map[string]bool)
+	for _, name := range names {
+		for key, chunks := range w.keys {
+			if isParameterOf(name, key, chunks) {
+				keys[key] = chunks
+			}
+		}
+	}
+	return keys
+}
+
+// isParameterOf reports whether the named parameter is loaded for key
+func isParameterOf(name, key string, chunks bool) bool {
+	return name == key || chunks && strings.HasPrefix(name, strings.TrimSuffix(key, "/")+"/part-")
+}
+
+// versionsChanged reports whether the versions of the parameters, or the ETags of the
+// objects, for keys changed since they were last checked.  A change is reported when
+// the versions can't be described, for example without permission for ssm:DescribeParameters, and when
+// references are enabled or parameters are loaded from other regions or accounts or
+// by ARN, since those parameters aren't described.
+func (w *Watcher) versionsChanged(keys map[string]bool) bool {
+	if w.o.references || w.remote {
+		return true
+	}
+	params := make(map[string]bool, len(keys))
+	var objects []string
+	for key, chunks := range keys {
+		if w.objects[key] {
+			objects = append(objects, key)
+		} else {
+			params[key] = chunks
+		}
+	}
+	changed := w.objectsChanged(objects)
+	v, err := w.describeVersions(params)
+	if err != nil {
+		w.o.logger.Debug("figgy: failed to describe parameters", "error", err)
+		return true
+	}
+	old := w.forgetVersions(params)
+	w.mu.Lock()
+	for name, version := range v {
+		w.versions[name] = version
+	}
+	w.mu.Unlock()
+	return changed || !reflect.DeepEqual(v, old)
+}
+
+// forgetVersions removes and returns the versions of the parameters for keys, and
+// removes the ETags of the objects for keys
+func (w *Watcher) forgetVersions(keys map[string]bool) map[string]int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for key := range keys {
+		delete(w.etags, key)
+	}
+	old := make(map[string]int64)
+	for name, version := range w.versions {
+		for key, chunks := range keys {
+			if isParameterOf(name, key, chunks) {
+				old[name] = version
+				delete(w.versions, name)
+				break
+			}
+		}
+	}
+	return old
+}
+
+// describeVersions returns the versions of the parameters for keys, including the
+// parts of chunked parameters, by name
+func (w *Watcher) describeVersions(keys map[string]bool) (map[string]int64, error) {
+	var names []*string
+	var chunked []string
+	for key, chunks := range keys {
+		if chunks {
+			chunked = append(chunked, key)
+		} else {
+			names = append(names, aws.String(key))
+		}
+	}
+	v := make(map[string]int64, len(keys))
+	for i := 0; i < len(names); i += maxDescribeParameters {
+		j := i + maxDescribeParameters
+		if j > len(names) {
+			j = len(names)
+		}
+		if err := w.describe("Equals", names[i:j], v); err != nil {
+			return nil, err
+		}
+	}
+	for _, key := range chunked {
+		prefix := strings.TrimSuffix(key, "/") + "/part-"
+		if err := w.describe("BeginsWith", []*string{aws.String(prefix)}, v); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// describe adds the versions of the parameters with names matching values to v
+func (w *Watcher) describe(option string, values []*string, v map[string]int64) error {
+	in := &ssm.DescribeParametersInput{
+		MaxResults: aws.Int64(maxDescribeParameters),
+		ParameterFilters: []*ssm.ParameterStringFilter{{
+			Key:    aws.String(ssm.ParametersFilterKeyName),
+			Option: aws.String(option),
+			Values: values,
+		}},
+	}
+	for {
+		res, err := w.o.guard(w.o.pace(w.o.limit(w.c))).DescribeParameters(in)
+		if err != nil {
+			return err
+		}
+		for _, p := range res.Parameters {
+			v[aws.StringValue(p.Name)] = aws.Int64Value(p.Version)
+		}
+		if aws.StringValue(res.NextToken) == "" {
+			return nil
+		}
+		in.NextToken = res.NextToken
+	}
+}
+
+// refresh loads the fields for keys into a new value and assigns those that changed
+func (w *Watcher) refresh(keys map[string]bool) error {
+	span := w.o.tracer.Start(nil, "figgy.Watch.refresh")
+	fresh := reflect.New(w.v.Elem().Type())
+	f, err := walk(fresh.Elem(), w.current)
+	w.o.normalizeKeys(f)
+	var selected []*field
+	for _, x := range f {
+		if _, ok := keys[x.key]; ok {
+			selected = append(selected, x)
+		}
+	}
+	if err == nil {
+		err = loadRemotes(w.c, span, selected, w.o)
+	}
+	var changes []change
+	if err == nil {
+		changes, err = w.assign(f, keys)
+	}
+	changed := len(changes) > 0
+	span.SetAttribute("figgy.fields", len(selected))
+	span.SetAttribute("figgy.changed", changed)
+	span.End(err)
+	w.o.metrics.WatchRefreshed(changed, err)
+	w.setStatus(err)
+	if changed {
+		w.notifyChange()
+		w.publish(changes)
+	}
+	return err
+}
+
+// notifyChange sends to the Changes channel, unless a change is already waiting
+func (w *Watcher) notifyChange() {
+	select {
+	case w.changes <- struct{}{}:
+	default:
+	}
+}
+
+// change is a field of the watched struct that changed, by its index in walk order
+type change struct {
+	index    int
+	field    *field
+	old, new reflect.Value
+}
+
+// assign the fields of the watched struct for keys that differ from the freshly
+// loaded fields.  Fresh fields are in walk order, like those of the watched struct.
+// When the struct with the new values fails validation, the old values are restored
+// before the lock is released.
+func (w *Watcher) assign(fresh []*field, keys map[string]bool) ([]change, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	live, err := walk(w.v.Elem(), w.current)
+	if err != nil {
+		return nil, err
+	}
+	w.o.normalizeKeys(live)
+	var changes []change
+	for i, x := range live {
+		if _, ok := keys[x.key]; !ok {
+			continue
+		}
+		nv := fresh[i].value
+		if equalFields(x.value, nv) {
+			continue
+		}
+		if x.static {
+			w.o.logger.Debug("figgy: ignoring change to static field", "field", x.field.Name, "key", x.key)
+			continue
+		}
+		ov := copyField(x.value)
+		setField(x.value, nv)
+		changes = append(changes, change{index: i, field: x, old: ov, new: nv})
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	if err := w.o.validate(w.v.Interface()); err != nil {
+		for _, c := range changes {
+			setField(c.field.value, c.old)
+		}
+		w.o.logger.Debug("figgy: reload failed validation", "error", err)
+		return nil, err
+	}
+	names := make([]string, len(changes))
+	for i, c := range changes {
+		w.o.logger.Debug("figgy: field changed", "field", c.field.field.Name, "key", c.field.key,
+			"old", logValue(c.field, c.old), "new", logValue(c.field, c.new))
+		names[i] = c.field.field.Name
+	}
+	w.snapshot(live, names)
+	return changes, nil
+}
+
+// setStatus records the outcome of checking for changes
+func (w *Watcher) setStatus(err error) {
+	w.mu.Lock()
+	w.err = err
+	if err == nil {
+		w.lastRefresh = time.Now()
+	}
+	w.mu.Unlock()
+}
+
+// logValue formats a field's value for logging, redacting decrypted values
+func logValue(x *field, v reflect.Value) string {
+	if x.decrypt {
+		return redacted
+	}
+	return fmt.Sprintf("%v", fieldInterface(v))
+}
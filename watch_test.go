@@ -0,0 +1,452 @@
+package figgy
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/stretchr/testify/assert"
+)
+
+// chanNotifier delivers the names sent on it
+type chanNotifier chan []string
+
+func (n chanNotifier) Notify(ctx context.Context) ([]string, error) {
+	select {
+	case names := <-n:
+		return names, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// settle waits for the watcher to handle the notifications already sent, by sending
+// two that don't reload: the second is only taken once the watcher has the first
+func (n chanNotifier) settle() {
+	n <- []string{}
+	n <- []string{}
+}
+
+// setParameter changes a parameter of a MockSSMClient, incrementing its version
+func setParameter(m *MockSSMClient, key, value string) {
+	m.PutParameter(&ssm.PutParameterInput{Name: aws.String(key), Value: aws.String(value), Overwrite: aws.Bool(true)})
+}
+
+type WatchConfig struct {
+	Host     string `ssm:"/app/host"`
+	Password string `ssm:"/app/password,decrypt"`
+	Local    string
+	Nested   *struct {
+		Port int `ssm:"/app/port"`
+	}
+}
+
+// waitChange waits for the watcher to report a change
+func waitChange(t *testing.T, w *Watcher) bool {
+	select {
+	case <-w.Changes():
+		return true
+	case <-time.After(time.Second):
+		return false
+	}
+}
+
+func TestWatch(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/host": "a", "/app/password": "p", "/app/port": "1"})
+	n := make(chanNotifier)
+	l := &recordingLogger{}
+	var cfg WatchConfig
+	w, err := Watch(m, &cfg, nil, n, WithLogger(l))
+	assert.NoError(t, err)
+	defer w.Stop()
+	assert.Equal(t, "a", cfg.Host)
+	assert.Equal(t, 1, cfg.Nested.Port)
+	cfg.Local = "local"
+	nested := cfg.Nested
+
+	setParameter(m, "/app/host", "b")
+	setParameter(m, "/app/password", "q")
+	setParameter(m, "/app/port", "2")
+	n <- []string{"/app/host", "/app/password"}
+	assert.True(t, waitChange(t, w))
+	w.RLock()
+	assert.Equal(t, "b", cfg.Host)
+	assert.Equal(t, "q", cfg.Password)
+	assert.Equal(t, "local", cfg.Local)
+	assert.True(t, nested == cfg.Nested)
+	// only the named parameters are reloaded
+	assert.Equal(t, 1, cfg.Nested.Port)
+	w.RUnlock()
+	assert.NoError(t, w.LastError())
+	assert.Contains(t, l.args, []interface{}{"field", "Host", "key", "/app/host", "old", "a", "new", "b"})
+	assert.Contains(t, l.args, []interface{}{"field", "Password", "key", "/app/password", "old", redacted, "new", redacted})
+
+	// names of other parameters don't reload, and reloads without changes don't notify
+	setParameter(m, "/app/host", "c")
+	n <- []string{"/other"}
+	n.settle()
+	setParameter(m, "/app/host", "b")
+	setParameter(m, "/app/port", "1")
+	n <- nil
+	n.settle()
+	select {
+	case <-w.Changes():
+		t.Error("unexpected change")
+	default:
+	}
+
+	// a failed reload leaves the struct as it was
+	delete(m.Data, "/app/port")
+	n <- nil
+	n.settle()
+	assert.Error(t, w.LastError())
+	w.RLock()
+	assert.Equal(t, 1, cfg.Nested.Port)
+	w.RUnlock()
+}
+
+func TestWatchChunks(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/doc/part-000": "a"})
+	n := make(chanNotifier)
+	var cfg struct {
+		Doc string `ssm:"/app/doc,chunks"`
+	}
+	w, err := Watch(m, &cfg, nil, n)
+	assert.NoError(t, err)
+	defer w.Stop()
+	setParameter(m, "/app/doc/part-001", "b")
+	n <- []string{"/app/doc/part-001"}
+	assert.True(t, waitChange(t, w))
+	w.RLock()
+	assert.Equal(t, "ab", cfg.Doc)
+	w.RUnlock()
+}
+
+func TestWatchLoadError(t *testing.T) {
+	var cfg WatchConfig
+	_, err := Watch(NewMockSSMClient(), &cfg, nil, Poll(time.Hour))
+	assert.Error(t, err)
+}
+
+func TestWatchRefreshMetrics(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/host": "a", "/app/password": "p", "/app/port": "1"})
+	n := make(chanNotifier)
+	rm := &refreshMetrics{refreshed: make(chan bool)}
+	var cfg WatchConfig
+	w, err := Watch(m, &cfg, nil, n, WithMetrics(rm))
+	assert.NoError(t, err)
+	defer w.Stop()
+	// a new version with the same value reloads without changing a field
+	setParameter(m, "/app/host", "a")
+	n <- nil
+	assert.False(t, <-rm.refreshed)
+	setParameter(m, "/app/host", "b")
+	n <- nil
+	assert.True(t, <-rm.refreshed)
+}
+
+type refreshMetrics struct {
+	NopMetrics
+	refreshed chan bool
+}
+
+func (m *refreshMetrics) WatchRefreshed(changed bool, err error) {
+	m.refreshed <- changed
+}
+
+func TestPoll(t *testing.T) {
+	names, err := Poll(time.Millisecond).Notify(context.Background())
+	assert.NoError(t, err)
+	assert.Nil(t, names)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Poll(time.Hour).Notify(ctx)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+// syncSSMClient allows a MockSSMClient to be changed while a watcher uses it
+type syncSSMClient struct {
+	sync.Mutex
+	*MockSSMClient
+}
+
+func (c *syncSSMClient) GetParameters(i *ssm.GetParametersInput) (*ssm.GetParametersOutput, error) {
+	c.Lock()
+	defer c.Unlock()
+	return c.MockSSMClient.GetParameters(i)
+}
+
+func (c *syncSSMClient) DescribeParameters(i *ssm.DescribeParametersInput) (*ssm.DescribeParametersOutput, error) {
+	c.Lock()
+	defer c.Unlock()
+	return c.MockSSMClient.DescribeParameters(i)
+}
+
+type countingRefreshMetrics struct {
+	NopMetrics
+	refreshes int32
+}
+
+func (m *countingRefreshMetrics) WatchRefreshed(changed bool, err error) {
+	atomic.AddInt32(&m.refreshes, 1)
+}
+
+func TestWatchVersions(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/host": "a", "/app/password": "p", "/app/port": "1", "/app/doc/part-000": "d"})
+	n := make(chanNotifier)
+	rm := &countingRefreshMetrics{}
+	var cfg struct {
+		WatchConfig
+		Doc string `ssm:"/app/doc,chunks"`
+	}
+	c := &countingSSMClient{MockSSMClient: m}
+	w, err := Watch(c, &cfg, nil, n, WithMetrics(rm))
+	assert.NoError(t, err)
+	defer w.Stop()
+	refreshes := func() int32 {
+		n.settle()
+		return atomic.LoadInt32(&rm.refreshes)
+	}
+
+	// the versions the struct was loaded with are known, so the first poll doesn't
+	// request the unchanged parameters
+	loads := c.calls
+	n <- nil
+	assert.Equal(t, int32(0), refreshes())
+	assert.Equal(t, loads, c.calls)
+	assert.Equal(t, map[string]int64{"/app/host": 0, "/app/password": 0, "/app/port": 0, "/app/doc/part-000": 0}, w.CurrentVersions())
+
+	setParameter(m, "/app/port", "2")
+	n <- nil
+	assert.Equal(t, int32(1), refreshes())
+	w.RLock()
+	assert.Equal(t, 2, cfg.Nested.Port)
+	w.RUnlock()
+
+	setParameter(m, "/app/doc/part-001", "e")
+	n <- nil
+	assert.Equal(t, int32(2), refreshes())
+	w.RLock()
+	assert.Equal(t, "de", cfg.Doc)
+	w.RUnlock()
+
+	// named changes reload without describing versions
+	n <- []string{"/app/host"}
+	assert.Equal(t, int32(3), refreshes())
+}
+
+func TestPollWithJitter(t *testing.T) {
+	n := PollWithJitter(time.Second, 0.25).(*pollNotifier)
+	seen := make(map[time.Duration]bool)
+	for i := 0; i < 100; i++ {
+		d := n.interval("")
+		assert.True(t, d >= 750*time.Millisecond && d <= 1250*time.Millisecond, "interval %s out of range", d)
+		seen[d] = true
+	}
+	assert.True(t, len(seen) > 1)
+
+	assert.Equal(t, time.Second, PollWithJitter(time.Second, -1).(*pollNotifier).interval(""))
+	n = PollWithJitter(time.Second, 2).(*pollNotifier)
+	assert.Equal(t, 1.0, n.jitter)
+	names, err := PollWithJitter(time.Millisecond, 0.5).Notify(context.Background())
+	assert.NoError(t, err)
+	assert.Nil(t, names)
+}
+
+func TestPollClasses(t *testing.T) {
+	n := PollClasses(time.Hour, map[string]time.Duration{"fast": time.Millisecond}).(*pollNotifier)
+	n.watch(map[string][]string{"": {"/app/host"}, "fast": {"/app/flag", "/app/other"}})
+	names, err := n.Notify(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/app/flag", "/app/other"}, names)
+
+	m := NewMockSSMClientWith(map[string]string{"/app/host": "a", "/app/flag": "false"})
+	var cfg struct {
+		Host string `ssm:"/app/host"`
+		Flag bool   `ssm:"/app/flag,refresh=fast"`
+	}
+	c := &syncSSMClient{MockSSMClient: m}
+	w, err := Watch(c, &cfg, nil, PollClasses(time.Hour, map[string]time.Duration{"fast": 10 * time.Millisecond}))
+	assert.NoError(t, err)
+	defer w.Stop()
+	c.Lock()
+	setParameter(m, "/app/host", "b")
+	setParameter(m, "/app/flag", "true")
+	c.Unlock()
+	assert.True(t, waitChange(t, w))
+	w.RLock()
+	assert.True(t, cfg.Flag)
+	assert.Equal(t, "a", cfg.Host)
+	w.RUnlock()
+}
+
+func TestWithDebounce(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/host": "a", "/app/password": "p", "/app/port": "1"})
+	n := make(chanNotifier)
+	rm := &refreshMetrics{refreshed: make(chan bool)}
+	var cfg WatchConfig
+	w, err := Watch(m, &cfg, nil, n, WithMetrics(rm), WithDebounce(50*time.Millisecond))
+	assert.NoError(t, err)
+	defer w.Stop()
+	setParameter(m, "/app/host", "b")
+	setParameter(m, "/app/port", "2")
+	n <- []string{"/app/host"}
+	n <- []string{"/other"}
+	n <- []string{"/app/port"}
+	assert.True(t, <-rm.refreshed)
+	select {
+	case <-rm.refreshed:
+		t.Error("unexpected refresh")
+	case <-time.After(100 * time.Millisecond):
+	}
+	w.RLock()
+	assert.Equal(t, "b", cfg.Host)
+	assert.Equal(t, 2, cfg.Nested.Port)
+	w.RUnlock()
+}
+
+func TestReload(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/host": "a", "/app/password": "p", "/app/port": "1"})
+	var cfg WatchConfig
+	w, err := Watch(m, &cfg, nil, Poll(time.Hour))
+	assert.NoError(t, err)
+	setParameter(m, "/app/host", "b")
+	assert.NoError(t, w.Reload(context.Background()))
+	assert.True(t, waitChange(t, w))
+	w.RLock()
+	assert.Equal(t, "b", cfg.Host)
+	w.RUnlock()
+
+	delete(m.Data, "/app/port")
+	assert.Error(t, w.Reload(context.Background()))
+	assert.Error(t, w.LastError())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, w.Reload(ctx))
+
+	w.Stop()
+	assert.Equal(t, errWatcherStopped, w.Reload(context.Background()))
+}
+
+func TestWatchValidation(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/host": "a", "/app/password": "p", "/app/port": "1"})
+	n := make(chanNotifier)
+	invalid := errors.New("port must be below 100")
+	validate := WithValidator(func(v interface{}) error {
+		if v.(*WatchConfig).Nested.Port >= 100 {
+			return invalid
+		}
+		return nil
+	})
+	var cfg WatchConfig
+	w, err := Watch(m, &cfg, nil, n, validate)
+	assert.NoError(t, err)
+	defer w.Stop()
+
+	setParameter(m, "/app/host", "b")
+	setParameter(m, "/app/port", "100")
+	n <- nil
+	n.settle()
+	assert.Equal(t, invalid, w.LastError())
+	w.RLock()
+	assert.Equal(t, "a", cfg.Host)
+	assert.Equal(t, 1, cfg.Nested.Port)
+	w.RUnlock()
+
+	setParameter(m, "/app/port", "2")
+	n <- nil
+	assert.True(t, waitChange(t, w))
+	assert.NoError(t, w.LastError())
+	w.RLock()
+	assert.Equal(t, "b", cfg.Host)
+	assert.Equal(t, 2, cfg.Nested.Port)
+	w.RUnlock()
+}
+
+func TestWatchStatic(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/host": "a", "/app/port": "1"})
+	n := make(chanNotifier)
+	l := &recordingLogger{}
+	var cfg struct {
+		Host string `ssm:"/app/host"`
+		Port int    `ssm:"/app/port,static"`
+	}
+	w, err := Watch(m, &cfg, nil, n, WithLogger(l))
+	assert.NoError(t, err)
+	defer w.Stop()
+
+	setParameter(m, "/app/host", "b")
+	setParameter(m, "/app/port", "2")
+	n <- nil
+	assert.True(t, waitChange(t, w))
+	w.RLock()
+	assert.Equal(t, "b", cfg.Host)
+	assert.Equal(t, 1, cfg.Port)
+	w.RUnlock()
+	assert.Contains(t, l.msgs, "figgy: ignoring change to static field")
+	assert.Contains(t, l.args, []interface{}{"field", "Port", "key", "/app/port"})
+}
+
+func TestOnChange(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/host": "a", "/app/password": "p", "/app/port": "1"})
+	var cfg WatchConfig
+	w, err := Watch(m, &cfg, nil, Poll(time.Hour))
+	assert.NoError(t, err)
+	defer w.Stop()
+	var hosts []string
+	assert.NoError(t, w.OnChange(&cfg.Host, func(old, new string) {
+		// the watcher's lock is not held
+		w.RLock()
+		defer w.RUnlock()
+		hosts = append(hosts, old, new)
+	}))
+	var ports []int
+	assert.NoError(t, w.OnChange(&cfg.Nested.Port, func(old, new int) {
+		ports = append(ports, old, new)
+	}))
+	assert.Error(t, w.OnChange(&cfg.Local, func(old, new string) {}))
+	assert.Error(t, w.OnChange(cfg.Host, func(old, new string) {}))
+	assert.Error(t, w.OnChange(&cfg.Host, func(old, new int) {}))
+	assert.Error(t, w.OnChange(&cfg.Host, func(s string) {}))
+
+	setParameter(m, "/app/host", "b")
+	assert.NoError(t, w.Reload(context.Background()))
+	assert.Equal(t, []string{"a", "b"}, hosts)
+	assert.Nil(t, ports)
+
+	setParameter(m, "/app/port", "2")
+	assert.NoError(t, w.Reload(context.Background()))
+	assert.Equal(t, []string{"a", "b"}, hosts)
+	assert.Equal(t, []int{1, 2}, ports)
+}
+
+func TestOnRotate(t *testing.T) {
+	m := NewMockSSMClientWith(map[string]string{"/app/host": "a", "/app/password": "p", "/app/port": "1"})
+	l := &recordingLogger{}
+	var cfg WatchConfig
+	w, err := Watch(m, &cfg, nil, Poll(time.Hour), WithLogger(l))
+	assert.NoError(t, err)
+	defer w.Stop()
+	var rotated []interface{}
+	w.OnRotate(func(key string, old, new interface{}) {
+		rotated = append(rotated, key, old, new)
+	})
+
+	setParameter(m, "/app/host", "b")
+	assert.NoError(t, w.Reload(context.Background()))
+	assert.Nil(t, rotated)
+
+	setParameter(m, "/app/password", "rotated")
+	assert.NoError(t, w.Reload(context.Background()))
+	assert.Equal(t, []interface{}{"/app/password", "p", "rotated"}, rotated)
+	for _, args := range l.args {
+		assert.NotContains(t, args, "rotated")
+	}
+}